@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/database"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/services"
+)
+
+const importMapUsage = "usage: server import-map [-name <map>] [-dry-run] <file.tmx | file.tmj | file.json>"
+
+// runImportMap implements `server import-map ...`.
+func runImportMap(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import-map", flag.ContinueOnError)
+	name := flags.String("name", "", "map to import into, instead of the file's name property")
+	dryRun := flags.Bool("dry-run", false, "report the changes without saving them")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return errors.New(importMapUsage)
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+	svc := services.New(cfg, repositories.New(db), repositories.NewUnitOfWork(db))
+	actor := services.Actor{ClientInfo: services.ClientInfo{UserAgent: "server import-map"}}
+
+	result, err := svc.World.ImportTiledMap(actor, data, services.MapImportOptions{Name: *name, DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	action := "Imported"
+	if result.DryRun {
+		action = "Dry run of"
+	}
+	switch {
+	case result.MapCreated:
+		fmt.Printf("%s map %s (new, %dx%d)\n", action, result.Map.Name, result.Map.Width, result.Map.Height)
+	case result.MapChanged:
+		fmt.Printf("%s map %s (layout updated)\n", action, result.Map.Name)
+	default:
+		fmt.Printf("%s map %s (layout unchanged)\n", action, result.Map.Name)
+	}
+
+	for _, change := range result.Created {
+		fmt.Printf("  + %s at (%d,%d)\n", change.ObjectType, change.PosX, change.PosY)
+	}
+	for _, change := range result.Updated {
+		fmt.Printf("  ~ %s at (%d,%d)\n", change.ObjectType, change.PosX, change.PosY)
+	}
+	for _, change := range result.Removed {
+		fmt.Printf("  - %s at (%d,%d)\n", change.ObjectType, change.PosX, change.PosY)
+	}
+	for _, npc := range result.NPCsPlaced {
+		fmt.Printf("  > %s to (%d,%d)\n", npc.NPCName, npc.PosX, npc.PosY)
+	}
+	fmt.Printf("Objects: %d created, %d updated, %d removed, %d unchanged; NPCs: %d placed, %d unchanged\n",
+		len(result.Created), len(result.Updated), len(result.Removed), result.Unchanged,
+		len(result.NPCsPlaced), result.NPCsUnchanged)
+
+	for _, warning := range result.Warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+	return nil
+}
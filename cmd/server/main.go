@@ -2,16 +2,20 @@ package main
 
 import (
 	"log"
+	"os"
+	"time"
 
 	"code-valley-api/internal/config"
 	"code-valley-api/internal/database"
 	"code-valley-api/internal/middleware"
+	"code-valley-api/internal/repositories"
 	"code-valley-api/internal/routes"
 	"code-valley-api/internal/services"
 	"code-valley-api/internal/websocket"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 func main() {
@@ -23,22 +27,53 @@ func main() {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
-	// Run migrations
-	if err := database.AutoMigrate(); err != nil {
-		log.Fatal("Failed to run migrations:", err)
+	// Handle the migrate subcommand
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		return
+	}
+
+	// Refuse to start on an outdated schema
+	if err := checkSchema(cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	// Handle the import-map subcommand
+	if len(os.Args) > 1 && os.Args[1] == "import-map" {
+		if err := runImportMap(cfg, os.Args[2:]); err != nil {
+			log.Fatal("Map import failed: ", err)
+		}
+		return
 	}
 
 	// Initialize WebSocket
-	websocket.InitializeWebSocket()
+	if err := websocket.InitializeWebSocket(cfg); err != nil {
+		log.Fatal("Failed to initialize WebSocket hub: ", err)
+	}
+	defer websocket.ShutdownWebSocket()
+
+	// Wire repositories and services
+	db := database.GetDB()
+	repos := repositories.New(db)
+	svc := services.New(cfg, repos, repositories.NewUnitOfWork(db))
 
 	// Start game clock service
-	gameClockService := services.NewGameClockService()
-	gameClockService.Start()
-	defer gameClockService.Stop()
+	svc.GameClock.Start()
+	defer svc.GameClock.Stop()
+
+	// Register WebSocket message handlers
+	svc.RegisterSocketHandlers(websocket.GlobalHub.Handlers())
 
-	// Start WebSocket handler service
-	wsHandlerService := services.NewWebSocketHandlerService()
-	wsHandlerService.Start()
+	// Reconcile stale presence and start following connections
+	svc.Presence.Start()
+
+	// Start coin ledger reconciliation
+	if cfg.Ledger.ReconcileMinutes > 0 {
+		svc.Ledger.StartReconciliation(time.Duration(cfg.Ledger.ReconcileMinutes) * time.Minute)
+		defer svc.Ledger.Stop()
+	}
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -57,13 +92,14 @@ func main() {
 
 	// Global middleware
 	app.Use(recover.New())
+	app.Use(requestid.New())
 	app.Use(middleware.LoggerMiddleware())
 	app.Use(middleware.CORSMiddleware(cfg))
 	app.Use(middleware.RateLimitMiddleware(cfg))
 	app.Use(middleware.ErrorHandlerMiddleware())
 
 	// Setup routes
-	routes.SetupRoutes(app, cfg)
+	routes.SetupRoutes(app, cfg, svc)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.Port)
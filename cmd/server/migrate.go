@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/database"
+	"code-valley-api/internal/migrations"
+)
+
+const migrateUsage = "usage: server migrate <up | down [steps] | status | to <version>>"
+
+// runMigrate implements `server migrate ...`.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator := migrations.New(database.GetDB())
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up()
+		log.Printf("Applied %d migration(s)", count)
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		count, err := migrator.Down(steps)
+		log.Printf("Rolled back %d migration(s)", count)
+		return err
+
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		count, err := migrator.To(version)
+		log.Printf("Ran %d migration(s)", count)
+		return err
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-32s %s\n", status.Version, status.Name, state)
+		}
+		return nil
+
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// checkSchema makes sure the database is on the latest schema version,
+// applying pending migrations only when DB_MIGRATE_ON_START is enabled.
+func checkSchema(cfg *config.Config) error {
+	migrator := migrations.New(database.GetDB())
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if cfg.Database.MigrateOnStart {
+		_, err := migrator.Up()
+		return err
+	}
+
+	current, _ := migrator.CurrentVersion()
+	return fmt.Errorf("database schema is at version %d but %d migration(s) are pending (latest %d); run `server migrate up` or set DB_MIGRATE_ON_START=true",
+		current, len(pending), migrator.LatestVersion())
+}
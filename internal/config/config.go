@@ -4,30 +4,45 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port      string
-	Database  DatabaseConfig
-	JWT       JWTConfig
-	CORS      CORSConfig
-	RateLimit RateLimitConfig
-	LogLevel  string
+	Port        string
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	CORS        CORSConfig
+	RateLimit   RateLimitConfig
+	Ledger      LedgerConfig
+	Stats       StatsConfig
+	Chat        ChatConfig
+	WebSocket   WebSocketConfig
+	Movement    MovementConfig
+	Pathfinding PathfindingConfig
+	Loot        LootConfig
+	LogLevel    string
 }
 
 type DatabaseConfig struct {
+	Driver   string // mysql, postgres or sqlite
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Name     string
+	Path     string // SQLite file path, or ":memory:"
+	SSLMode  string
+
+	// MigrateOnStart applies pending migrations at boot instead of refusing to start
+	MigrateOnStart bool
 }
 
 type JWTConfig struct {
-	Secret      string
-	ExpireHours int
+	Secret        string
+	AccessMinutes int // lifetime of access tokens
+	RefreshHours  int // lifetime of refresh tokens
 }
 
 type CORSConfig struct {
@@ -39,28 +54,123 @@ type RateLimitConfig struct {
 	Expiration int
 }
 
+type LedgerConfig struct {
+	// ReconcileMinutes is how often cached balances are checked against the
+	// coin ledger; 0 disables the background job
+	ReconcileMinutes int
+}
+
+type StatsConfig struct {
+	// CacheSeconds is how long expensive admin aggregates are reused
+	CacheSeconds int
+	// ActiveDays is the window within which a user counts as active
+	ActiveDays int
+}
+
+type ChatConfig struct {
+	// MaxLength is the longest message allowed, in characters
+	MaxLength int
+	// SlowMode is the minimum number of seconds between a user's messages,
+	// keyed by channel type; 0 turns slow mode off for that channel
+	SlowMode map[string]int
+	// BlockedWords are masked by the default profanity filter
+	BlockedWords []string
+}
+
+type WebSocketConfig struct {
+	// ReplayBuffer is how many recent events each connection keeps so a
+	// client can resume after a drop; 0 disables replay
+	ReplayBuffer int
+	// ResumeGraceSeconds is how long a dropped connection's events are kept
+	ResumeGraceSeconds int
+	// Backplane shares the hub between API nodes: local for a single
+	// node, or redis
+	Backplane string
+	RedisURL  string
+}
+
+type MovementConfig struct {
+	// MaxTilesPerSecond is the fastest a player may walk
+	MaxTilesPerSecond float64
+	// BurstSeconds is how much unused movement a player may save up, which
+	// also caps the length of a single move
+	BurstSeconds float64
+	// SpeedStrikes too-fast moves within StrikeWindowSeconds are recorded
+	// as a speed violation; 0 records none
+	SpeedStrikes        int
+	StrikeWindowSeconds int
+}
+
+type PathfindingConfig struct {
+	// Heuristic is manhattan, chebyshev, octile or euclidean
+	Heuristic string
+	// DiagonalCost is the cost of a diagonal step, a straight one costing 1
+	DiagonalCost float64
+	// MaxNodes caps the tiles a single search may expand
+	MaxNodes int
+	// MaxRouteLength is the longest route a player may request
+	MaxRouteLength int
+}
+
+type LootConfig struct {
+	// Seed seeds loot rolls, so a fixed seed drops the same items in the
+	// same order every run; 0 seeds from the clock
+	Seed int64
+}
+
 func Load() *Config {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	expireHours, _ := strconv.Atoi(getEnv("JWT_EXPIRE_HOURS", "24"))
+	accessMinutes, _ := strconv.Atoi(getEnv("JWT_ACCESS_MINUTES", "15"))
+	refreshHours, _ := strconv.Atoi(getEnv("JWT_REFRESH_HOURS", "720"))
 	rateMax, _ := strconv.Atoi(getEnv("RATE_LIMIT_MAX", "100"))
 	rateExp, _ := strconv.Atoi(getEnv("RATE_LIMIT_EXPIRATION", "1"))
+	reconcileMinutes, _ := strconv.Atoi(getEnv("LEDGER_RECONCILE_MINUTES", "60"))
+	statsCacheSeconds, _ := strconv.Atoi(getEnv("STATS_CACHE_SECONDS", "60"))
+	statsActiveDays, _ := strconv.Atoi(getEnv("STATS_ACTIVE_DAYS", "7"))
+	chatMaxLength, _ := strconv.Atoi(getEnv("CHAT_MAX_LENGTH", "500"))
+	slowGlobal, _ := strconv.Atoi(getEnv("CHAT_SLOW_MODE_GLOBAL", "5"))
+	slowMap, _ := strconv.Atoi(getEnv("CHAT_SLOW_MODE_MAP", "2"))
+	slowGuild, _ := strconv.Atoi(getEnv("CHAT_SLOW_MODE_GUILD", "1"))
+	slowWhisper, _ := strconv.Atoi(getEnv("CHAT_SLOW_MODE_WHISPER", "0"))
+	wsReplayBuffer, _ := strconv.Atoi(getEnv("WS_REPLAY_BUFFER", "256"))
+	wsResumeGrace, _ := strconv.Atoi(getEnv("WS_RESUME_GRACE_SECONDS", "120"))
+	moveMaxRate, _ := strconv.ParseFloat(getEnv("MOVE_MAX_TILES_PER_SECOND", "6"), 64)
+	moveBurst, _ := strconv.ParseFloat(getEnv("MOVE_BURST_SECONDS", "1"), 64)
+	moveStrikes, _ := strconv.Atoi(getEnv("MOVE_SPEED_STRIKES", "10"))
+	moveStrikeWindow, _ := strconv.Atoi(getEnv("MOVE_STRIKE_WINDOW_SECONDS", "10"))
+	pathDiagonalCost, _ := strconv.ParseFloat(getEnv("PATH_DIAGONAL_COST", "1"), 64)
+	pathMaxNodes, _ := strconv.Atoi(getEnv("PATH_MAX_NODES", "10000"))
+	pathMaxRoute, _ := strconv.Atoi(getEnv("PATH_MAX_ROUTE_LENGTH", "100"))
+	lootSeed, _ := strconv.ParseInt(getEnv("LOOT_SEED", "0"), 10, 64)
+
+	dbDriver := getEnv("DB_DRIVER", "mysql")
+	dbPort := "3306"
+	if dbDriver == "postgres" {
+		dbPort = "5432"
+	}
 
 	return &Config{
 		Port: getEnv("PORT", "8000"),
 		Database: DatabaseConfig{
+			Driver:   dbDriver,
 			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "3306"),
+			Port:     getEnv("DB_PORT", dbPort),
 			User:     getEnv("DB_USER", "root"),
 			Password: getEnv("DB_PASSWORD", ""),
 			Name:     getEnv("DB_NAME", "code_valley"),
+			Path:     getEnv("DB_PATH", "code_valley.db"),
+			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			MigrateOnStart: getEnv("DB_MIGRATE_ON_START", "false") == "true",
 		},
 		JWT: JWTConfig{
-			Secret:      getEnv("JWT_SECRET", "your-secret-key"),
-			ExpireHours: expireHours,
+			Secret:        getEnv("JWT_SECRET", "your-secret-key"),
+			AccessMinutes: accessMinutes,
+			RefreshHours:  refreshHours,
 		},
 		CORS: CORSConfig{
 			Origin: getEnv("CORS_ORIGIN", "*"),
@@ -69,6 +179,44 @@ func Load() *Config {
 			Max:        rateMax,
 			Expiration: rateExp,
 		},
+		Ledger: LedgerConfig{
+			ReconcileMinutes: reconcileMinutes,
+		},
+		Stats: StatsConfig{
+			CacheSeconds: statsCacheSeconds,
+			ActiveDays:   statsActiveDays,
+		},
+		Chat: ChatConfig{
+			MaxLength: chatMaxLength,
+			SlowMode: map[string]int{
+				"global":  slowGlobal,
+				"map":     slowMap,
+				"guild":   slowGuild,
+				"whisper": slowWhisper,
+			},
+			BlockedWords: getList("CHAT_BLOCKED_WORDS"),
+		},
+		WebSocket: WebSocketConfig{
+			ReplayBuffer:       wsReplayBuffer,
+			ResumeGraceSeconds: wsResumeGrace,
+			Backplane:          getEnv("WS_BACKPLANE", "local"),
+			RedisURL:           getEnv("REDIS_URL", "redis://localhost:6379/0"),
+		},
+		Movement: MovementConfig{
+			MaxTilesPerSecond:   moveMaxRate,
+			BurstSeconds:        moveBurst,
+			SpeedStrikes:        moveStrikes,
+			StrikeWindowSeconds: moveStrikeWindow,
+		},
+		Pathfinding: PathfindingConfig{
+			Heuristic:      getEnv("PATH_HEURISTIC", "chebyshev"),
+			DiagonalCost:   pathDiagonalCost,
+			MaxNodes:       pathMaxNodes,
+			MaxRouteLength: pathMaxRoute,
+		},
+		Loot: LootConfig{
+			Seed: lootSeed,
+		},
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 	}
 }
@@ -79,3 +227,14 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getList reads a comma-separated variable, skipping empty entries.
+func getList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
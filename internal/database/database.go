@@ -6,9 +6,10 @@ import (
 	"time"
 
 	"code-valley-api/internal/config"
-	"code-valley-api/internal/models"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -16,16 +17,12 @@ import (
 var DB *gorm.DB
 
 func Initialize(cfg *config.Config) error {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
-	)
+	dialector, err := openDialector(cfg.Database)
+	if err != nil {
+		return err
+	}
 
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
@@ -38,8 +35,14 @@ func Initialize(cfg *config.Config) error {
 	}
 
 	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
+	if cfg.Database.Driver == "sqlite" {
+		// SQLite serialises writers, and every connection to ":memory:"
+		// opens a fresh database, so keep a single shared connection.
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		sqlDB.SetMaxIdleConns(10)
+		sqlDB.SetMaxOpenConns(100)
+	}
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
 	// Test connection
@@ -47,66 +50,36 @@ func Initialize(cfg *config.Config) error {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Database connected successfully")
+	log.Printf("Database connected successfully (%s)", cfg.Database.Driver)
 	return nil
 }
 
-func AutoMigrate() error {
-	err := DB.AutoMigrate(
-		&models.User{},
-		&models.OnlineUser{},
-		&models.Inventory{},
-		&models.Quest{},
-		&models.UserQuestProgress{},
-		&models.NPC{},
-		&models.NPCRelationship{},
-		&models.NPCInteraction{},
-		&models.DailyTask{},
-		&models.UserDailyTaskProgress{},
-		&models.Achievement{},
-		&models.UserAchievement{},
-		&models.Badge{},
-		&models.UserBadge{},
-		&models.StoryProgress{},
-		&models.CodeBattle{},
-		&models.Friendship{},
-		&models.ShopItem{},
-		&models.UserPurchase{},
-		&models.Notification{},
-		&models.Skill{},
-		&models.UserSkill{},
-		&models.MiniGame{},
-		&models.MiniGameSession{},
-		&models.CraftingRecipe{},
-		&models.CraftingSession{},
-		&models.DailyReward{},
-		&models.UserDailyReward{},
-		&models.LoginStreak{},
-		&models.Event{},
-		&models.EventParticipant{},
-		&models.Guild{},
-		&models.GuildMember{},
-		&models.GuildInvitation{},
-		&models.MarketplaceListing{},
-		&models.MarketplaceTransaction{},
-		&models.Tutorial{},
-		&models.UserTutorialProgress{},
-		&models.UserStatistics{},
-		&models.DailyStatistics{},
-		&models.Map{},
-		&models.PlayerPosition{},
-		&models.WorldObject{},
-		&models.NPCPosition{},
-		&models.NPCSchedule{},
-		&models.GameClock{},
-		&models.CodeFarm{},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to auto-migrate database: %w", err)
+func openDialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "mysql":
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.Name,
+		)
+		return mysql.Open(dsn), nil
+	case "postgres":
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host,
+			cfg.Port,
+			cfg.User,
+			cfg.Password,
+			cfg.Name,
+			cfg.SSLMode,
+		)
+		return postgres.Open(dsn), nil
+	case "sqlite":
+		return sqlite.Open(cfg.Path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
 	}
-
-	log.Println("Database migration completed successfully")
-	return nil
 }
 
 func GetDB() *gorm.DB {
@@ -4,6 +4,10 @@ import (
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/services"
 	"code-valley-api/internal/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -42,12 +46,66 @@ func (h *AdminHandler) BanUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	err = h.adminService.BanUser(userID, req)
+	ban, err := h.adminService.BanUser(actor(c), userID, req)
 	if err != nil {
+		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Message: "Validation failed",
+				Data:    validationErrors,
+			})
+		}
+		if errors.Is(err, services.ErrAlreadyBanned) {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse(err.Error()))
+		}
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
 	}
 
-	return c.JSON(models.SuccessResponse("User banned successfully", nil))
+	return c.JSON(models.SuccessResponse("User banned successfully", ban))
+}
+
+func (h *AdminHandler) UnbanUser(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	userID, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid user ID"))
+	}
+
+	if err := h.adminService.UnbanUser(actor(c), userID); err != nil {
+		if errors.Is(err, services.ErrNotBanned) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to unban user"))
+	}
+
+	return c.JSON(models.SuccessResponse("User unbanned successfully", nil))
+}
+
+func (h *AdminHandler) GetUserBans(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	userID, err := uuid.Parse(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid user ID"))
+	}
+
+	bans, err := h.adminService.GetUserBans(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(models.SuccessResponse("Ban history retrieved successfully", bans))
+}
+
+func (h *AdminHandler) GetBans(c *fiber.Ctx) error {
+	pagination := utils.GetPaginationParams(c)
+	activeOnly := c.QueryBool("active", false)
+
+	response, err := h.adminService.GetBans(pagination, activeOnly)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch bans"))
+	}
+
+	return c.JSON(models.SuccessResponse("Bans retrieved successfully", response))
 }
 
 func (h *AdminHandler) ChangeUserRole(c *fiber.Ctx) error {
@@ -62,7 +120,7 @@ func (h *AdminHandler) ChangeUserRole(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	user, err := h.adminService.ChangeUserRole(userID, req)
+	user, err := h.adminService.ChangeUserRole(actor(c), userID, req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
 	}
@@ -79,13 +137,129 @@ func (h *AdminHandler) GetSystemStats(c *fiber.Ctx) error {
 	return c.JSON(models.SuccessResponse("System stats retrieved successfully", stats))
 }
 
+// GetStatsSeries returns daily series for the comma-separated metric query
+// parameter (all metrics when omitted) over the last days days.
+func (h *AdminHandler) GetStatsSeries(c *fiber.Ctx) error {
+	var metrics []models.StatsMetric
+	if param := c.Query("metric"); param != "" {
+		for _, metric := range strings.Split(param, ",") {
+			metrics = append(metrics, models.StatsMetric(strings.TrimSpace(metric)))
+		}
+	}
+	days := c.QueryInt("days", 30)
+
+	series, err := h.adminService.GetStatsSeries(metrics, days)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStats) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch stats series"))
+	}
+
+	return c.JSON(models.SuccessResponse("Stats series retrieved successfully", series))
+}
+
 func (h *AdminHandler) GetAuditLogs(c *fiber.Ctx) error {
 	pagination := utils.GetPaginationParams(c)
 
-	logs, err := h.adminService.GetAuditLogs(pagination)
+	filter, err := auditLogFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+	}
+
+	logs, err := h.adminService.GetAuditLogs(filter, pagination)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch audit logs"))
 	}
 
 	return c.JSON(models.SuccessResponse("Audit logs retrieved successfully", logs))
-}
\ No newline at end of file
+}
+
+// auditLogFilter reads the actor_id, action, target_type, target_id, from
+// and to query parameters. Times are RFC 3339.
+func auditLogFilter(c *fiber.Ctx) (services.AuditLogFilter, error) {
+	filter := services.AuditLogFilter{
+		Action:     models.AuditAction(c.Query("action")),
+		TargetType: c.Query("target_type"),
+	}
+
+	for param, dst := range map[string]**uuid.UUID{"actor_id": &filter.ActorID, "target_id": &filter.TargetID} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*dst = &id
+		}
+	}
+
+	for param, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected RFC 3339 time", param)
+			}
+			*dst = &t
+		}
+	}
+
+	return filter, nil
+}
+
+func (h *AdminHandler) GetAntiCheatEvents(c *fiber.Ctx) error {
+	pagination := utils.GetPaginationParams(c)
+
+	filter, err := antiCheatFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+	}
+
+	events, err := h.adminService.GetAntiCheatEvents(filter, pagination)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch anti-cheat events"))
+	}
+
+	return c.JSON(models.SuccessResponse("Anti-cheat events retrieved successfully", events))
+}
+
+// antiCheatFilter reads the user_id, kind, from and to query parameters.
+// Times are RFC 3339.
+func antiCheatFilter(c *fiber.Ctx) (services.AntiCheatFilter, error) {
+	filter := services.AntiCheatFilter{
+		Kind: models.CheatKind(c.Query("kind")),
+	}
+
+	if value := c.Query("user_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return filter, errors.New("invalid user_id")
+		}
+		filter.UserID = &id
+	}
+
+	for param, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected RFC 3339 time", param)
+			}
+			*dst = &t
+		}
+	}
+
+	return filter, nil
+}
+
+// GetAntiCheatSummary ranks users by anti-cheat events over the last days
+// days.
+func (h *AdminHandler) GetAntiCheatSummary(c *fiber.Ctx) error {
+	summary, err := h.adminService.GetAntiCheatSummary(c.QueryInt("days", 7))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDays) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch anti-cheat summary"))
+	}
+
+	return c.JSON(models.SuccessResponse("Anti-cheat summary retrieved successfully", summary))
+}
@@ -4,9 +4,11 @@ import (
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/services"
 	"code-valley-api/internal/utils"
+	"errors"
 	"mime/multipart"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
@@ -25,7 +27,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	response, err := h.authService.Register(req)
+	response, err := h.authService.Register(req, clientInfo(c))
 	if err != nil {
 		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
@@ -46,7 +48,7 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	response, err := h.authService.Login(req)
+	response, err := h.authService.Login(req, clientInfo(c))
 	if err != nil {
 		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
@@ -55,15 +57,13 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 				Data:    validationErrors,
 			})
 		}
+		if errors.Is(err, services.ErrUserBanned) {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse(err.Error()))
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse(err.Error()))
 	}
 
-	// return c.JSON(models.SuccessResponse("Login successful", response))
-	return c.JSON(models.SuccessResponse("Login successful", map[string]interface{}{
-		"token": response.Token,
-		"user":  response.User,
-	}))
-
+	return c.JSON(models.SuccessResponse("Login successful", response))
 }
 
 func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
@@ -101,26 +101,65 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 }
 
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
-	// For simplicity, we'll just return the current user info
-	// In a real application, you might want to implement proper refresh token logic
-	user := c.Locals("user").(*utils.Claims)
+	var req services.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
+	}
 
-	profile, err := h.authService.GetProfile(user.UserID)
+	response, err := h.authService.Refresh(req, clientInfo(c))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse("User not found"))
+		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Message: "Validation failed",
+				Data:    validationErrors,
+			})
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse(err.Error()))
 	}
 
-	return c.JSON(models.SuccessResponse("Token refreshed successfully", map[string]interface{}{
-		"user": profile,
-	}))
+	return c.JSON(models.SuccessResponse("Token refreshed successfully", response))
 }
 
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
-	// In a real implementation, you might want to blacklist the token
-	// For now, we'll just return success as the client should remove the token
+	user := c.Locals("user").(*utils.Claims)
+
+	if err := h.authService.Logout(user); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to log out"))
+	}
+
 	return c.JSON(models.SuccessResponse("Logged out successfully", nil))
 }
 
+func (h *AuthHandler) GetSessions(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	sessions, err := h.authService.GetSessions(user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch sessions"))
+	}
+
+	return c.JSON(models.SuccessResponse("Sessions retrieved successfully", sessions))
+}
+
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid session ID"))
+	}
+
+	if err := h.authService.RevokeSession(user.UserID, sessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse("Session not found"))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to revoke session"))
+	}
+
+	return c.JSON(models.SuccessResponse("Session revoked successfully", nil))
+}
+
 func (h *AuthHandler) UploadAvatar(c *fiber.Ctx) error {
 	user := c.Locals("user").(*utils.Claims)
 
@@ -154,9 +193,7 @@ func (h *AuthHandler) UploadAvatar(c *fiber.Ctx) error {
 }
 
 func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
-	user := c.Locals("user").(*utils.Claims)
-
-	err := h.authService.DeleteAccount(user.UserID)
+	err := h.authService.DeleteAccount(actor(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to delete account"))
 	}
@@ -164,6 +201,26 @@ func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
 	return c.JSON(models.SuccessResponse("Account deleted successfully", nil))
 }
 
+// clientInfo captures the device details recorded on sessions and audit
+// logs.
+func clientInfo(c *fiber.Ctx) services.ClientInfo {
+	requestID, _ := c.Locals("requestid").(string)
+	return services.ClientInfo{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		RequestID: requestID,
+	}
+}
+
+// actor identifies the signed-in user behind an audited request.
+func actor(c *fiber.Ctx) services.Actor {
+	user := c.Locals("user").(*utils.Claims)
+	return services.Actor{
+		UserID:     user.UserID,
+		ClientInfo: clientInfo(c),
+	}
+}
+
 func isValidImageType(file *multipart.FileHeader) bool {
 	validTypes := map[string]bool{
 		"image/jpeg": true,
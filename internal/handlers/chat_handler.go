@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/services"
+	"code-valley-api/internal/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ChatHandler struct {
+	chatService *services.ChatService
+}
+
+func NewChatHandler(chatService *services.ChatService) *ChatHandler {
+	return &ChatHandler{
+		chatService: chatService,
+	}
+}
+
+func (h *ChatHandler) SendMessage(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	var req services.SendChatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
+	}
+
+	message, err := h.chatService.SendMessage(user.UserID, req)
+	if err != nil {
+		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Message: "Validation failed",
+				Data:    validationErrors,
+			})
+		}
+		return chatError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.SuccessResponse("Message sent successfully", message))
+}
+
+func (h *ChatHandler) GetGlobalHistory(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+	pagination := utils.GetPaginationParams(c)
+
+	history, err := h.chatService.GetGlobalHistory(user.UserID, pagination)
+	if err != nil {
+		return chatError(c, err)
+	}
+
+	return c.JSON(models.SuccessResponse("Chat history retrieved successfully", history))
+}
+
+func (h *ChatHandler) GetMapHistory(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+	pagination := utils.GetPaginationParams(c)
+
+	mapID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid map ID"))
+	}
+
+	history, err := h.chatService.GetMapHistory(user.UserID, mapID, pagination)
+	if err != nil {
+		return chatError(c, err)
+	}
+
+	return c.JSON(models.SuccessResponse("Chat history retrieved successfully", history))
+}
+
+func (h *ChatHandler) GetGuildHistory(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+	pagination := utils.GetPaginationParams(c)
+
+	guildID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid guild ID"))
+	}
+
+	history, err := h.chatService.GetGuildHistory(user.UserID, guildID, pagination)
+	if err != nil {
+		return chatError(c, err)
+	}
+
+	return c.JSON(models.SuccessResponse("Chat history retrieved successfully", history))
+}
+
+func (h *ChatHandler) GetWhisperHistory(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+	pagination := utils.GetPaginationParams(c)
+
+	history, err := h.chatService.GetWhisperHistory(user.UserID, c.Params("username"), pagination)
+	if err != nil {
+		return chatError(c, err)
+	}
+
+	return c.JSON(models.SuccessResponse("Chat history retrieved successfully", history))
+}
+
+func (h *ChatHandler) GetMutes(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	mutes, err := h.chatService.GetMutes(user.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch mutes"))
+	}
+
+	return c.JSON(models.SuccessResponse("Mutes retrieved successfully", mutes))
+}
+
+func (h *ChatHandler) MuteUser(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	var req services.MuteUserRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
+		}
+	}
+
+	mute, err := h.chatService.MuteUser(user.UserID, c.Params("username"), req)
+	if err != nil {
+		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Message: "Validation failed",
+				Data:    validationErrors,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(models.SuccessResponse("User muted successfully", mute))
+}
+
+func (h *ChatHandler) UnmuteUser(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	if err := h.chatService.UnmuteUser(user.UserID, c.Params("username")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(models.SuccessResponse("User unmuted successfully", nil))
+}
+
+func (h *ChatHandler) DeleteMessage(c *fiber.Ctx) error {
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid message ID"))
+	}
+
+	if err := h.chatService.DeleteMessage(actor(c), messageID); err != nil {
+		if errors.Is(err, services.ErrChatMessageNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to delete message"))
+	}
+
+	return c.JSON(models.SuccessResponse("Message deleted successfully", nil))
+}
+
+// chatError maps chat service errors to status codes.
+func chatError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrChatSlowMode):
+		return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse(err.Error()))
+	case errors.Is(err, services.ErrChatBlocked), errors.Is(err, services.ErrNotGuildMember):
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse(err.Error()))
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+}
@@ -9,12 +9,14 @@ import (
 )
 
 type FriendHandler struct {
-	friendService *services.FriendService
+	friendService   *services.FriendService
+	presenceService *services.PresenceService
 }
 
-func NewFriendHandler(friendService *services.FriendService) *FriendHandler {
+func NewFriendHandler(friendService *services.FriendService, presenceService *services.PresenceService) *FriendHandler {
 	return &FriendHandler{
-		friendService: friendService,
+		friendService:   friendService,
+		presenceService: presenceService,
 	}
 }
 
@@ -65,6 +67,30 @@ func (h *FriendHandler) RemoveFriend(c *fiber.Ctx) error {
 	return c.JSON(models.SuccessResponse("Friend removed successfully", nil))
 }
 
+func (h *FriendHandler) BlockUser(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+	username := c.Params("username")
+
+	err := h.friendService.BlockUser(user.UserID, username)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(models.SuccessResponse("User blocked successfully", nil))
+}
+
+func (h *FriendHandler) UnblockUser(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+	username := c.Params("username")
+
+	err := h.friendService.UnblockUser(user.UserID, username)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(models.SuccessResponse("User unblocked successfully", nil))
+}
+
 func (h *FriendHandler) GetOnlineFriends(c *fiber.Ctx) error {
 	user := c.Locals("user").(*utils.Claims)
 
@@ -74,4 +100,27 @@ func (h *FriendHandler) GetOnlineFriends(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(models.SuccessResponse("Online friends retrieved successfully", friends))
-}
\ No newline at end of file
+}
+
+func (h *FriendHandler) SetStatus(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	var req services.SetStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
+	}
+
+	presence, err := h.presenceService.SetStatus(user.UserID, req)
+	if err != nil {
+		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Message: "Validation failed",
+				Data:    validationErrors,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to update status"))
+	}
+
+	return c.JSON(models.SuccessResponse("Status updated successfully", presence))
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/services"
+	"code-valley-api/internal/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LootHandler serves the admin loot table endpoints.
+type LootHandler struct {
+	lootService *services.LootService
+}
+
+func NewLootHandler(lootService *services.LootService) *LootHandler {
+	return &LootHandler{
+		lootService: lootService,
+	}
+}
+
+func (h *LootHandler) GetLootTables(c *fiber.Ctx) error {
+	tables, err := h.lootService.GetLootTables()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch loot tables"))
+	}
+
+	return c.JSON(models.SuccessResponse("Loot tables retrieved successfully", tables))
+}
+
+func (h *LootHandler) GetLootTable(c *fiber.Ctx) error {
+	table, err := h.lootService.GetLootTable(c.Params("name"))
+	if err != nil {
+		if errors.Is(err, services.ErrLootTableNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse("Loot table not found"))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch loot table"))
+	}
+
+	return c.JSON(models.SuccessResponse("Loot table retrieved successfully", table))
+}
+
+// SaveLootTable creates the named loot table or replaces its contents.
+func (h *LootHandler) SaveLootTable(c *fiber.Ctx) error {
+	var req services.LootTableRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
+	}
+
+	table, created, err := h.lootService.SaveLootTable(actor(c), c.Params("name"), req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidLootTable) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to save loot table"))
+	}
+
+	if created {
+		return c.Status(fiber.StatusCreated).JSON(models.SuccessResponse("Loot table created successfully", table))
+	}
+	return c.JSON(models.SuccessResponse("Loot table updated successfully", table))
+}
+
+func (h *LootHandler) DeleteLootTable(c *fiber.Ctx) error {
+	if err := h.lootService.DeleteLootTable(actor(c), c.Params("name")); err != nil {
+		if errors.Is(err, services.ErrLootTableNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse("Loot table not found"))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to delete loot table"))
+	}
+
+	return c.JSON(models.SuccessResponse("Loot table deleted successfully", nil))
+}
+
+// SimulateLootTable rolls a loot table many times without dropping
+// anything and reports how often each item dropped.
+func (h *LootHandler) SimulateLootTable(c *fiber.Ctx) error {
+	var req services.LootSimulationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
+	}
+
+	simulation, err := h.lootService.SimulateLootTable(c.Params("name"), req)
+	if err != nil {
+		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Message: "Validation failed",
+				Data:    validationErrors,
+			})
+		}
+		if errors.Is(err, services.ErrLootTableNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse("Loot table not found"))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to simulate loot table"))
+	}
+
+	return c.JSON(models.SuccessResponse("Loot table simulated successfully", simulation))
+}
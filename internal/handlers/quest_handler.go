@@ -4,6 +4,7 @@ import (
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/services"
 	"code-valley-api/internal/utils"
+	"errors"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -76,7 +77,7 @@ func (h *QuestHandler) CompleteQuest(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	progress, err := h.questService.CompleteQuest(user.UserID, questID, req)
+	progress, err := h.questService.CompleteQuest(user.UserID, questID, req, clientInfo(c))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
 	}
@@ -102,7 +103,7 @@ func (h *QuestHandler) CreateQuest(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	quest, err := h.questService.CreateQuest(req)
+	quest, err := h.questService.CreateQuest(actor(c), req)
 	if err != nil {
 		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
@@ -129,7 +130,7 @@ func (h *QuestHandler) UpdateQuest(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	quest, err := h.questService.UpdateQuest(id, req)
+	quest, err := h.questService.UpdateQuest(actor(c), id, req)
 	if err != nil {
 		if validationErrors := utils.FormatValidationErrors(err); len(validationErrors) > 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
@@ -151,7 +152,10 @@ func (h *QuestHandler) DeleteQuest(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid quest ID"))
 	}
 
-	if err := h.questService.DeleteQuest(id); err != nil {
+	if err := h.questService.DeleteQuest(actor(c), id); err != nil {
+		if errors.Is(err, services.ErrQuestNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse("Quest not found"))
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to delete quest"))
 	}
 
@@ -44,7 +44,7 @@ func (h *ShopHandler) BuyItem(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	purchase, err := h.shopService.BuyItem(user.UserID, itemID, req)
+	purchase, err := h.shopService.BuyItem(user.UserID, itemID, req, clientInfo(c))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
 	}
@@ -66,7 +66,7 @@ func (h *ShopHandler) SellItem(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	result, err := h.shopService.SellItem(user.UserID, itemID, req)
+	result, err := h.shopService.SellItem(user.UserID, itemID, req, clientInfo(c))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
 	}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/services"
+	"code-valley-api/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WalletHandler struct {
+	ledgerService *services.LedgerService
+}
+
+func NewWalletHandler(ledgerService *services.LedgerService) *WalletHandler {
+	return &WalletHandler{
+		ledgerService: ledgerService,
+	}
+}
+
+func (h *WalletHandler) GetBalance(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+
+	balance, err := h.ledgerService.GetBalance(user.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch balance"))
+	}
+
+	return c.JSON(models.SuccessResponse("Balance retrieved successfully", fiber.Map{
+		"balance": balance,
+	}))
+}
+
+func (h *WalletHandler) GetHistory(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.Claims)
+	pagination := utils.GetPaginationParams(c)
+
+	response, err := h.ledgerService.GetHistory(user.UserID, pagination)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to fetch wallet history"))
+	}
+
+	return c.JSON(models.SuccessResponse("Wallet history retrieved successfully", response))
+}
+
+// Reconcile runs a reconciliation immediately and returns the report.
+func (h *WalletHandler) Reconcile(c *fiber.Ctx) error {
+	report, err := h.ledgerService.Reconcile()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to reconcile coin ledger"))
+	}
+
+	return c.JSON(models.SuccessResponse("Reconciliation completed successfully", report))
+}
+
+// GetReconciliation returns the report from the last background run.
+func (h *WalletHandler) GetReconciliation(c *fiber.Ctx) error {
+	report := h.ledgerService.LastReport()
+	if report == nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse("No reconciliation has run yet"))
+	}
+
+	return c.JSON(models.SuccessResponse("Reconciliation retrieved successfully", report))
+}
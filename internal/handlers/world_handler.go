@@ -4,6 +4,8 @@ import (
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/services"
 	"code-valley-api/internal/utils"
+	"errors"
+	"io"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -43,14 +45,12 @@ func (h *WorldHandler) GetPlayerPosition(c *fiber.Ctx) error {
 }
 
 func (h *WorldHandler) TeleportPlayer(c *fiber.Ctx) error {
-	user := c.Locals("user").(*utils.Claims)
-
 	var req services.TeleportRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
 	}
 
-	position, err := h.worldService.TeleportPlayer(user.UserID, req)
+	position, err := h.worldService.TeleportPlayer(actor(c), req)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
 	}
@@ -120,10 +120,70 @@ func (h *WorldHandler) HarvestCode(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid farm ID"))
 	}
 
-	result, err := h.worldService.HarvestCode(user.UserID, farmID)
+	result, err := h.worldService.HarvestCode(user.UserID, farmID, clientInfo(c))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
 	}
 
 	return c.JSON(models.SuccessResponse("Code harvested successfully", result))
-}
\ No newline at end of file
+}
+// UpdateMapLayout replaces a map's layout (admin only).
+func (h *WorldHandler) UpdateMapLayout(c *fiber.Ctx) error {
+	mapID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid map ID"))
+	}
+
+	var layout models.MapLayout
+	if err := c.BodyParser(&layout); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid request body"))
+	}
+
+	mapData, err := h.worldService.UpdateMapLayout(actor(c), mapID, layout)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(models.SuccessResponse("Map layout updated successfully", mapData))
+}
+
+// maxMapFileSize bounds uploaded Tiled maps.
+const maxMapFileSize = 10 * 1024 * 1024
+
+// ImportMap creates or updates a map from a Tiled JSON or TMX export (admin
+// only), sent as the file field of a multipart form or as the raw body.
+// The name query parameter overrides the map's name property and dry_run
+// only reports the changes.
+func (h *WorldHandler) ImportMap(c *fiber.Ctx) error {
+	data := c.Body()
+	if file, err := c.FormFile("file"); err == nil {
+		if file.Size > maxMapFileSize {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("File too large. Maximum size is 10MB"))
+		}
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid file"))
+		}
+		defer f.Close()
+		if data, err = io.ReadAll(f); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("Invalid file"))
+		}
+	}
+	if len(data) > maxMapFileSize {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse("File too large. Maximum size is 10MB"))
+	}
+
+	opts := services.MapImportOptions{
+		Name:   c.Query("name"),
+		DryRun: c.QueryBool("dry_run"),
+	}
+	result, err := h.worldService.ImportTiledMap(actor(c), data, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMapFile) || errors.Is(err, models.ErrInvalidLayout) {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse("Failed to import map"))
+	}
+
+	return c.JSON(models.SuccessResponse("Map imported successfully", result))
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func AuthMiddleware(cfg *config.Config) fiber.Handler {
+// AuthMiddleware accepts requests carrying a valid access token whose session
+// is still accepted by sessions.
+func AuthMiddleware(cfg *config.Config, sessions utils.SessionValidator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -27,6 +29,10 @@ func AuthMiddleware(cfg *config.Config) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse("Invalid token"))
 		}
 
+		if err := sessions.ValidateSession(claims); err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse("Session is no longer valid"))
+		}
+
 		c.Locals("user", claims)
 		return c.Next()
 	}
@@ -14,11 +14,12 @@ func LoggerMiddleware() fiber.Handler {
 		err := c.Next()
 		
 		duration := time.Since(start)
-		log.Printf("%s %s - %d - %v", 
+		log.Printf("%s %s - %d - %v - %v", 
 			c.Method(), 
 			c.Path(), 
 			c.Response().StatusCode(), 
 			duration,
+			c.Locals("requestid"),
 		)
 		
 		return err
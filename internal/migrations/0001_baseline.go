@@ -0,0 +1,951 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// baselineTables is the schema that AutoMigrate used to create on boot. The
+// tables are snapshots of the models as released, so the baseline keeps
+// creating the same schema however the models change later on.
+var baselineTables = []interface{}{
+	&userV1{},
+	&onlineUserV1{},
+	&inventoryV1{},
+	&questV1{},
+	&userQuestProgressV1{},
+	&npcV1{},
+	&npcRelationshipV1{},
+	&npcInteractionV1{},
+	&dailyTaskV1{},
+	&userDailyTaskProgressV1{},
+	&achievementV1{},
+	&userAchievementV1{},
+	&badgeV1{},
+	&userBadgeV1{},
+	&storyProgressV1{},
+	&codeBattleV1{},
+	&friendshipV1{},
+	&shopItemV1{},
+	&userPurchaseV1{},
+	&notificationV1{},
+	&skillV1{},
+	&userSkillV1{},
+	&miniGameV1{},
+	&miniGameSessionV1{},
+	&craftingRecipeV1{},
+	&craftingSessionV1{},
+	&dailyRewardV1{},
+	&userDailyRewardV1{},
+	&loginStreakV1{},
+	&eventV1{},
+	&eventParticipantV1{},
+	&guildV1{},
+	&guildMemberV1{},
+	&guildInvitationV1{},
+	&marketplaceListingV1{},
+	&marketplaceTransactionV1{},
+	&tutorialV1{},
+	&userTutorialProgressV1{},
+	&userStatisticsV1{},
+	&dailyStatisticsV1{},
+	&mapV1{},
+	&playerPositionV1{},
+	&worldObjectV1{},
+	&npcPositionV1{},
+	&npcScheduleV1{},
+	&gameClockV1{},
+	&codeFarmV1{},
+}
+
+var baseline = Migration{
+	Version: 1,
+	Name:    "baseline",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, baselineTables...)
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, baselineTables...)
+	},
+}
+
+type userV1 struct {
+	ID           uuid.UUID `gorm:"type:char(36);primary_key"`
+	Email        string    `gorm:"uniqueIndex;not null"`
+	Username     string    `gorm:"uniqueIndex;not null"`
+	PasswordHash string    `gorm:"not null"`
+	Bio          string    `gorm:"type:text"`
+	AvatarURL    string
+	EXP          int    `gorm:"default:0"`
+	Level        int    `gorm:"default:1"`
+	Coins        int    `gorm:"default:100"`
+	Role         string `gorm:"type:varchar(32);default:'player'"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	// Relationships
+	Inventory         []inventoryV1             `gorm:"foreignKey:UserID"`
+	QuestProgress     []userQuestProgressV1     `gorm:"foreignKey:UserID"`
+	DailyTaskProgress []userDailyTaskProgressV1 `gorm:"foreignKey:UserID"`
+	Achievements      []userAchievementV1       `gorm:"foreignKey:UserID"`
+	StoryProgress     []storyProgressV1         `gorm:"foreignKey:UserID"`
+	CodeBattles       []codeBattleV1            `gorm:"foreignKey:UserID"`
+}
+
+func (userV1) TableName() string {
+	return "users"
+}
+
+type onlineUserV1 struct {
+	ID       uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID   uuid.UUID `gorm:"type:char(36);not null;uniqueIndex"`
+	LastSeen time.Time
+	IsOnline bool   `gorm:"default:true"`
+	SocketID string `gorm:"index"`
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (onlineUserV1) TableName() string {
+	return "online_users"
+}
+
+type inventoryV1 struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	ItemName  string    `gorm:"not null"`
+	Quantity  int       `gorm:"default:1"`
+	ItemType  string    `gorm:"type:varchar(32);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (inventoryV1) TableName() string {
+	return "inventories"
+}
+
+type questV1 struct {
+	ID            uuid.UUID `gorm:"type:char(36);primary_key"`
+	Title         string    `gorm:"not null"`
+	Description   string    `gorm:"type:text"`
+	RewardCoins   int       `gorm:"default:0"`
+	RewardEXP     int       `gorm:"default:0"`
+	RequiredItems string    `gorm:"type:json"`
+	IsRepeatable  bool      `gorm:"default:false"`
+	IsActive      bool      `gorm:"default:true"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	// Relationships
+	UserProgress []userQuestProgressV1 `gorm:"foreignKey:QuestID"`
+}
+
+func (questV1) TableName() string {
+	return "quests"
+}
+
+type userQuestProgressV1 struct {
+	ID           uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID       uuid.UUID `gorm:"type:char(36);not null;index"`
+	QuestID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	Status       string    `gorm:"type:varchar(32);default:'not_started'"`
+	ProgressData string    `gorm:"type:json"`
+	StartedAt    *time.Time
+	CompletedAt  *time.Time
+
+	// Relationships
+	User  userV1  `gorm:"foreignKey:UserID"`
+	Quest questV1 `gorm:"foreignKey:QuestID"`
+}
+
+func (userQuestProgressV1) TableName() string {
+	return "user_quest_progresses"
+}
+
+type npcV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name        string    `gorm:"not null"`
+	Role        string    `gorm:"type:varchar(32);not null"`
+	Dialogue    string    `gorm:"type:text"`
+	Location    string    `gorm:"not null"`
+	AvatarURL   string
+	QuestsGiven string `gorm:"type:json"`
+	IsActive    bool   `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (npcV1) TableName() string {
+	return "npcs"
+}
+
+type npcRelationshipV1 struct {
+	ID                uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID            uuid.UUID `gorm:"type:char(36);not null;index"`
+	NPCID             uuid.UUID `gorm:"type:char(36);not null;index"`
+	FriendshipLevel   int       `gorm:"default:0"`
+	LastInteraction   time.Time
+	TotalInteractions int `gorm:"default:0"`
+	GiftsGiven        int `gorm:"default:0"`
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+	NPC  npcV1  `gorm:"foreignKey:NPCID"`
+}
+
+func (npcRelationshipV1) TableName() string {
+	return "npc_relationships"
+}
+
+type npcInteractionV1 struct {
+	ID              uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID          uuid.UUID `gorm:"type:char(36);not null;index"`
+	NPCID           uuid.UUID `gorm:"type:char(36);not null;index"`
+	InteractionType string    `gorm:"not null"`
+	Data            string    `gorm:"type:json"`
+	CreatedAt       time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+	NPC  npcV1  `gorm:"foreignKey:NPCID"`
+}
+
+func (npcInteractionV1) TableName() string {
+	return "npc_interactions"
+}
+
+type dailyTaskV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	TaskName    string    `gorm:"not null"`
+	TaskType    string    `gorm:"not null"`
+	Description string    `gorm:"type:text"`
+	RewardEXP   int       `gorm:"default:0"`
+	RewardCoins int       `gorm:"default:0"`
+	Date        time.Time `gorm:"type:date;not null"`
+	IsActive    bool      `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	UserProgress []userDailyTaskProgressV1 `gorm:"foreignKey:DailyTaskID"`
+}
+
+func (dailyTaskV1) TableName() string {
+	return "daily_tasks"
+}
+
+type userDailyTaskProgressV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	DailyTaskID uuid.UUID `gorm:"type:char(36);not null;index"`
+	CompletedAt *time.Time
+	Date        time.Time `gorm:"type:date;not null"`
+
+	// Relationships
+	User      userV1      `gorm:"foreignKey:UserID"`
+	DailyTask dailyTaskV1 `gorm:"foreignKey:DailyTaskID"`
+}
+
+func (userDailyTaskProgressV1) TableName() string {
+	return "user_daily_task_progresses"
+}
+
+type achievementV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Title       string    `gorm:"not null"`
+	Description string    `gorm:"type:text"`
+	RewardCoins int       `gorm:"default:0"`
+	RewardEXP   int       `gorm:"default:0"`
+	Conditions  string    `gorm:"type:json"`
+	IconURL     string
+	IsActive    bool `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	UserAchievements []userAchievementV1 `gorm:"foreignKey:AchievementID"`
+}
+
+func (achievementV1) TableName() string {
+	return "achievements"
+}
+
+type userAchievementV1 struct {
+	ID            uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID        uuid.UUID `gorm:"type:char(36);not null;index"`
+	AchievementID uuid.UUID `gorm:"type:char(36);not null;index"`
+	UnlockedAt    time.Time
+
+	// Relationships
+	User        userV1        `gorm:"foreignKey:UserID"`
+	Achievement achievementV1 `gorm:"foreignKey:AchievementID"`
+}
+
+func (userAchievementV1) TableName() string {
+	return "user_achievements"
+}
+
+type badgeV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name        string    `gorm:"not null;index"`
+	Description string    `gorm:"type:text"`
+	Type        string    `gorm:"type:varchar(32);not null"`
+	Rarity      string    `gorm:"type:varchar(32);default:'common'"`
+	IconURL     string
+	Conditions  string `gorm:"type:json"`
+	IsActive    bool   `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	UserBadges []userBadgeV1 `gorm:"foreignKey:BadgeID"`
+}
+
+func (badgeV1) TableName() string {
+	return "badges"
+}
+
+type userBadgeV1 struct {
+	ID       uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID   uuid.UUID `gorm:"type:char(36);not null;index"`
+	BadgeID  uuid.UUID `gorm:"type:char(36);not null;index"`
+	EarnedAt time.Time
+
+	// Relationships
+	User  userV1  `gorm:"foreignKey:UserID"`
+	Badge badgeV1 `gorm:"foreignKey:BadgeID"`
+}
+
+func (userBadgeV1) TableName() string {
+	return "user_badges"
+}
+
+type storyProgressV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	Chapter     int       `gorm:"not null"`
+	Milestone   string    `gorm:"not null"`
+	IsCompleted bool      `gorm:"default:false"`
+	UnlockedAt  *time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (storyProgressV1) TableName() string {
+	return "story_progresses"
+}
+
+type codeBattleV1 struct {
+	ID            uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID        uuid.UUID `gorm:"type:char(36);not null;index"`
+	ChallengeName string    `gorm:"not null"`
+	Difficulty    string    `gorm:"type:varchar(32);not null"`
+	Status        string    `gorm:"type:varchar(32);default:'in_progress'"`
+	Score         int       `gorm:"default:0"`
+	StartedAt     time.Time
+	CompletedAt   *time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (codeBattleV1) TableName() string {
+	return "code_battles"
+}
+
+type friendshipV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	RequesterID uuid.UUID `gorm:"type:char(36);not null;index"`
+	AddresseeID uuid.UUID `gorm:"type:char(36);not null;index"`
+	Status      string    `gorm:"type:varchar(32);default:'pending'"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	Requester userV1 `gorm:"foreignKey:RequesterID"`
+	Addressee userV1 `gorm:"foreignKey:AddresseeID"`
+}
+
+func (friendshipV1) TableName() string {
+	return "friendships"
+}
+
+type shopItemV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name        string    `gorm:"not null;index"`
+	Description string    `gorm:"type:text"`
+	Price       int       `gorm:"not null"`
+	ItemType    string    `gorm:"type:varchar(32);not null"`
+	IconURL     string
+	IsAvailable bool `gorm:"default:true"`
+	Stock       int  `gorm:"default:-1"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (shopItemV1) TableName() string {
+	return "shop_items"
+}
+
+type userPurchaseV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	ShopItemID  uuid.UUID `gorm:"type:char(36);not null;index"`
+	Quantity    int       `gorm:"default:1"`
+	TotalPrice  int       `gorm:"not null"`
+	PurchasedAt time.Time
+
+	// Relationships
+	User     userV1     `gorm:"foreignKey:UserID"`
+	ShopItem shopItemV1 `gorm:"foreignKey:ShopItemID"`
+}
+
+func (userPurchaseV1) TableName() string {
+	return "user_purchases"
+}
+
+type notificationV1 struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	Type      string    `gorm:"type:varchar(32);not null"`
+	Title     string    `gorm:"not null"`
+	Message   string    `gorm:"type:text"`
+	IsRead    bool      `gorm:"default:false"`
+	Data      string    `gorm:"type:json"`
+	CreatedAt time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (notificationV1) TableName() string {
+	return "notifications"
+}
+
+type skillV1 struct {
+	ID            uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name          string    `gorm:"not null;index"`
+	Description   string    `gorm:"type:text"`
+	Category      string    `gorm:"type:varchar(32);not null"`
+	MaxLevel      int       `gorm:"default:10"`
+	BaseCost      int       `gorm:"default:100"`
+	Effects       string    `gorm:"type:json"`
+	IconURL       string
+	Prerequisites string `gorm:"type:json"`
+	IsActive      bool   `gorm:"default:true"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	// Relationships
+	UserSkills []userSkillV1 `gorm:"foreignKey:SkillID"`
+}
+
+func (skillV1) TableName() string {
+	return "skills"
+}
+
+type userSkillV1 struct {
+	ID         uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	SkillID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	Level      int       `gorm:"default:1"`
+	UnlockedAt time.Time
+
+	// Relationships
+	User  userV1  `gorm:"foreignKey:UserID"`
+	Skill skillV1 `gorm:"foreignKey:SkillID"`
+}
+
+func (userSkillV1) TableName() string {
+	return "user_skills"
+}
+
+type miniGameV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name        string    `gorm:"not null;index"`
+	Description string    `gorm:"type:text"`
+	Type        string    `gorm:"type:varchar(32);not null"`
+	Difficulty  string    `gorm:"type:varchar(32);not null"`
+	Config      string    `gorm:"type:json"`
+	RewardCoins int       `gorm:"default:0"`
+	RewardEXP   int       `gorm:"default:0"`
+	TimeLimit   int       `gorm:"default:300"`
+	IsActive    bool      `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	GameSessions []miniGameSessionV1 `gorm:"foreignKey:MiniGameID"`
+}
+
+func (miniGameV1) TableName() string {
+	return "mini_games"
+}
+
+type miniGameSessionV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	MiniGameID  uuid.UUID `gorm:"type:char(36);not null;index"`
+	Status      string    `gorm:"type:varchar(32);default:'active'"`
+	Score       int       `gorm:"default:0"`
+	StartedAt   time.Time
+	CompletedAt *time.Time
+	SessionData string `gorm:"type:json"`
+
+	// Relationships
+	User     userV1     `gorm:"foreignKey:UserID"`
+	MiniGame miniGameV1 `gorm:"foreignKey:MiniGameID"`
+}
+
+func (miniGameSessionV1) TableName() string {
+	return "mini_game_sessions"
+}
+
+type craftingRecipeV1 struct {
+	ID             uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name           string    `gorm:"not null;index"`
+	Description    string    `gorm:"type:text"`
+	RequiredItems  string    `gorm:"type:json"`
+	ResultItem     string    `gorm:"not null"`
+	ResultQuantity int       `gorm:"default:1"`
+	CraftingTime   int       `gorm:"default:60"`
+	RequiredLevel  int       `gorm:"default:1"`
+	IsActive       bool      `gorm:"default:true"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// Relationships
+	CraftingSessions []craftingSessionV1 `gorm:"foreignKey:RecipeID"`
+}
+
+func (craftingRecipeV1) TableName() string {
+	return "crafting_recipes"
+}
+
+type craftingSessionV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	RecipeID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	Status      string    `gorm:"type:varchar(32);default:'in_progress'"`
+	StartedAt   time.Time
+	CompletedAt *time.Time
+
+	// Relationships
+	User   userV1           `gorm:"foreignKey:UserID"`
+	Recipe craftingRecipeV1 `gorm:"foreignKey:RecipeID"`
+}
+
+func (craftingSessionV1) TableName() string {
+	return "crafting_sessions"
+}
+
+type dailyRewardV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Day         int       `gorm:"not null;index"`
+	RewardCoins int       `gorm:"default:0"`
+	RewardEXP   int       `gorm:"default:0"`
+	BonusItem   string
+	IsActive    bool `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (dailyRewardV1) TableName() string {
+	return "daily_rewards"
+}
+
+type userDailyRewardV1 struct {
+	ID            uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID        uuid.UUID `gorm:"type:char(36);not null;index"`
+	DailyRewardID uuid.UUID `gorm:"type:char(36);not null;index"`
+	ClaimedAt     time.Time
+	Date          time.Time `gorm:"type:date;not null;index"`
+
+	// Relationships
+	User        userV1        `gorm:"foreignKey:UserID"`
+	DailyReward dailyRewardV1 `gorm:"foreignKey:DailyRewardID"`
+}
+
+func (userDailyRewardV1) TableName() string {
+	return "user_daily_rewards"
+}
+
+type loginStreakV1 struct {
+	ID            uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID        uuid.UUID `gorm:"type:char(36);not null;uniqueIndex"`
+	CurrentStreak int       `gorm:"default:0"`
+	LongestStreak int       `gorm:"default:0"`
+	LastLoginDate time.Time `gorm:"type:date"`
+	UpdatedAt     time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (loginStreakV1) TableName() string {
+	return "login_streaks"
+}
+
+type eventV1 struct {
+	ID              uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name            string    `gorm:"not null;index"`
+	Description     string    `gorm:"type:text"`
+	Type            string    `gorm:"type:varchar(32);not null"`
+	StartDate       time.Time `gorm:"not null;index"`
+	EndDate         time.Time `gorm:"not null;index"`
+	Requirements    string    `gorm:"type:json"`
+	Rewards         string    `gorm:"type:json"`
+	MaxParticipants int       `gorm:"default:-1"`
+	IsActive        bool      `gorm:"default:true"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	// Relationships
+	Participants []eventParticipantV1 `gorm:"foreignKey:EventID"`
+}
+
+func (eventV1) TableName() string {
+	return "events"
+}
+
+type eventParticipantV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	EventID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	Status      string    `gorm:"type:varchar(32);default:'joined'"`
+	Score       int       `gorm:"default:0"`
+	JoinedAt    time.Time
+	CompletedAt *time.Time
+
+	// Relationships
+	User  userV1  `gorm:"foreignKey:UserID"`
+	Event eventV1 `gorm:"foreignKey:EventID"`
+}
+
+func (eventParticipantV1) TableName() string {
+	return "event_participants"
+}
+
+type guildV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name        string    `gorm:"not null;uniqueIndex"`
+	Description string    `gorm:"type:text"`
+	OwnerID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	MaxMembers  int       `gorm:"default:20"`
+	Level       int       `gorm:"default:1"`
+	EXP         int       `gorm:"default:0"`
+	IconURL     string
+	IsPublic    bool `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	Owner   userV1          `gorm:"foreignKey:OwnerID"`
+	Members []guildMemberV1 `gorm:"foreignKey:GuildID"`
+}
+
+func (guildV1) TableName() string {
+	return "guilds"
+}
+
+type guildMemberV1 struct {
+	ID       uuid.UUID `gorm:"type:char(36);primary_key"`
+	GuildID  uuid.UUID `gorm:"type:char(36);not null;index"`
+	UserID   uuid.UUID `gorm:"type:char(36);not null;index"`
+	Role     string    `gorm:"type:varchar(32);default:'member'"`
+	JoinedAt time.Time
+
+	// Relationships
+	Guild guildV1 `gorm:"foreignKey:GuildID"`
+	User  userV1  `gorm:"foreignKey:UserID"`
+}
+
+func (guildMemberV1) TableName() string {
+	return "guild_members"
+}
+
+type guildInvitationV1 struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key"`
+	GuildID   uuid.UUID `gorm:"type:char(36);not null;index"`
+	InviterID uuid.UUID `gorm:"type:char(36);not null;index"`
+	InviteeID uuid.UUID `gorm:"type:char(36);not null;index"`
+	Status    string    `gorm:"type:varchar(32);default:'pending'"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// Relationships
+	Guild   guildV1 `gorm:"foreignKey:GuildID"`
+	Inviter userV1  `gorm:"foreignKey:InviterID"`
+	Invitee userV1  `gorm:"foreignKey:InviteeID"`
+}
+
+func (guildInvitationV1) TableName() string {
+	return "guild_invitations"
+}
+
+type marketplaceListingV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	SellerID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	ItemName    string    `gorm:"not null;index"`
+	Description string    `gorm:"type:text"`
+	Price       int       `gorm:"not null"`
+	Quantity    int       `gorm:"default:1"`
+	ItemType    string    `gorm:"type:varchar(32);not null"`
+	Status      string    `gorm:"type:varchar(32);default:'active'"`
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	Seller userV1 `gorm:"foreignKey:SellerID"`
+}
+
+func (marketplaceListingV1) TableName() string {
+	return "marketplace_listings"
+}
+
+type marketplaceTransactionV1 struct {
+	ID         uuid.UUID `gorm:"type:char(36);primary_key"`
+	ListingID  uuid.UUID `gorm:"type:char(36);not null;index"`
+	BuyerID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	SellerID   uuid.UUID `gorm:"type:char(36);not null;index"`
+	Quantity   int       `gorm:"not null"`
+	TotalPrice int       `gorm:"not null"`
+	CreatedAt  time.Time
+
+	// Relationships
+	Listing marketplaceListingV1 `gorm:"foreignKey:ListingID"`
+	Buyer   userV1               `gorm:"foreignKey:BuyerID"`
+	Seller  userV1               `gorm:"foreignKey:SellerID"`
+}
+
+func (marketplaceTransactionV1) TableName() string {
+	return "marketplace_transactions"
+}
+
+type tutorialV1 struct {
+	ID            uuid.UUID `gorm:"type:char(36);primary_key"`
+	Title         string    `gorm:"not null;index"`
+	Description   string    `gorm:"type:text"`
+	Category      string    `gorm:"type:varchar(32);not null"`
+	Content       string    `gorm:"type:json"`
+	Prerequisites string    `gorm:"type:json"`
+	RequiredLevel int       `gorm:"default:1"`
+	RewardEXP     int       `gorm:"default:0"`
+	EstimatedTime int       `gorm:"default:30"`
+	IsActive      bool      `gorm:"default:true"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	// Relationships
+	UserProgress []userTutorialProgressV1 `gorm:"foreignKey:TutorialID"`
+}
+
+func (tutorialV1) TableName() string {
+	return "tutorials"
+}
+
+type userTutorialProgressV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	TutorialID  uuid.UUID `gorm:"type:char(36);not null;index"`
+	Status      string    `gorm:"type:varchar(32);default:'not_started'"`
+	Progress    int       `gorm:"default:0"`
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+
+	// Relationships
+	User     userV1     `gorm:"foreignKey:UserID"`
+	Tutorial tutorialV1 `gorm:"foreignKey:TutorialID"`
+}
+
+func (userTutorialProgressV1) TableName() string {
+	return "user_tutorial_progresses"
+}
+
+type userStatisticsV1 struct {
+	ID                 uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID             uuid.UUID `gorm:"type:char(36);not null;uniqueIndex"`
+	TotalPlayTime      int       `gorm:"default:0"`
+	QuestsCompleted    int       `gorm:"default:0"`
+	TasksCompleted     int       `gorm:"default:0"`
+	CoinsEarned        int       `gorm:"default:0"`
+	CoinsSpent         int       `gorm:"default:0"`
+	FriendsCount       int       `gorm:"default:0"`
+	GamesPlayed        int       `gorm:"default:0"`
+	GamesWon           int       `gorm:"default:0"`
+	ItemsCrafted       int       `gorm:"default:0"`
+	TutorialsCompleted int       `gorm:"default:0"`
+	LoginStreak        int       `gorm:"default:0"`
+	LastActive         time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (userStatisticsV1) TableName() string {
+	return "user_statistics"
+}
+
+type dailyStatisticsV1 struct {
+	ID              uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID          uuid.UUID `gorm:"type:char(36);not null;index"`
+	Date            time.Time `gorm:"type:date;not null;index"`
+	PlayTime        int       `gorm:"default:0"`
+	QuestsCompleted int       `gorm:"default:0"`
+	TasksCompleted  int       `gorm:"default:0"`
+	CoinsEarned     int       `gorm:"default:0"`
+	EXPGained       int       `gorm:"default:0"`
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (dailyStatisticsV1) TableName() string {
+	return "daily_statistics"
+}
+
+type mapV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name        string    `gorm:"not null;uniqueIndex"`
+	Type        string    `gorm:"type:varchar(32);not null"`
+	Width       int       `gorm:"not null"`
+	Height      int       `gorm:"not null"`
+	Layout      string    `gorm:"type:json"`
+	Description string    `gorm:"type:text"`
+	IsActive    bool      `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	PlayerPositions []playerPositionV1 `gorm:"foreignKey:MapID"`
+	WorldObjects    []worldObjectV1    `gorm:"foreignKey:MapID"`
+	NPCPositions    []npcPositionV1    `gorm:"foreignKey:MapID"`
+}
+
+func (mapV1) TableName() string {
+	return "maps"
+}
+
+type playerPositionV1 struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;uniqueIndex"`
+	MapID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	PosX      int       `gorm:"not null"`
+	PosY      int       `gorm:"not null"`
+	Direction string    `gorm:"default:'down'"`
+	LastMoved time.Time
+	UpdatedAt time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+	Map  mapV1  `gorm:"foreignKey:MapID"`
+}
+
+func (playerPositionV1) TableName() string {
+	return "player_positions"
+}
+
+type worldObjectV1 struct {
+	ID         uuid.UUID `gorm:"type:char(36);primary_key"`
+	MapID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	ObjectType string    `gorm:"type:varchar(32);not null"`
+	PosX       int       `gorm:"not null"`
+	PosY       int       `gorm:"not null"`
+	State      string    `gorm:"type:json"`
+	IsActive   bool      `gorm:"default:true"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	// Relationships
+	Map mapV1 `gorm:"foreignKey:MapID"`
+}
+
+func (worldObjectV1) TableName() string {
+	return "world_objects"
+}
+
+type npcPositionV1 struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key"`
+	NPCID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	MapID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	PosX      int       `gorm:"not null"`
+	PosY      int       `gorm:"not null"`
+	Direction string    `gorm:"default:'down'"`
+	UpdatedAt time.Time
+
+	// Relationships
+	NPC npcV1 `gorm:"foreignKey:NPCID"`
+	Map mapV1 `gorm:"foreignKey:MapID"`
+}
+
+func (npcPositionV1) TableName() string {
+	return "npc_positions"
+}
+
+type npcScheduleV1 struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key"`
+	NPCID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	DayOfWeek int       `gorm:"not null"`
+	TimeOfDay int       `gorm:"not null"`
+	MapID     uuid.UUID `gorm:"type:char(36);not null"`
+	PosX      int       `gorm:"not null"`
+	PosY      int       `gorm:"not null"`
+	Action    string
+	CreatedAt time.Time
+
+	// Relationships
+	NPC npcV1 `gorm:"foreignKey:NPCID"`
+	Map mapV1 `gorm:"foreignKey:MapID"`
+}
+
+func (npcScheduleV1) TableName() string {
+	return "npc_schedules"
+}
+
+type gameClockV1 struct {
+	ID         uuid.UUID `gorm:"type:char(36);primary_key"`
+	GameYear   int       `gorm:"default:1"`
+	GameSeason string    `gorm:"default:'spring'"`
+	GameDay    int       `gorm:"default:1"`
+	GameHour   int       `gorm:"default:6"`
+	GameMinute int       `gorm:"default:0"`
+	IsPaused   bool      `gorm:"default:false"`
+	TimeScale  float64   `gorm:"default:1.0"`
+	UpdatedAt  time.Time
+}
+
+func (gameClockV1) TableName() string {
+	return "game_clocks"
+}
+
+type codeFarmV1 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index"`
+	PlotX       int       `gorm:"not null"`
+	PlotY       int       `gorm:"not null"`
+	CodeType    string
+	PlantedAt   *time.Time
+	LastWatered *time.Time
+	HarvestAt   *time.Time
+	GrowthStage int    `gorm:"default:0"`
+	Quality     string `gorm:"default:'normal'"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (codeFarmV1) TableName() string {
+	return "code_farms"
+}
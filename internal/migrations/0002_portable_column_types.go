@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type enumColumn struct {
+	Table   string
+	Column  string
+	Values  []string
+	NotNull bool
+	Default string
+}
+
+// legacyEnumColumns are the MySQL ENUM columns that older releases created.
+// New databases already get VARCHAR columns from the baseline, so this only
+// rewrites MySQL schemas that were built by AutoMigrate.
+var legacyEnumColumns = []enumColumn{
+	{"badges", "type", []string{"achievement", "event", "special", "seasonal"}, true, ""},
+	{"badges", "rarity", []string{"common", "uncommon", "rare", "epic", "legendary"}, false, "common"},
+	{"code_battles", "difficulty", []string{"easy", "medium", "hard"}, true, ""},
+	{"code_battles", "status", []string{"in_progress", "completed", "failed"}, false, "in_progress"},
+	{"crafting_sessions", "status", []string{"in_progress", "completed", "cancelled"}, false, "in_progress"},
+	{"events", "type", []string{"hackathon", "festival", "competition", "seasonal"}, true, ""},
+	{"event_participants", "status", []string{"joined", "completed", "dropped"}, false, "joined"},
+	{"friendships", "status", []string{"pending", "accepted", "blocked"}, false, "pending"},
+	{"guild_members", "role", []string{"owner", "officer", "member"}, false, "member"},
+	{"guild_invitations", "status", []string{"pending", "accepted", "blocked"}, false, "pending"},
+	{"inventories", "item_type", []string{"tool", "code", "snippet", "resource"}, true, ""},
+	{"marketplace_listings", "item_type", []string{"tool", "code", "snippet", "resource"}, true, ""},
+	{"marketplace_listings", "status", []string{"active", "sold", "expired"}, false, "active"},
+	{"mini_games", "type", []string{"quiz", "puzzle", "regex", "algorithm"}, true, ""},
+	{"mini_games", "difficulty", []string{"easy", "medium", "hard"}, true, ""},
+	{"mini_game_sessions", "status", []string{"active", "completed", "failed", "timeout"}, false, "active"},
+	{"notifications", "type", []string{"quest", "friend", "achievement", "system", "npc", "event"}, true, ""},
+	{"npcs", "role", []string{"mentor", "client", "villager"}, true, ""},
+	{"user_quest_progresses", "status", []string{"not_started", "in_progress", "completed"}, false, "not_started"},
+	{"shop_items", "item_type", []string{"tool", "upgrade", "cosmetic", "resource", "skill"}, true, ""},
+	{"skills", "category", []string{"programming", "debugging", "optimization", "social", "economic"}, true, ""},
+	{"tutorials", "category", []string{"basics", "advanced", "framework", "algorithm", "best_practice"}, true, ""},
+	{"user_tutorial_progresses", "status", []string{"not_started", "in_progress", "completed"}, false, "not_started"},
+	{"users", "role", []string{"player", "admin"}, false, "player"},
+	{"maps", "type", []string{"village", "code_mine", "data_farm", "office", "library", "lab"}, true, ""},
+	{"world_objects", "object_type", []string{"tree", "rock", "chest", "server", "workstation", "code_block", "bug_hive"}, true, ""},
+}
+
+var portableColumnTypes = Migration{
+	Version: 2,
+	Name:    "portable_column_types",
+	Up: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() != "mysql" {
+			return nil
+		}
+		for _, col := range legacyEnumColumns {
+			if err := tx.Exec(col.modify("VARCHAR(32)")).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() != "mysql" {
+			return nil
+		}
+		for _, col := range legacyEnumColumns {
+			quoted := make([]string, len(col.Values))
+			for i, value := range col.Values {
+				quoted[i] = "'" + value + "'"
+			}
+			if err := tx.Exec(col.modify("ENUM(" + strings.Join(quoted, ",") + ")")).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func (c enumColumn) modify(dataType string) string {
+	sql := fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN `%s` %s", c.Table, c.Column, dataType)
+	if c.NotNull {
+		sql += " NOT NULL"
+	}
+	if c.Default != "" {
+		sql += fmt.Sprintf(" DEFAULT '%s'", c.Default)
+	}
+	return sql
+}
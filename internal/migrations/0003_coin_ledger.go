@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type coinLedgerEntryV3 struct {
+	ID            uuid.UUID  `gorm:"type:char(36);primary_key"`
+	TransactionID uuid.UUID  `gorm:"type:char(36);not null;index"`
+	Account       string     `gorm:"type:varchar(32);not null;index:idx_coin_ledger_account"`
+	UserID        *uuid.UUID `gorm:"type:char(36);index:idx_coin_ledger_account"`
+	Amount        int        `gorm:"not null"`
+	Reason        string     `gorm:"type:varchar(32);not null;index"`
+	ReferenceType string     `gorm:"type:varchar(32)"`
+	ReferenceID   *uuid.UUID `gorm:"type:char(36);index"`
+	CreatedAt     time.Time  `gorm:"index"`
+}
+
+func (coinLedgerEntryV3) TableName() string {
+	return "coin_ledger"
+}
+
+// coinLedger adds the coin ledger and opens every existing wallet with the
+// user's current balance so that balances are derivable from day one.
+var coinLedger = Migration{
+	Version: 3,
+	Name:    "coin_ledger",
+	Up: func(tx *gorm.DB) error {
+		if err := createTables(tx, &coinLedgerEntryV3{}); err != nil {
+			return err
+		}
+		return PostOpeningBalances(tx)
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &coinLedgerEntryV3{})
+	},
+}
+
+// PostOpeningBalances moves each user's coins from the opening balance
+// account into their wallet, skipping wallets that already have entries.
+// Seeders call it for the users they insert after migrating.
+func PostOpeningBalances(tx *gorm.DB) error {
+	var users []struct {
+		ID    uuid.UUID
+		Coins int
+	}
+	err := tx.Table("users").Select("id, coins").
+		Where("coins <> 0").
+		Where("NOT EXISTS (SELECT 1 FROM coin_ledger WHERE coin_ledger.user_id = users.id AND coin_ledger.account = ?)", "wallet").
+		Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		userID := user.ID
+		transactionID := uuid.New()
+		now := time.Now()
+		entries := []coinLedgerEntryV3{
+			{
+				ID: uuid.New(), TransactionID: transactionID, Account: "wallet", UserID: &userID,
+				Amount: user.Coins, Reason: "opening_balance", ReferenceType: "user", ReferenceID: &userID, CreatedAt: now,
+			},
+			{
+				ID: uuid.New(), TransactionID: transactionID, Account: "opening_balance",
+				Amount: -user.Coins, Reason: "opening_balance", ReferenceType: "user", ReferenceID: &userID, CreatedAt: now,
+			},
+		}
+		if err := tx.Create(&entries).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type refreshTokenV4 struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	FamilyID  uuid.UUID `gorm:"type:char(36);not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (refreshTokenV4) TableName() string {
+	return "refresh_tokens"
+}
+
+var refreshTokens = Migration{
+	Version: 4,
+	Name:    "refresh_tokens",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &refreshTokenV4{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &refreshTokenV4{})
+	},
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type sessionV5 struct {
+	ID         uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	DeviceName string    `gorm:"type:varchar(100)"`
+	IPAddress  string    `gorm:"type:varchar(45)"`
+	UserAgent  string    `gorm:"type:varchar(255)"`
+	LastSeenAt time.Time
+	ExpiresAt  time.Time `gorm:"not null"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	// Relationships
+	User userV1 `gorm:"foreignKey:UserID"`
+}
+
+func (sessionV5) TableName() string {
+	return "sessions"
+}
+
+// sessions adds per-device sessions and backfills one for every refresh
+// token family that can still be used, so existing logins keep working.
+var sessions = Migration{
+	Version: 5,
+	Name:    "sessions",
+	Up: func(tx *gorm.DB) error {
+		if err := createTables(tx, &sessionV5{}); err != nil {
+			return err
+		}
+
+		var tokens []refreshTokenV4
+		err := tx.Where("used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", time.Now()).
+			Find(&tokens).Error
+		if err != nil {
+			return err
+		}
+
+		for _, token := range tokens {
+			session := sessionV5{
+				ID:         token.FamilyID,
+				UserID:     token.UserID,
+				LastSeenAt: token.CreatedAt,
+				ExpiresAt:  token.ExpiresAt,
+				CreatedAt:  token.CreatedAt,
+			}
+			if err := tx.Create(&session).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &sessionV5{})
+	},
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type userBanV6 struct {
+	ID         uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID     uuid.UUID `gorm:"type:char(36);not null;index"`
+	Reason     string    `gorm:"type:text;not null"`
+	IssuedByID uuid.UUID `gorm:"type:char(36);not null"`
+	StartsAt   time.Time `gorm:"not null"`
+	ExpiresAt  *time.Time
+	LiftedAt   *time.Time
+	LiftedByID *uuid.UUID `gorm:"type:char(36)"`
+	CreatedAt  time.Time
+
+	// Relationships
+	User     userV1 `gorm:"foreignKey:UserID"`
+	IssuedBy userV1 `gorm:"foreignKey:IssuedByID"`
+}
+
+func (userBanV6) TableName() string {
+	return "user_bans"
+}
+
+var userBans = Migration{
+	Version: 6,
+	Name:    "user_bans",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &userBanV6{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &userBanV6{})
+	},
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type auditLogV7 struct {
+	ID         uuid.UUID  `gorm:"type:char(36);primary_key"`
+	ActorID    *uuid.UUID `gorm:"type:char(36);index"`
+	Action     string     `gorm:"type:varchar(50);not null;index"`
+	TargetType string     `gorm:"type:varchar(50)"`
+	TargetID   *uuid.UUID `gorm:"type:char(36);index"`
+	Changes    string     `gorm:"type:json"`
+	IPAddress  string     `gorm:"type:varchar(45)"`
+	UserAgent  string     `gorm:"type:varchar(255)"`
+	RequestID  string     `gorm:"type:varchar(64);index"`
+	CreatedAt  time.Time  `gorm:"index"`
+}
+
+func (auditLogV7) TableName() string {
+	return "audit_logs"
+}
+
+var auditLogs = Migration{
+	Version: 7,
+	Name:    "audit_logs",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &auditLogV7{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &auditLogV7{})
+	},
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+type onlineUserV8 struct {
+	Status string `gorm:"type:varchar(16);default:'online'"`
+}
+
+func (onlineUserV8) TableName() string {
+	return "online_users"
+}
+
+var presenceStatus = Migration{
+	Version: 8,
+	Name:    "presence_status",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&onlineUserV8{}, "Status")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&onlineUserV8{}, "Status")
+	},
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type chatMessageV9 struct {
+	ID          uuid.UUID  `gorm:"type:char(36);primary_key"`
+	Channel     string     `gorm:"type:varchar(16);not null;index:idx_chat_channel_scope"`
+	ScopeID     *uuid.UUID `gorm:"type:char(36);index:idx_chat_channel_scope"`
+	SenderID    uuid.UUID  `gorm:"type:char(36);not null;index"`
+	RecipientID *uuid.UUID `gorm:"type:char(36);index"`
+	Content     string     `gorm:"type:text;not null"`
+	Filtered    bool
+	RemovedAt   *time.Time
+	RemovedByID *uuid.UUID `gorm:"type:char(36)"`
+	CreatedAt   time.Time  `gorm:"index"`
+
+	// Relationships
+	Sender userV1 `gorm:"foreignKey:SenderID"`
+}
+
+func (chatMessageV9) TableName() string {
+	return "chat_messages"
+}
+
+type chatMuteV9 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_chat_mute_pair"`
+	MutedUserID uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_chat_mute_pair;index"`
+	ExpiresAt   *time.Time
+	CreatedAt   time.Time
+
+	// Relationships
+	MutedUser userV1 `gorm:"foreignKey:MutedUserID"`
+}
+
+func (chatMuteV9) TableName() string {
+	return "chat_mutes"
+}
+
+var chat = Migration{
+	Version: 9,
+	Name:    "chat",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &chatMessageV9{}, &chatMuteV9{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &chatMuteV9{}, &chatMessageV9{})
+	},
+}
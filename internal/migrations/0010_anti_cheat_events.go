@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type antiCheatEventV10 struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;index"`
+	MapID     uuid.UUID `gorm:"type:char(36);not null"`
+	Kind      string    `gorm:"type:varchar(32);not null;index"`
+	FromX     int
+	FromY     int
+	ToX       int
+	ToY       int
+	ElapsedMs int64
+	Details   string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (antiCheatEventV10) TableName() string {
+	return "anti_cheat_events"
+}
+
+var antiCheatEvents = Migration{
+	Version: 10,
+	Name:    "anti_cheat_events",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &antiCheatEventV10{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &antiCheatEventV10{})
+	},
+}
@@ -0,0 +1,164 @@
+package migrations
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// structuredMapLayouts rewrites the free-form layouts older releases stored
+// into the structured layout. The old spawn_point becomes the default spawn
+// point, buildings become one-tile building zones and anything else is kept
+// under properties.
+var structuredMapLayouts = Migration{
+	Version: 11,
+	Name:    "structured_map_layouts",
+	Up: func(tx *gorm.DB) error {
+		return rewriteLayouts(tx, func(raw map[string]json.RawMessage) (interface{}, bool) {
+			for key := range raw {
+				if !layoutKeys[key] {
+					return legacyToLayout(raw), true
+				}
+			}
+			return nil, false
+		})
+	},
+	Down: func(tx *gorm.DB) error {
+		return rewriteLayouts(tx, func(raw map[string]json.RawMessage) (interface{}, bool) {
+			return layoutToLegacy(raw), true
+		})
+	},
+}
+
+var layoutKeys = map[string]bool{"layers": true, "walkable": true, "spawn_points": true, "zones": true, "properties": true}
+
+type legacyPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type legacyBuilding struct {
+	Type string `json:"type"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+// layoutV11 is the structured layout as this migration wrote it.
+type layoutV11 struct {
+	Layers      json.RawMessage        `json:"layers,omitempty"`
+	Walkable    []string               `json:"walkable,omitempty"`
+	SpawnPoints []spawnPointV11        `json:"spawn_points,omitempty"`
+	Zones       []zoneV11              `json:"zones,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+}
+
+type spawnPointV11 struct {
+	Name string `json:"name"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+type zoneV11 struct {
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	X          int                    `json:"x"`
+	Y          int                    `json:"y"`
+	Width      int                    `json:"width"`
+	Height     int                    `json:"height"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+const (
+	defaultSpawnV11 = "default"
+	buildingZoneV11 = "building"
+)
+
+// rewriteLayouts replaces every map layout convert returns a new value for.
+func rewriteLayouts(tx *gorm.DB, convert func(map[string]json.RawMessage) (interface{}, bool)) error {
+	var rows []struct {
+		ID     string
+		Layout string
+	}
+	if err := tx.Table("maps").Select("id, layout").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		raw := make(map[string]json.RawMessage)
+		if row.Layout != "" && row.Layout != "null" {
+			if err := json.Unmarshal([]byte(row.Layout), &raw); err != nil {
+				return err
+			}
+		}
+
+		layout, changed := convert(raw)
+		if !changed {
+			continue
+		}
+		data, err := json.Marshal(layout)
+		if err != nil {
+			return err
+		}
+		if err := tx.Table("maps").Where("id = ?", row.ID).Update("layout", string(data)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func legacyToLayout(raw map[string]json.RawMessage) layoutV11 {
+	layout := layoutV11{Properties: make(map[string]interface{})}
+	for key, value := range raw {
+		var point legacyPoint
+		var buildings []legacyBuilding
+		switch {
+		case key == "spawn_point" && json.Unmarshal(value, &point) == nil:
+			layout.SpawnPoints = append(layout.SpawnPoints, spawnPointV11{Name: defaultSpawnV11, X: point.X, Y: point.Y})
+		case key == "buildings" && json.Unmarshal(value, &buildings) == nil:
+			for _, building := range buildings {
+				layout.Zones = append(layout.Zones, zoneV11{
+					Name: building.Type, Type: buildingZoneV11,
+					X: building.X, Y: building.Y, Width: 1, Height: 1,
+				})
+			}
+		default:
+			var property interface{}
+			json.Unmarshal(value, &property)
+			layout.Properties[key] = property
+		}
+	}
+	if len(layout.Properties) == 0 {
+		layout.Properties = nil
+	}
+	return layout
+}
+
+func layoutToLegacy(raw map[string]json.RawMessage) map[string]interface{} {
+	var layout layoutV11
+	data, _ := json.Marshal(raw)
+	json.Unmarshal(data, &layout)
+
+	legacy := make(map[string]interface{})
+	for key, value := range layout.Properties {
+		legacy[key] = value
+	}
+	for i, spawn := range layout.SpawnPoints {
+		// The default spawn point, or the first if none is named default
+		if spawn.Name == defaultSpawnV11 || i == 0 {
+			legacy["spawn_point"] = legacyPoint{X: spawn.X, Y: spawn.Y}
+		}
+		if spawn.Name == defaultSpawnV11 {
+			break
+		}
+	}
+	var buildings []legacyBuilding
+	for _, zone := range layout.Zones {
+		if zone.Type == buildingZoneV11 {
+			buildings = append(buildings, legacyBuilding{Type: zone.Name, X: zone.X, Y: zone.Y})
+		}
+	}
+	if buildings != nil {
+		legacy["buildings"] = buildings
+	}
+	return legacy
+}
@@ -0,0 +1,93 @@
+package migrations
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type lootTableV12 struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key"`
+	Name        string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Description string    `gorm:"type:text"`
+	Rolls       int       `gorm:"not null;default:1"`
+	Entries     string    `gorm:"type:json"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (lootTableV12) TableName() string {
+	return "loot_tables"
+}
+
+type lootEntryV12 struct {
+	ItemName    string `json:"item_name"`
+	ItemType    string `json:"item_type,omitempty"`
+	Weight      int    `json:"weight"`
+	MinQuantity int    `json:"min_quantity"`
+	MaxQuantity int    `json:"max_quantity"`
+	Rarity      string `json:"rarity"`
+	// Conditions were all empty to start with
+	Conditions struct{} `json:"conditions"`
+}
+
+type inventoryV12 struct {
+	Equipped bool `gorm:"default:false"`
+}
+
+func (inventoryV12) TableName() string {
+	return "inventories"
+}
+
+// lootTables adds loot tables, starting them off with what trees, rocks and
+// chests dropped before, and lets players equip a tool.
+var lootTables = Migration{
+	Version: 12,
+	Name:    "loot_tables",
+	Up: func(tx *gorm.DB) error {
+		if err := createTables(tx, &lootTableV12{}); err != nil {
+			return err
+		}
+		if err := tx.Migrator().AddColumn(&inventoryV12{}, "Equipped"); err != nil {
+			return err
+		}
+
+		for _, table := range defaultLootTables() {
+			table.ID = uuid.New()
+			if err := tx.Where("name = ?", table.Name).FirstOrCreate(&table).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropColumn(&inventoryV12{}, "Equipped"); err != nil {
+			return err
+		}
+		return dropTables(tx, &lootTableV12{})
+	},
+}
+
+func defaultLootTables() []lootTableV12 {
+	one := func(name, itemType string, quantity int) lootEntryV12 {
+		return lootEntryV12{
+			ItemName: name, ItemType: itemType, Weight: 1,
+			MinQuantity: quantity, MaxQuantity: quantity, Rarity: "common",
+		}
+	}
+	table := func(name, description string, entries ...lootEntryV12) lootTableV12 {
+		data, _ := json.Marshal(entries)
+		return lootTableV12{Name: name, Description: description, Rolls: 1, Entries: string(data)}
+	}
+	return []lootTableV12{
+		table("tree", "Chopped trees", one("Code Snippet", "resource", 2)),
+		table("rock", "Mined rocks", one("Raw Data", "resource", 1)),
+		table("chest", "Opened chests",
+			one("Debug Tool", "tool", 1),
+			one("Refactor Kit", "tool", 1),
+			one("Unit Test Template", "tool", 1),
+		),
+	}
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// All returns every schema migration in version order. New migrations are
+// appended here and must never be edited once released.
+func All() []Migration {
+	return []Migration{
+		baseline,
+		portableColumnTypes,
+		coinLedger,
+		refreshTokens,
+		sessions,
+		userBans,
+		auditLogs,
+		presenceStatus,
+		chat,
+		antiCheatEvents,
+		structuredMapLayouts,
+		lootTables,
+	}
+}
+
+// createTables creates each table that does not exist yet, so a migration
+// can adopt databases that were previously built by AutoMigrate. GORM orders
+// the tables by their foreign key dependencies.
+func createTables(tx *gorm.DB, tables ...interface{}) error {
+	var missing []interface{}
+	for _, table := range tables {
+		if !tx.Migrator().HasTable(table) {
+			missing = append(missing, table)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return tx.Migrator().CreateTable(missing...)
+}
+
+// dropTables drops the given tables, dependents first.
+func dropTables(tx *gorm.DB, tables ...interface{}) error {
+	return tx.Migrator().DropTable(tables...)
+}
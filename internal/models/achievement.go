@@ -19,7 +19,7 @@ func (c *Conditions) Scan(value interface{}) error {
 		*c = make(Conditions)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -27,7 +27,7 @@ func (c *Conditions) Scan(value interface{}) error {
 }
 
 type Achievement struct {
-	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primary_key"`
 	Title       string     `json:"title" gorm:"not null" validate:"required"`
 	Description string     `json:"description" gorm:"type:text" validate:"required"`
 	RewardCoins int        `json:"reward_coins" gorm:"default:0"`
@@ -50,7 +50,7 @@ func (a *Achievement) BeforeCreate(tx *gorm.DB) error {
 }
 
 type UserAchievement struct {
-	ID            uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID            uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID        uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	AchievementID uuid.UUID `json:"achievement_id" gorm:"type:char(36);not null;index"`
 	UnlockedAt    time.Time `json:"unlocked_at"`
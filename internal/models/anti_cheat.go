@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CheatKind string
+
+const (
+	// CheatSpeed is a move far beyond the player's movement budget
+	CheatSpeed CheatKind = "speed"
+	// CheatWallClip is a move with no walkable path, e.g. through a wall
+	// or between two diagonal blockers
+	CheatWallClip CheatKind = "wall_clip"
+	// CheatOutOfBounds is a move off the edge of the map
+	CheatOutOfBounds CheatKind = "out_of_bounds"
+)
+
+// AntiCheatEvent records a rejected move no honest client would send, for
+// admins to review. Ordinary rejections such as walking into a rock or a
+// little lag are corrected without being recorded.
+type AntiCheatEvent struct {
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
+	MapID     uuid.UUID `json:"map_id" gorm:"type:char(36);not null"`
+	Kind      CheatKind `json:"kind" gorm:"type:varchar(32);not null;index"`
+	FromX     int       `json:"from_x"`
+	FromY     int       `json:"from_y"`
+	ToX       int       `json:"to_x"`
+	ToY       int       `json:"to_y"`
+	ElapsedMs int64     `json:"elapsed_ms"` // since the previous accepted move
+	Details   string    `json:"details" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+func (e *AntiCheatEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// AntiCheatCount is how often a user tripped one kind of check.
+type AntiCheatCount struct {
+	UserID uuid.UUID `json:"user_id"`
+	Kind   CheatKind `json:"kind"`
+	Count  int64     `json:"count"`
+	LastAt time.Time `json:"last_at"`
+}
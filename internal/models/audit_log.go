@@ -0,0 +1,139 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditActionUserBan        AuditAction = "user.ban"
+	AuditActionUserUnban      AuditAction = "user.unban"
+	AuditActionUserRoleChange AuditAction = "user.role_change"
+	AuditActionQuestCreate    AuditAction = "quest.create"
+	AuditActionQuestUpdate    AuditAction = "quest.update"
+	AuditActionQuestDelete    AuditAction = "quest.delete"
+	AuditActionLogin          AuditAction = "auth.login"
+	AuditActionLoginFailed    AuditAction = "auth.login_failed"
+	AuditActionAccountDelete  AuditAction = "account.delete"
+	AuditActionLargeCoinMove  AuditAction = "coins.large_movement"
+	AuditActionChatDelete     AuditAction = "chat.delete"
+	AuditActionMapLayout      AuditAction = "map.layout_update"
+	AuditActionMapImport      AuditAction = "map.import"
+	AuditActionLootCreate     AuditAction = "loot_table.create"
+	AuditActionLootUpdate     AuditAction = "loot_table.update"
+	AuditActionLootDelete     AuditAction = "loot_table.delete"
+	AuditActionTeleport       AuditAction = "player.teleport"
+)
+
+const (
+	AuditTargetUser  = "user"
+	AuditTargetQuest = "quest"
+	AuditTargetChat  = "chat_message"
+	AuditTargetMap   = "map"
+	AuditTargetLoot  = "loot_table"
+)
+
+// ErrAuditLogImmutable is returned when something tries to rewrite history.
+var ErrAuditLogImmutable = errors.New("audit log entries cannot be modified")
+
+// AuditChange is the value of a single field before and after an action.
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditChanges maps field names to how they changed.
+type AuditChanges map[string]AuditChange
+
+func (ac AuditChanges) Value() (driver.Value, error) {
+	return json.Marshal(ac)
+}
+
+func (ac *AuditChanges) Scan(value interface{}) error {
+	if value == nil {
+		*ac = make(AuditChanges)
+		return nil
+	}
+	bytes, ok := jsonBytes(value)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, ac)
+}
+
+// auditIgnoredFields change on every write and would only add noise.
+var auditIgnoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// DiffChanges compares the JSON form of two snapshots of the same record and
+// returns the fields that differ. Either side may be nil for creates and
+// deletes.
+func DiffChanges(before, after interface{}) AuditChanges {
+	from, to := jsonFields(before), jsonFields(after)
+
+	changes := make(AuditChanges)
+	for field, value := range from {
+		if !auditIgnoredFields[field] && !reflect.DeepEqual(value, to[field]) {
+			changes[field] = AuditChange{From: value, To: to[field]}
+		}
+	}
+	for field, value := range to {
+		if _, seen := from[field]; !seen && !auditIgnoredFields[field] {
+			changes[field] = AuditChange{To: value}
+		}
+	}
+	return changes
+}
+
+func jsonFields(v interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if v == nil {
+		return fields
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// AuditLog records who did what to which record, from where. Entries are
+// append-only.
+type AuditLog struct {
+	ID         uuid.UUID    `json:"id" gorm:"type:char(36);primary_key"`
+	ActorID    *uuid.UUID   `json:"actor_id" gorm:"type:char(36);index"`
+	Action     AuditAction  `json:"action" gorm:"type:varchar(50);not null;index"`
+	TargetType string       `json:"target_type" gorm:"type:varchar(50)"`
+	TargetID   *uuid.UUID   `json:"target_id" gorm:"type:char(36);index"`
+	Changes    AuditChanges `json:"changes" gorm:"type:json"`
+	IPAddress  string       `json:"ip_address" gorm:"type:varchar(45)"`
+	UserAgent  string       `json:"user_agent" gorm:"type:varchar(255)"`
+	RequestID  string       `json:"request_id" gorm:"type:varchar(64);index"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"index"`
+}
+
+func (al *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if al.ID == uuid.Nil {
+		al.ID = uuid.New()
+	}
+	return nil
+}
+
+func (al *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+func (al *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -29,11 +29,11 @@ const (
 )
 
 type Badge struct {
-	ID          uuid.UUID   `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID   `json:"id" gorm:"type:char(36);primary_key"`
 	Name        string      `json:"name" gorm:"not null;index" validate:"required"`
 	Description string      `json:"description" gorm:"type:text"`
-	Type        BadgeType   `json:"type" gorm:"type:enum('achievement','event','special','seasonal');not null"`
-	Rarity      BadgeRarity `json:"rarity" gorm:"type:enum('common','uncommon','rare','epic','legendary');default:'common'"`
+	Type        BadgeType   `json:"type" gorm:"type:varchar(32);not null"`
+	Rarity      BadgeRarity `json:"rarity" gorm:"type:varchar(32);default:'common'"`
 	IconURL     string      `json:"icon_url"`
 	Conditions  Conditions  `json:"conditions" gorm:"type:json"`
 	IsActive    bool        `json:"is_active" gorm:"default:true"`
@@ -52,7 +52,7 @@ func (b *Badge) BeforeCreate(tx *gorm.DB) error {
 }
 
 type UserBadge struct {
-	ID       uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID       uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID   uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	BadgeID  uuid.UUID `json:"badge_id" gorm:"type:char(36);not null;index"`
 	EarnedAt time.Time `json:"earned_at"`
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserBan suspends a user from StartsAt until ExpiresAt. A nil ExpiresAt
+// makes the ban permanent; lifting a ban records who ended it early.
+type UserBan struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:char(36);primary_key"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
+	Reason     string     `json:"reason" gorm:"type:text;not null"`
+	IssuedByID uuid.UUID  `json:"issued_by_id" gorm:"type:char(36);not null"`
+	StartsAt   time.Time  `json:"starts_at" gorm:"not null"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LiftedAt   *time.Time `json:"lifted_at"`
+	LiftedByID *uuid.UUID `json:"lifted_by_id" gorm:"type:char(36)"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Relationships
+	User     User `json:"-" gorm:"foreignKey:UserID"`
+	IssuedBy User `json:"-" gorm:"foreignKey:IssuedByID"`
+}
+
+func (ub *UserBan) BeforeCreate(tx *gorm.DB) error {
+	if ub.ID == uuid.Nil {
+		ub.ID = uuid.New()
+	}
+	return nil
+}
+
+func (ub *UserBan) IsPermanent() bool {
+	return ub.ExpiresAt == nil
+}
+
+// IsActive reports whether the ban is in force at the given time.
+func (ub *UserBan) IsActive(now time.Time) bool {
+	if ub.LiftedAt != nil || now.Before(ub.StartsAt) {
+		return false
+	}
+	return ub.ExpiresAt == nil || now.Before(*ub.ExpiresAt)
+}
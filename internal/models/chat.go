@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ChatChannel string
+
+const (
+	ChatChannelGlobal  ChatChannel = "global"
+	ChatChannelMap     ChatChannel = "map"
+	ChatChannelGuild   ChatChannel = "guild"
+	ChatChannelWhisper ChatChannel = "whisper"
+)
+
+// ChatMessage is a message posted to a channel. ScopeID is the map or guild
+// for map and guild channels; RecipientID is set only for whispers.
+// Messages removed by an admin are kept for moderation but hidden from
+// history.
+type ChatMessage struct {
+	ID          uuid.UUID   `json:"id" gorm:"type:char(36);primary_key"`
+	Channel     ChatChannel `json:"channel" gorm:"type:varchar(16);not null;index:idx_chat_channel_scope"`
+	ScopeID     *uuid.UUID  `json:"scope_id,omitempty" gorm:"type:char(36);index:idx_chat_channel_scope"`
+	SenderID    uuid.UUID   `json:"sender_id" gorm:"type:char(36);not null;index"`
+	RecipientID *uuid.UUID  `json:"recipient_id,omitempty" gorm:"type:char(36);index"`
+	Content     string      `json:"content" gorm:"type:text;not null"`
+	Filtered    bool        `json:"filtered"`
+	RemovedAt   *time.Time  `json:"-"`
+	RemovedByID *uuid.UUID  `json:"-" gorm:"type:char(36)"`
+	CreatedAt   time.Time   `json:"created_at" gorm:"index"`
+
+	// Relationships
+	Sender User `json:"-" gorm:"foreignKey:SenderID"`
+}
+
+func (cm *ChatMessage) BeforeCreate(tx *gorm.DB) error {
+	if cm.ID == uuid.Nil {
+		cm.ID = uuid.New()
+	}
+	return nil
+}
+
+// ChatMessageResponse is what other players see of a message and its
+// sender.
+type ChatMessageResponse struct {
+	ID             uuid.UUID   `json:"id"`
+	Channel        ChatChannel `json:"channel"`
+	ScopeID        *uuid.UUID  `json:"scope_id,omitempty"`
+	SenderID       uuid.UUID   `json:"sender_id"`
+	SenderUsername string      `json:"sender_username"`
+	SenderAvatar   string      `json:"sender_avatar_url"`
+	RecipientID    *uuid.UUID  `json:"recipient_id,omitempty"`
+	Content        string      `json:"content"`
+	Filtered       bool        `json:"filtered"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+func (cm *ChatMessage) ToResponse() ChatMessageResponse {
+	return ChatMessageResponse{
+		ID:             cm.ID,
+		Channel:        cm.Channel,
+		ScopeID:        cm.ScopeID,
+		SenderID:       cm.SenderID,
+		SenderUsername: cm.Sender.Username,
+		SenderAvatar:   cm.Sender.AvatarURL,
+		RecipientID:    cm.RecipientID,
+		Content:        cm.Content,
+		Filtered:       cm.Filtered,
+		CreatedAt:      cm.CreatedAt,
+	}
+}
+
+// ChatMute hides another user's messages from UserID until ExpiresAt, or
+// for good when ExpiresAt is nil.
+type ChatMute struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primary_key"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;uniqueIndex:idx_chat_mute_pair"`
+	MutedUserID uuid.UUID  `json:"muted_user_id" gorm:"type:char(36);not null;uniqueIndex:idx_chat_mute_pair;index"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Relationships
+	MutedUser User `json:"-" gorm:"foreignKey:MutedUserID"`
+}
+
+func (cm *ChatMute) BeforeCreate(tx *gorm.DB) error {
+	if cm.ID == uuid.Nil {
+		cm.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive reports whether the mute is in force at the given time.
+func (cm *ChatMute) IsActive(now time.Time) bool {
+	return cm.ExpiresAt == nil || now.Before(*cm.ExpiresAt)
+}
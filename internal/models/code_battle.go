@@ -23,11 +23,11 @@ const (
 )
 
 type CodeBattle struct {
-	ID            uuid.UUID        `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID            uuid.UUID        `json:"id" gorm:"type:char(36);primary_key"`
 	UserID        uuid.UUID        `json:"user_id" gorm:"type:char(36);not null;index"`
 	ChallengeName string           `json:"challenge_name" gorm:"not null" validate:"required"`
-	Difficulty    BattleDifficulty `json:"difficulty" gorm:"type:enum('easy','medium','hard');not null" validate:"required"`
-	Status        BattleStatus     `json:"status" gorm:"type:enum('in_progress','completed','failed');default:'in_progress'"`
+	Difficulty    BattleDifficulty `json:"difficulty" gorm:"type:varchar(32);not null" validate:"required"`
+	Status        BattleStatus     `json:"status" gorm:"type:varchar(32);default:'in_progress'"`
 	Score         int              `json:"score" gorm:"default:0"`
 	StartedAt     time.Time        `json:"started_at"`
 	CompletedAt   *time.Time       `json:"completed_at"`
@@ -10,7 +10,7 @@ import (
 )
 
 type CraftingRecipe struct {
-	ID             uuid.UUID     `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID             uuid.UUID     `json:"id" gorm:"type:char(36);primary_key"`
 	Name           string        `json:"name" gorm:"not null;index" validate:"required"`
 	Description    string        `json:"description" gorm:"type:text"`
 	RequiredItems  RequiredItems `json:"required_items" gorm:"type:json"`
@@ -42,10 +42,10 @@ const (
 )
 
 type CraftingSession struct {
-	ID          uuid.UUID      `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID      `json:"id" gorm:"type:char(36);primary_key"`
 	UserID      uuid.UUID      `json:"user_id" gorm:"type:char(36);not null;index"`
 	RecipeID    uuid.UUID      `json:"recipe_id" gorm:"type:char(36);not null;index"`
-	Status      CraftingStatus `json:"status" gorm:"type:enum('in_progress','completed','cancelled');default:'in_progress'"`
+	Status      CraftingStatus `json:"status" gorm:"type:varchar(32);default:'in_progress'"`
 	StartedAt   time.Time      `json:"started_at"`
 	CompletedAt *time.Time     `json:"completed_at"`
 
@@ -7,7 +7,7 @@ import (
 )
 
 type DailyTask struct {
-	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	TaskName    string    `json:"task_name" gorm:"not null" validate:"required"`
 	TaskType    string    `json:"task_type" gorm:"not null" validate:"required"`
 	Description string    `json:"description" gorm:"type:text" validate:"required"`
@@ -30,7 +30,7 @@ func (dt *DailyTask) BeforeCreate(tx *gorm.DB) error {
 }
 
 type UserDailyTaskProgress struct {
-	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primary_key"`
 	UserID      uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
 	DailyTaskID uuid.UUID  `json:"daily_task_id" gorm:"type:char(36);not null;index"`
 	CompletedAt *time.Time `json:"completed_at"`
@@ -28,7 +28,7 @@ func (er *EventRewards) Scan(value interface{}) error {
 		*er = make(EventRewards)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -36,10 +36,10 @@ func (er *EventRewards) Scan(value interface{}) error {
 }
 
 type Event struct {
-	ID           uuid.UUID    `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID           uuid.UUID    `json:"id" gorm:"type:char(36);primary_key"`
 	Name         string       `json:"name" gorm:"not null;index" validate:"required"`
 	Description  string       `json:"description" gorm:"type:text"`
-	Type         EventType    `json:"type" gorm:"type:enum('hackathon','festival','competition','seasonal');not null"`
+	Type         EventType    `json:"type" gorm:"type:varchar(32);not null"`
 	StartDate    time.Time    `json:"start_date" gorm:"not null;index"`
 	EndDate      time.Time    `json:"end_date" gorm:"not null;index"`
 	Requirements EventRewards `json:"requirements" gorm:"type:json"`
@@ -69,10 +69,10 @@ const (
 )
 
 type EventParticipant struct {
-	ID        uuid.UUID         `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID        uuid.UUID         `json:"id" gorm:"type:char(36);primary_key"`
 	UserID    uuid.UUID         `json:"user_id" gorm:"type:char(36);not null;index"`
 	EventID   uuid.UUID         `json:"event_id" gorm:"type:char(36);not null;index"`
-	Status    ParticipantStatus `json:"status" gorm:"type:enum('joined','completed','dropped');default:'joined'"`
+	Status    ParticipantStatus `json:"status" gorm:"type:varchar(32);default:'joined'"`
 	Score     int               `json:"score" gorm:"default:0"`
 	JoinedAt  time.Time         `json:"joined_at"`
 	CompletedAt *time.Time      `json:"completed_at"`
@@ -15,10 +15,10 @@ const (
 )
 
 type Friendship struct {
-	ID           uuid.UUID        `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID           uuid.UUID        `json:"id" gorm:"type:char(36);primary_key"`
 	RequesterID  uuid.UUID        `json:"requester_id" gorm:"type:char(36);not null;index"`
 	AddresseeID  uuid.UUID        `json:"addressee_id" gorm:"type:char(36);not null;index"`
-	Status       FriendshipStatus `json:"status" gorm:"type:enum('pending','accepted','blocked');default:'pending'"`
+	Status       FriendshipStatus `json:"status" gorm:"type:varchar(32);default:'pending'"`
 	CreatedAt    time.Time        `json:"created_at"`
 	UpdatedAt    time.Time        `json:"updated_at"`
 
@@ -34,12 +34,26 @@ func (f *Friendship) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// PresenceStatus is the status a user chooses to show their friends.
+type PresenceStatus string
+
+const (
+	PresenceOnline    PresenceStatus = "online"
+	PresenceAway      PresenceStatus = "away"
+	PresenceBusy      PresenceStatus = "busy"
+	PresenceInvisible PresenceStatus = "invisible"
+	// PresenceOffline is never stored; friends see it for disconnected or
+	// invisible users.
+	PresenceOffline PresenceStatus = "offline"
+)
+
 type OnlineUser struct {
-	ID         uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
-	UserID     uuid.UUID `json:"user_id" gorm:"type:char(36);not null;uniqueIndex"`
-	LastSeen   time.Time `json:"last_seen"`
-	IsOnline   bool      `json:"is_online" gorm:"default:true"`
-	SocketID   string    `json:"socket_id" gorm:"index"`
+	ID         uuid.UUID      `json:"id" gorm:"type:char(36);primary_key"`
+	UserID     uuid.UUID      `json:"user_id" gorm:"type:char(36);not null;uniqueIndex"`
+	LastSeen   time.Time      `json:"last_seen"`
+	IsOnline   bool           `json:"is_online" gorm:"default:true"`
+	Status     PresenceStatus `json:"status" gorm:"type:varchar(16);default:'online'"`
+	SocketID   string         `json:"socket_id" gorm:"index"`
 
 	// Relationships
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -50,4 +64,22 @@ func (ou *OnlineUser) BeforeCreate(tx *gorm.DB) error {
 		ou.ID = uuid.New()
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// VisibleStatus is the status friends see, hiding invisible users.
+func (ou *OnlineUser) VisibleStatus() PresenceStatus {
+	if !ou.IsOnline || ou.Status == PresenceInvisible {
+		return PresenceOffline
+	}
+	if ou.Status == "" {
+		return PresenceOnline
+	}
+	return ou.Status
+}
+
+// FriendPresence is a friend together with the status they show.
+type FriendPresence struct {
+	User
+	Status   PresenceStatus `json:"status"`
+	LastSeen time.Time      `json:"last_seen"`
+}
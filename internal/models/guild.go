@@ -7,7 +7,7 @@ import (
 )
 
 type Guild struct {
-	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	Name        string    `json:"name" gorm:"not null;uniqueIndex" validate:"required,min=3,max=50"`
 	Description string    `json:"description" gorm:"type:text"`
 	OwnerID     uuid.UUID `json:"owner_id" gorm:"type:char(36);not null;index"`
@@ -40,10 +40,10 @@ const (
 )
 
 type GuildMember struct {
-	ID       uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID       uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	GuildID  uuid.UUID `json:"guild_id" gorm:"type:char(36);not null;index"`
 	UserID   uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
-	Role     GuildRole `json:"role" gorm:"type:enum('owner','officer','member');default:'member'"`
+	Role     GuildRole `json:"role" gorm:"type:varchar(32);default:'member'"`
 	JoinedAt time.Time `json:"joined_at"`
 
 	// Relationships
@@ -59,11 +59,11 @@ func (gm *GuildMember) BeforeCreate(tx *gorm.DB) error {
 }
 
 type GuildInvitation struct {
-	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	GuildID   uuid.UUID `json:"guild_id" gorm:"type:char(36);not null;index"`
 	InviterID uuid.UUID `json:"inviter_id" gorm:"type:char(36);not null;index"`
 	InviteeID uuid.UUID `json:"invitee_id" gorm:"type:char(36);not null;index"`
-	Status    FriendshipStatus `json:"status" gorm:"type:enum('pending','accepted','blocked');default:'pending'"`
+	Status    FriendshipStatus `json:"status" gorm:"type:varchar(32);default:'pending'"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -16,11 +16,12 @@ const (
 )
 
 type Inventory struct {
-	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID    uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	ItemName  string    `json:"item_name" gorm:"not null" validate:"required"`
 	Quantity  int       `json:"quantity" gorm:"default:1" validate:"min=1"`
-	ItemType  ItemType  `json:"item_type" gorm:"type:enum('tool','code','snippet','resource');not null" validate:"required"`
+	ItemType  ItemType  `json:"item_type" gorm:"type:varchar(32);not null" validate:"required"`
+	Equipped  bool      `json:"equipped" gorm:"default:false"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -0,0 +1,14 @@
+package models
+
+// jsonBytes extracts the raw JSON payload from a column value. MySQL and
+// Postgres hand JSON columns back as []byte while SQLite returns a string.
+func jsonBytes(value interface{}) ([]byte, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	default:
+		return nil, false
+	}
+}
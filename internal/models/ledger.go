@@ -0,0 +1,120 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LedgerAccount identifies one side of a coin transaction. Player wallets
+// use LedgerAccountWallet together with a UserID; every other account is a
+// system account that coins flow into or out of.
+type LedgerAccount string
+
+const (
+	LedgerAccountWallet         LedgerAccount = "wallet"
+	LedgerAccountShop           LedgerAccount = "shop"
+	LedgerAccountQuestRewards   LedgerAccount = "quest_rewards"
+	LedgerAccountHarvest        LedgerAccount = "harvest"
+	LedgerAccountMarketplace    LedgerAccount = "marketplace"
+	LedgerAccountSignupBonus    LedgerAccount = "signup_bonus"
+	LedgerAccountOpeningBalance LedgerAccount = "opening_balance"
+)
+
+type LedgerReason string
+
+const (
+	LedgerReasonPurchase       LedgerReason = "purchase"
+	LedgerReasonSale           LedgerReason = "sale"
+	LedgerReasonQuestReward    LedgerReason = "quest_reward"
+	LedgerReasonHarvest        LedgerReason = "harvest"
+	LedgerReasonMarketplace    LedgerReason = "marketplace"
+	LedgerReasonSignupBonus    LedgerReason = "signup_bonus"
+	LedgerReasonOpeningBalance LedgerReason = "opening_balance"
+)
+
+// Reference types recorded alongside ledger entries.
+const (
+	LedgerRefPurchase      = "purchase"
+	LedgerRefInventoryItem = "inventory_item"
+	LedgerRefQuest         = "quest"
+	LedgerRefCodeFarm      = "code_farm"
+	LedgerRefUser          = "user"
+)
+
+var ErrLedgerImmutable = errors.New("ledger entries are immutable")
+
+// CoinLedgerEntry is one leg of a double-entry coin transaction. All legs of
+// a transaction share a TransactionID and their amounts sum to zero; a
+// player's balance is the sum of their wallet legs.
+type CoinLedgerEntry struct {
+	ID            uuid.UUID     `json:"id" gorm:"type:char(36);primary_key"`
+	TransactionID uuid.UUID     `json:"transaction_id" gorm:"type:char(36);not null;index"`
+	Account       LedgerAccount `json:"account" gorm:"type:varchar(32);not null;index:idx_coin_ledger_account"`
+	UserID        *uuid.UUID    `json:"user_id,omitempty" gorm:"type:char(36);index:idx_coin_ledger_account"`
+	Amount        int           `json:"amount" gorm:"not null"`
+	Reason        LedgerReason  `json:"reason" gorm:"type:varchar(32);not null;index"`
+	ReferenceType string        `json:"reference_type" gorm:"type:varchar(32)"`
+	ReferenceID   *uuid.UUID    `json:"reference_id,omitempty" gorm:"type:char(36);index"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"index"`
+}
+
+func (CoinLedgerEntry) TableName() string {
+	return "coin_ledger"
+}
+
+func (e *CoinLedgerEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+func (e *CoinLedgerEntry) BeforeUpdate(tx *gorm.DB) error {
+	return ErrLedgerImmutable
+}
+
+func (e *CoinLedgerEntry) BeforeDelete(tx *gorm.DB) error {
+	return ErrLedgerImmutable
+}
+
+// NewCoinTransfer builds a balanced transaction that moves amount coins from
+// the counterparty account into a player's wallet. A negative amount moves
+// coins out of the wallet instead.
+func NewCoinTransfer(userID uuid.UUID, amount int, counterparty LedgerAccount, reason LedgerReason, referenceType string, referenceID uuid.UUID) []CoinLedgerEntry {
+	transactionID := uuid.New()
+	now := time.Now()
+	return []CoinLedgerEntry{
+		{
+			TransactionID: transactionID,
+			Account:       LedgerAccountWallet,
+			UserID:        &userID,
+			Amount:        amount,
+			Reason:        reason,
+			ReferenceType: referenceType,
+			ReferenceID:   &referenceID,
+			CreatedAt:     now,
+		},
+		{
+			TransactionID: transactionID,
+			Account:       counterparty,
+			Amount:        -amount,
+			Reason:        reason,
+			ReferenceType: referenceType,
+			ReferenceID:   &referenceID,
+			CreatedAt:     now,
+		},
+	}
+}
+
+// CoinDrift reports a user whose cached User.Coins no longer matches the
+// balance derived from the ledger.
+type CoinDrift struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Username      string    `json:"username"`
+	CachedBalance int       `json:"cached_balance"`
+	LedgerBalance int       `json:"ledger_balance"`
+	Drift         int       `json:"drift"`
+}
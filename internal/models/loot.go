@@ -0,0 +1,175 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidLootTable is returned when a loot table cannot be rolled.
+var ErrInvalidLootTable = errors.New("invalid loot table")
+
+// MaxLootRolls caps how many entries one loot table draws at a time.
+const MaxLootRolls = 10
+
+type LootRarity string
+
+const (
+	RarityCommon    LootRarity = "common"
+	RarityUncommon  LootRarity = "uncommon"
+	RarityRare      LootRarity = "rare"
+	RarityEpic      LootRarity = "epic"
+	RarityLegendary LootRarity = "legendary"
+)
+
+// LootRarities lists the rarity tiers from most to least common.
+var LootRarities = []LootRarity{RarityCommon, RarityUncommon, RarityRare, RarityEpic, RarityLegendary}
+
+// LootConditions limit when an entry can drop. Zero values match
+// everything.
+type LootConditions struct {
+	// Seasons the entry drops in
+	Seasons []string `json:"seasons,omitempty"`
+	// MinLevel and MaxLevel bound the player's level; a MaxLevel of 0 has no
+	// upper bound
+	MinLevel int `json:"min_level,omitempty"`
+	MaxLevel int `json:"max_level,omitempty"`
+	// Tool is the name of the tool the player must have equipped
+	Tool string `json:"tool,omitempty"`
+}
+
+// Matches reports whether the entry can drop for a player of the level,
+// with the tool equipped, in the season.
+func (lc LootConditions) Matches(season string, level int, tool string) bool {
+	if len(lc.Seasons) > 0 {
+		inSeason := false
+		for _, allowed := range lc.Seasons {
+			inSeason = inSeason || allowed == season
+		}
+		if !inSeason {
+			return false
+		}
+	}
+	if level < lc.MinLevel || (lc.MaxLevel > 0 && level > lc.MaxLevel) {
+		return false
+	}
+	return lc.Tool == "" || strings.EqualFold(lc.Tool, tool)
+}
+
+// LootEntry is one of the things a loot table can draw. An entry without an
+// item name draws nothing, which makes the other entries rarer.
+type LootEntry struct {
+	ItemName    string         `json:"item_name"`
+	ItemType    ItemType       `json:"item_type,omitempty"`
+	Weight      int            `json:"weight"`
+	MinQuantity int            `json:"min_quantity"`
+	MaxQuantity int            `json:"max_quantity"`
+	Rarity      LootRarity     `json:"rarity"`
+	Conditions  LootConditions `json:"conditions"`
+}
+
+type LootEntries []LootEntry
+
+func (le LootEntries) Value() (driver.Value, error) {
+	return json.Marshal(le)
+}
+
+func (le *LootEntries) Scan(value interface{}) error {
+	if value == nil {
+		*le = nil
+		return nil
+	}
+	bytes, ok := jsonBytes(value)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, le)
+}
+
+// LootTable is what a world object drops. Objects use the table named by
+// their "loot_table" state, or the one named after their type. Each roll
+// draws Rolls entries among those whose conditions match, each with a
+// chance of its weight over the matching entries' total weight.
+type LootTable struct {
+	ID          uuid.UUID   `json:"id" gorm:"type:char(36);primary_key"`
+	Name        string      `json:"name" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Description string      `json:"description" gorm:"type:text"`
+	Rolls       int         `json:"rolls" gorm:"not null;default:1"`
+	Entries     LootEntries `json:"entries" gorm:"type:json"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+func (lt *LootTable) BeforeCreate(tx *gorm.DB) error {
+	if lt.ID == uuid.Nil {
+		lt.ID = uuid.New()
+	}
+	return nil
+}
+
+// Validate checks that the table can be rolled.
+func (lt LootTable) Validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidLootTable, fmt.Sprintf(format, args...))
+	}
+
+	if lt.Name == "" || len(lt.Name) > 64 {
+		return invalid("name must be 1 to 64 characters")
+	}
+	if lt.Rolls < 1 || lt.Rolls > MaxLootRolls {
+		return invalid("rolls must be between 1 and %d", MaxLootRolls)
+	}
+	for i, entry := range lt.Entries {
+		if entry.Weight < 1 {
+			return invalid("entry %d needs a weight of at least 1", i)
+		}
+		conditions := entry.Conditions
+		for _, season := range conditions.Seasons {
+			if !knownSeason(season) {
+				return invalid("entry %d has unknown season %q", i, season)
+			}
+		}
+		if conditions.MinLevel < 0 || conditions.MaxLevel < 0 ||
+			(conditions.MaxLevel > 0 && conditions.MaxLevel < conditions.MinLevel) {
+			return invalid("entry %d has an empty level range", i)
+		}
+
+		if entry.ItemName == "" {
+			continue
+		}
+		if entry.ItemType == "" {
+			return invalid("entry %q needs an item type", entry.ItemName)
+		}
+		if entry.MinQuantity < 1 || entry.MaxQuantity < entry.MinQuantity {
+			return invalid("entry %q needs a quantity range of at least 1", entry.ItemName)
+		}
+		if !entry.Rarity.valid() {
+			return invalid("entry %q has unknown rarity %q", entry.ItemName, entry.Rarity)
+		}
+	}
+	return nil
+}
+
+func knownSeason(season string) bool {
+	for _, known := range Seasons {
+		if season == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (r LootRarity) valid() bool {
+	for _, rarity := range LootRarities {
+		if r == rarity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidLayout is returned when a map layout does not fit its map.
+var ErrInvalidLayout = errors.New("invalid map layout")
+
+// Walkable mask cells. Rows may use any other character for walkable tiles
+// but the importer and seeders write these.
+const (
+	TileOpen    = '.'
+	TileBlocked = '#'
+)
+
+// DefaultSpawn is the spawn point used when none is named.
+const DefaultSpawn = "default"
+
+type ZoneType string
+
+const (
+	// ZoneFarmland is where code can be planted
+	ZoneFarmland ZoneType = "farmland"
+	ZoneBuilding ZoneType = "building"
+)
+
+// MapLayout describes a map's tiles. Every field is optional: a map without
+// a walkable mask can be walked everywhere, and one without spawn points
+// spawns players in its centre.
+type MapLayout struct {
+	// Layers are drawn bottom to top by the client
+	Layers []TileLayer `json:"layers,omitempty"`
+	// Walkable has one row per y, one character per x; TileBlocked marks a
+	// tile no one can stand on
+	Walkable    []string               `json:"walkable,omitempty"`
+	SpawnPoints []SpawnPoint           `json:"spawn_points,omitempty"`
+	Zones       []MapZone              `json:"zones,omitempty"`
+	Portals     []Portal               `json:"portals,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+}
+
+// TileLayer holds a tile ID for every tile of the map in row-major order;
+// 0 leaves the tile empty.
+type TileLayer struct {
+	Name  string `json:"name"`
+	Tiles []int  `json:"tiles"`
+}
+
+type SpawnPoint struct {
+	Name string `json:"name"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+// MapZone is a named rectangle of tiles with a gameplay meaning.
+type MapZone struct {
+	Name       string                 `json:"name"`
+	Type       ZoneType               `json:"type"`
+	X          int                    `json:"x"`
+	Y          int                    `json:"y"`
+	Width      int                    `json:"width"`
+	Height     int                    `json:"height"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// Contains reports whether the tile is inside the zone.
+func (z MapZone) Contains(x, y int) bool {
+	return x >= z.X && x < z.X+z.Width && y >= z.Y && y < z.Y+z.Height
+}
+
+// Portal takes players who walk onto any of its tiles to a spawn point on
+// another map, if they meet its requirements.
+type Portal struct {
+	Name   string `json:"name"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// TargetMap is the name of the map the portal leads to
+	TargetMap string `json:"target_map"`
+	// TargetSpawn is the spawn point players arrive at, the target map's
+	// default spawn when empty
+	TargetSpawn  string             `json:"target_spawn,omitempty"`
+	Requirements PortalRequirements `json:"requirements"`
+}
+
+// PortalRequirements restricts who can use a portal and when. The zero
+// value lets everyone through at any time.
+type PortalRequirements struct {
+	MinLevel int `json:"min_level,omitempty"`
+	// QuestID is a quest the player must have completed
+	QuestID *uuid.UUID `json:"quest_id,omitempty"`
+	// OpenFrom and OpenUntil are game hours; the portal is open from the
+	// start of OpenFrom until the start of OpenUntil, wrapping past
+	// midnight when OpenUntil is earlier. Equal hours mean always open.
+	OpenFrom  int `json:"open_from,omitempty"`
+	OpenUntil int `json:"open_until,omitempty"`
+}
+
+// Contains reports whether the tile is inside the portal.
+func (p Portal) Contains(x, y int) bool {
+	return x >= p.X && x < p.X+p.Width && y >= p.Y && y < p.Y+p.Height
+}
+
+// OpenAt reports whether the portal is open at a game hour.
+func (r PortalRequirements) OpenAt(hour int) bool {
+	switch {
+	case r.OpenFrom == r.OpenUntil:
+		return true
+	case r.OpenFrom < r.OpenUntil:
+		return hour >= r.OpenFrom && hour < r.OpenUntil
+	default:
+		return hour >= r.OpenFrom || hour < r.OpenUntil
+	}
+}
+
+func (ml MapLayout) Value() (driver.Value, error) {
+	return json.Marshal(ml)
+}
+
+func (ml *MapLayout) Scan(value interface{}) error {
+	if value == nil {
+		*ml = MapLayout{}
+		return nil
+	}
+	bytes, ok := jsonBytes(value)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, ml)
+}
+
+// Validate checks that the layout fits a map of the given size.
+func (ml MapLayout) Validate(width, height int) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidLayout, fmt.Sprintf(format, args...))
+	}
+
+	names := make(map[string]bool)
+	for i, layer := range ml.Layers {
+		if layer.Name == "" || names[layer.Name] {
+			return invalid("layer %d needs a unique name", i)
+		}
+		names[layer.Name] = true
+		if len(layer.Tiles) != width*height {
+			return invalid("layer %q has %d tiles, expected %d", layer.Name, len(layer.Tiles), width*height)
+		}
+	}
+
+	if ml.Walkable != nil {
+		if len(ml.Walkable) != height {
+			return invalid("walkable mask has %d rows, expected %d", len(ml.Walkable), height)
+		}
+		for y, row := range ml.Walkable {
+			if len(row) != width {
+				return invalid("walkable row %d has %d columns, expected %d", y, len(row), width)
+			}
+		}
+	}
+
+	names = make(map[string]bool)
+	for i, spawn := range ml.SpawnPoints {
+		if spawn.Name == "" || names[spawn.Name] {
+			return invalid("spawn point %d needs a unique name", i)
+		}
+		names[spawn.Name] = true
+		if spawn.X < 0 || spawn.X >= width || spawn.Y < 0 || spawn.Y >= height {
+			return invalid("spawn point %q is off the map", spawn.Name)
+		}
+		if !ml.walkable(spawn.X, spawn.Y) {
+			return invalid("spawn point %q is on a blocked tile", spawn.Name)
+		}
+	}
+
+	for i, zone := range ml.Zones {
+		if zone.Name == "" || zone.Type == "" {
+			return invalid("zone %d needs a name and a type", i)
+		}
+		if zone.Width < 1 || zone.Height < 1 || zone.X < 0 || zone.Y < 0 ||
+			zone.X+zone.Width > width || zone.Y+zone.Height > height {
+			return invalid("zone %q does not fit on the map", zone.Name)
+		}
+	}
+
+	names = make(map[string]bool)
+	for i, portal := range ml.Portals {
+		if portal.Name == "" || names[portal.Name] {
+			return invalid("portal %d needs a unique name", i)
+		}
+		names[portal.Name] = true
+		if portal.TargetMap == "" {
+			return invalid("portal %q needs a target map", portal.Name)
+		}
+		if portal.Width < 1 || portal.Height < 1 || portal.X < 0 || portal.Y < 0 ||
+			portal.X+portal.Width > width || portal.Y+portal.Height > height {
+			return invalid("portal %q does not fit on the map", portal.Name)
+		}
+		requirements := portal.Requirements
+		if requirements.MinLevel < 0 ||
+			requirements.OpenFrom < 0 || requirements.OpenFrom > 23 ||
+			requirements.OpenUntil < 0 || requirements.OpenUntil > 23 {
+			return invalid("portal %q has invalid requirements", portal.Name)
+		}
+	}
+
+	return nil
+}
+
+// walkable reads the mask, assuming the tile is on the map.
+func (ml MapLayout) walkable(x, y int) bool {
+	if ml.Walkable == nil {
+		return true
+	}
+	return ml.Walkable[y][x] != TileBlocked
+}
+
+// Spawn finds a spawn point by name, DefaultSpawn when name is empty. The
+// default falls back to the first spawn point.
+func (ml MapLayout) Spawn(name string) (SpawnPoint, bool) {
+	if name == "" {
+		name = DefaultSpawn
+	}
+	for _, spawn := range ml.SpawnPoints {
+		if spawn.Name == name {
+			return spawn, true
+		}
+	}
+	if name == DefaultSpawn && len(ml.SpawnPoints) > 0 {
+		return ml.SpawnPoints[0], true
+	}
+	return SpawnPoint{}, false
+}
+
+// ZonesOf lists the zones of a type.
+func (ml MapLayout) ZonesOf(zoneType ZoneType) []MapZone {
+	var zones []MapZone
+	for _, zone := range ml.Zones {
+		if zone.Type == zoneType {
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
+
+// PortalAt finds the portal covering a tile.
+func (ml MapLayout) PortalAt(x, y int) (Portal, bool) {
+	for _, portal := range ml.Portals {
+		if portal.Contains(x, y) {
+			return portal, true
+		}
+	}
+	return Portal{}, false
+}
@@ -15,14 +15,14 @@ const (
 )
 
 type MarketplaceListing struct {
-	ID          uuid.UUID             `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID             `json:"id" gorm:"type:char(36);primary_key"`
 	SellerID    uuid.UUID             `json:"seller_id" gorm:"type:char(36);not null;index"`
 	ItemName    string                `json:"item_name" gorm:"not null;index" validate:"required"`
 	Description string                `json:"description" gorm:"type:text"`
 	Price       int                   `json:"price" gorm:"not null" validate:"min=1"`
 	Quantity    int                   `json:"quantity" gorm:"default:1" validate:"min=1"`
-	ItemType    ItemType              `json:"item_type" gorm:"type:enum('tool','code','snippet','resource');not null"`
-	Status      MarketplaceItemStatus `json:"status" gorm:"type:enum('active','sold','expired');default:'active'"`
+	ItemType    ItemType              `json:"item_type" gorm:"type:varchar(32);not null"`
+	Status      MarketplaceItemStatus `json:"status" gorm:"type:varchar(32);default:'active'"`
 	ExpiresAt   time.Time             `json:"expires_at"`
 	CreatedAt   time.Time             `json:"created_at"`
 	UpdatedAt   time.Time             `json:"updated_at"`
@@ -39,7 +39,7 @@ func (ml *MarketplaceListing) BeforeCreate(tx *gorm.DB) error {
 }
 
 type MarketplaceTransaction struct {
-	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	ListingID uuid.UUID `json:"listing_id" gorm:"type:char(36);not null;index"`
 	BuyerID   uuid.UUID `json:"buyer_id" gorm:"type:char(36);not null;index"`
 	SellerID  uuid.UUID `json:"seller_id" gorm:"type:char(36);not null;index"`
@@ -28,7 +28,7 @@ func (gc *GameConfig) Scan(value interface{}) error {
 		*gc = make(GameConfig)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -36,11 +36,11 @@ func (gc *GameConfig) Scan(value interface{}) error {
 }
 
 type MiniGame struct {
-	ID          uuid.UUID    `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID    `json:"id" gorm:"type:char(36);primary_key"`
 	Name        string       `json:"name" gorm:"not null;index" validate:"required"`
 	Description string       `json:"description" gorm:"type:text"`
-	Type        MiniGameType `json:"type" gorm:"type:enum('quiz','puzzle','regex','algorithm');not null"`
-	Difficulty  BattleDifficulty `json:"difficulty" gorm:"type:enum('easy','medium','hard');not null"`
+	Type        MiniGameType `json:"type" gorm:"type:varchar(32);not null"`
+	Difficulty  BattleDifficulty `json:"difficulty" gorm:"type:varchar(32);not null"`
 	Config      GameConfig   `json:"config" gorm:"type:json"`
 	RewardCoins int          `json:"reward_coins" gorm:"default:0"`
 	RewardEXP   int          `json:"reward_exp" gorm:"default:0"`
@@ -70,10 +70,10 @@ const (
 )
 
 type MiniGameSession struct {
-	ID         uuid.UUID         `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID         uuid.UUID         `json:"id" gorm:"type:char(36);primary_key"`
 	UserID     uuid.UUID         `json:"user_id" gorm:"type:char(36);not null;index"`
 	MiniGameID uuid.UUID         `json:"mini_game_id" gorm:"type:char(36);not null;index"`
-	Status     GameSessionStatus `json:"status" gorm:"type:enum('active','completed','failed','timeout');default:'active'"`
+	Status     GameSessionStatus `json:"status" gorm:"type:varchar(32);default:'active'"`
 	Score      int               `json:"score" gorm:"default:0"`
 	StartedAt  time.Time         `json:"started_at"`
 	CompletedAt *time.Time       `json:"completed_at"`
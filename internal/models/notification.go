@@ -20,9 +20,9 @@ const (
 )
 
 type Notification struct {
-	ID       uuid.UUID        `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID       uuid.UUID        `json:"id" gorm:"type:char(36);primary_key"`
 	UserID   uuid.UUID        `json:"user_id" gorm:"type:char(36);not null;index"`
-	Type     NotificationType `json:"type" gorm:"type:enum('quest','friend','achievement','system','npc','event');not null"`
+	Type     NotificationType `json:"type" gorm:"type:varchar(32);not null"`
 	Title    string           `json:"title" gorm:"not null" validate:"required"`
 	Message  string           `json:"message" gorm:"type:text" validate:"required"`
 	IsRead   bool             `json:"is_read" gorm:"default:false"`
@@ -44,7 +44,7 @@ func (nd *NotificationData) Scan(value interface{}) error {
 		*nd = make(NotificationData)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -27,7 +27,7 @@ func (qi *QuestIDs) Scan(value interface{}) error {
 		*qi = []uuid.UUID{}
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -35,9 +35,9 @@ func (qi *QuestIDs) Scan(value interface{}) error {
 }
 
 type NPC struct {
-	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	Name        string    `json:"name" gorm:"not null" validate:"required"`
-	Role        NPCRole   `json:"role" gorm:"type:enum('mentor','client','villager');not null" validate:"required"`
+	Role        NPCRole   `json:"role" gorm:"type:varchar(32);not null" validate:"required"`
 	Dialogue    string    `json:"dialogue" gorm:"type:text"`
 	Location    string    `json:"location" gorm:"not null" validate:"required"`
 	AvatarURL   string    `json:"avatar_url"`
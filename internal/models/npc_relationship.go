@@ -9,7 +9,7 @@ import (
 )
 
 type NPCRelationship struct {
-	ID             uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID             uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID         uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	NPCID          uuid.UUID `json:"npc_id" gorm:"type:char(36);not null;index"`
 	FriendshipLevel int      `json:"friendship_level" gorm:"default:0"`
@@ -30,7 +30,7 @@ func (nr *NPCRelationship) BeforeCreate(tx *gorm.DB) error {
 }
 
 type NPCInteraction struct {
-	ID           uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID           uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID       uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	NPCID        uuid.UUID `json:"npc_id" gorm:"type:char(36);not null;index"`
 	InteractionType string `json:"interaction_type" gorm:"not null"` // talk, gift, quest
@@ -53,7 +53,7 @@ func (id *InteractionData) Scan(value interface{}) error {
 		*id = make(InteractionData)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
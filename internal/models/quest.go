@@ -19,7 +19,7 @@ func (ri *RequiredItems) Scan(value interface{}) error {
 		*ri = make(RequiredItems)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -27,7 +27,7 @@ func (ri *RequiredItems) Scan(value interface{}) error {
 }
 
 type Quest struct {
-	ID            uuid.UUID     `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID            uuid.UUID     `json:"id" gorm:"type:char(36);primary_key"`
 	Title         string        `json:"title" gorm:"not null" validate:"required"`
 	Description   string        `json:"description" gorm:"type:text" validate:"required"`
 	RewardCoins   int           `json:"reward_coins" gorm:"default:0"`
@@ -68,7 +68,7 @@ func (pd *ProgressData) Scan(value interface{}) error {
 		*pd = make(ProgressData)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -76,10 +76,10 @@ func (pd *ProgressData) Scan(value interface{}) error {
 }
 
 type UserQuestProgress struct {
-	ID           uuid.UUID     `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID           uuid.UUID     `json:"id" gorm:"type:char(36);primary_key"`
 	UserID       uuid.UUID     `json:"user_id" gorm:"type:char(36);not null;index"`
 	QuestID      uuid.UUID     `json:"quest_id" gorm:"type:char(36);not null;index"`
-	Status       QuestStatus   `json:"status" gorm:"type:enum('not_started','in_progress','completed');default:'not_started'"`
+	Status       QuestStatus   `json:"status" gorm:"type:varchar(32);default:'not_started'"`
 	ProgressData ProgressData  `json:"progress_data" gorm:"type:json"`
 	StartedAt    *time.Time    `json:"started_at"`
 	CompletedAt  *time.Time    `json:"completed_at"`
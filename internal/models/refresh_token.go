@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken is an opaque, single-use token that can be exchanged for a new
+// access token. Only the SHA-256 hash of the token is stored. Every login
+// starts a new family; each rotation adds a token to the same family, and the
+// family ID doubles as the session ID carried in access tokens.
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:char(36);primary_key"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
+	FamilyID  uuid.UUID  `json:"family_id" gorm:"type:char(36);not null;index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (rt *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if rt.ID == uuid.Nil {
+		rt.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsUsable reports whether the token can still be exchanged.
+func (rt *RefreshToken) IsUsable(now time.Time) bool {
+	return rt.UsedAt == nil && rt.RevokedAt == nil && now.Before(rt.ExpiresAt)
+}
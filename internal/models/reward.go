@@ -7,7 +7,7 @@ import (
 )
 
 type DailyReward struct {
-	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	Day         int       `json:"day" gorm:"not null;index"` // Day of the month or streak day
 	RewardCoins int       `json:"reward_coins" gorm:"default:0"`
 	RewardEXP   int       `json:"reward_exp" gorm:"default:0"`
@@ -25,7 +25,7 @@ func (dr *DailyReward) BeforeCreate(tx *gorm.DB) error {
 }
 
 type UserDailyReward struct {
-	ID           uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID           uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID       uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	DailyRewardID uuid.UUID `json:"daily_reward_id" gorm:"type:char(36);not null;index"`
 	ClaimedAt    time.Time `json:"claimed_at"`
@@ -44,7 +44,7 @@ func (udr *UserDailyReward) BeforeCreate(tx *gorm.DB) error {
 }
 
 type LoginStreak struct {
-	ID           uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID           uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID       uuid.UUID `json:"user_id" gorm:"type:char(36);not null;uniqueIndex"`
 	CurrentStreak int      `json:"current_streak" gorm:"default:0"`
 	LongestStreak int      `json:"longest_streak" gorm:"default:0"`
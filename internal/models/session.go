@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one signed-in device. Its ID is shared with the refresh token
+// family and carried as the sid claim of access tokens.
+type Session struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:char(36);primary_key"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
+	DeviceName string     `json:"device_name" gorm:"type:varchar(100)"`
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(45)"`
+	UserAgent  string     `json:"user_agent" gorm:"type:varchar(255)"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Current marks the session the request was made with
+	Current bool `json:"current" gorm:"-"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// IsActive reports whether the session can still authenticate requests.
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
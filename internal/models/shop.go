@@ -17,11 +17,11 @@ const (
 )
 
 type ShopItem struct {
-	ID          uuid.UUID    `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID    `json:"id" gorm:"type:char(36);primary_key"`
 	Name        string       `json:"name" gorm:"not null;index" validate:"required"`
 	Description string       `json:"description" gorm:"type:text"`
 	Price       int          `json:"price" gorm:"not null" validate:"min=0"`
-	ItemType    ShopItemType `json:"item_type" gorm:"type:enum('tool','upgrade','cosmetic','resource','skill');not null"`
+	ItemType    ShopItemType `json:"item_type" gorm:"type:varchar(32);not null"`
 	IconURL     string       `json:"icon_url"`
 	IsAvailable bool         `json:"is_available" gorm:"default:true"`
 	Stock       int          `json:"stock" gorm:"default:-1"` // -1 means unlimited
@@ -37,7 +37,7 @@ func (si *ShopItem) BeforeCreate(tx *gorm.DB) error {
 }
 
 type UserPurchase struct {
-	ID         uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID         uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID     uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	ShopItemID uuid.UUID `json:"shop_item_id" gorm:"type:char(36);not null;index"`
 	Quantity   int       `json:"quantity" gorm:"default:1"`
@@ -29,7 +29,7 @@ func (se *SkillEffects) Scan(value interface{}) error {
 		*se = make(SkillEffects)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -37,10 +37,10 @@ func (se *SkillEffects) Scan(value interface{}) error {
 }
 
 type Skill struct {
-	ID           uuid.UUID     `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID           uuid.UUID     `json:"id" gorm:"type:char(36);primary_key"`
 	Name         string        `json:"name" gorm:"not null;index" validate:"required"`
 	Description  string        `json:"description" gorm:"type:text"`
-	Category     SkillCategory `json:"category" gorm:"type:enum('programming','debugging','optimization','social','economic');not null"`
+	Category     SkillCategory `json:"category" gorm:"type:varchar(32);not null"`
 	MaxLevel     int           `json:"max_level" gorm:"default:10"`
 	BaseCost     int           `json:"base_cost" gorm:"default:100"`
 	Effects      SkillEffects  `json:"effects" gorm:"type:json"`
@@ -62,7 +62,7 @@ func (s *Skill) BeforeCreate(tx *gorm.DB) error {
 }
 
 type UserSkill struct {
-	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID    uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	SkillID   uuid.UUID `json:"skill_id" gorm:"type:char(36);not null;index"`
 	Level     int       `json:"level" gorm:"default:1"`
@@ -7,7 +7,7 @@ import (
 )
 
 type UserStatistics struct {
-	ID                uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID                uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID            uuid.UUID `json:"user_id" gorm:"type:char(36);not null;uniqueIndex"`
 	TotalPlayTime     int       `json:"total_play_time" gorm:"default:0"` // minutes
 	QuestsCompleted   int       `json:"quests_completed" gorm:"default:0"`
@@ -36,7 +36,7 @@ func (us *UserStatistics) BeforeCreate(tx *gorm.DB) error {
 }
 
 type DailyStatistics struct {
-	ID              uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID              uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID          uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	Date            time.Time `json:"date" gorm:"type:date;not null;index"`
 	PlayTime        int       `json:"play_time" gorm:"default:0"` // minutes
@@ -54,4 +54,47 @@ func (ds *DailyStatistics) BeforeCreate(tx *gorm.DB) error {
 		ds.ID = uuid.New()
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// StatsMetric names a statistic that can be broken down by day.
+type StatsMetric string
+
+const (
+	StatsMetricNewUsers         StatsMetric = "new_users"
+	StatsMetricActiveUsers      StatsMetric = "active_users"
+	StatsMetricQuestCompletions StatsMetric = "quest_completions"
+	StatsMetricNewGuilds        StatsMetric = "new_guilds"
+	StatsMetricCoinsEarned      StatsMetric = "coins_earned"
+	StatsMetricCoinsSpent       StatsMetric = "coins_spent"
+)
+
+var StatsMetrics = []StatsMetric{
+	StatsMetricNewUsers,
+	StatsMetricActiveUsers,
+	StatsMetricQuestCompletions,
+	StatsMetricNewGuilds,
+	StatsMetricCoinsEarned,
+	StatsMetricCoinsSpent,
+}
+
+// DailyCount is one day of a stats time series. Day is formatted YYYY-MM-DD
+// in UTC.
+type DailyCount struct {
+	Day   string `json:"date"`
+	Value int64  `json:"value"`
+}
+
+// EconomyTotals summarises the coin economy. Earned and spent are all-time
+// wallet inflows and outflows from the coin ledger.
+type EconomyTotals struct {
+	CoinsInCirculation int64 `json:"coins_in_circulation"`
+	CoinsEarned        int64 `json:"coins_earned"`
+	CoinsSpent         int64 `json:"coins_spent"`
+	Transactions       int64 `json:"transactions"`
+}
+
+// ActivityDay truncates t to the start of its UTC day, the granularity of
+// DailyStatistics.
+func ActivityDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type StoryProgress struct {
-	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID  `json:"id" gorm:"type:char(36);primary_key"`
 	UserID      uuid.UUID  `json:"user_id" gorm:"type:char(36);not null;index"`
 	Chapter     int        `json:"chapter" gorm:"not null"`
 	Milestone   string     `json:"milestone" gorm:"not null" validate:"required"`
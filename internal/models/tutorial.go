@@ -29,7 +29,7 @@ func (tc *TutorialContent) Scan(value interface{}) error {
 		*tc = make(TutorialContent)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -37,10 +37,10 @@ func (tc *TutorialContent) Scan(value interface{}) error {
 }
 
 type Tutorial struct {
-	ID           uuid.UUID        `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID           uuid.UUID        `json:"id" gorm:"type:char(36);primary_key"`
 	Title        string           `json:"title" gorm:"not null;index" validate:"required"`
 	Description  string           `json:"description" gorm:"type:text"`
-	Category     TutorialCategory `json:"category" gorm:"type:enum('basics','advanced','framework','algorithm','best_practice');not null"`
+	Category     TutorialCategory `json:"category" gorm:"type:varchar(32);not null"`
 	Content      TutorialContent  `json:"content" gorm:"type:json"`
 	Prerequisites []uuid.UUID     `json:"prerequisites" gorm:"type:json"`
 	RequiredLevel int             `json:"required_level" gorm:"default:1"`
@@ -70,10 +70,10 @@ const (
 )
 
 type UserTutorialProgress struct {
-	ID         uuid.UUID      `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID         uuid.UUID      `json:"id" gorm:"type:char(36);primary_key"`
 	UserID     uuid.UUID      `json:"user_id" gorm:"type:char(36);not null;index"`
 	TutorialID uuid.UUID      `json:"tutorial_id" gorm:"type:char(36);not null;index"`
-	Status     TutorialStatus `json:"status" gorm:"type:enum('not_started','in_progress','completed');default:'not_started'"`
+	Status     TutorialStatus `json:"status" gorm:"type:varchar(32);default:'not_started'"`
 	Progress   int            `json:"progress" gorm:"default:0"` // percentage
 	StartedAt  *time.Time     `json:"started_at"`
 	CompletedAt *time.Time    `json:"completed_at"`
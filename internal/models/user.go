@@ -14,7 +14,7 @@ const (
 )
 
 type User struct {
-	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	Email       string    `json:"email" gorm:"uniqueIndex;not null" validate:"required,email"`
 	Username    string    `json:"username" gorm:"uniqueIndex;not null" validate:"required,min=3,max=30"`
 	PasswordHash string   `json:"-" gorm:"not null"`
@@ -23,7 +23,7 @@ type User struct {
 	EXP         int       `json:"exp" gorm:"default:0"`
 	Level       int       `json:"level" gorm:"default:1"`
 	Coins       int       `json:"coins" gorm:"default:100"`
-	Role        UserRole  `json:"role" gorm:"type:enum('player','admin');default:'player'"`
+	Role        UserRole  `json:"role" gorm:"type:varchar(32);default:'player'"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
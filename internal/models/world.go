@@ -21,9 +21,9 @@ const (
 )
 
 type Map struct {
-	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	Name        string    `json:"name" gorm:"not null;uniqueIndex" validate:"required"`
-	Type        MapType   `json:"type" gorm:"type:enum('village','code_mine','data_farm','office','library','lab');not null"`
+	Type        MapType   `json:"type" gorm:"type:varchar(32);not null"`
 	Width       int       `json:"width" gorm:"not null" validate:"min=1"`
 	Height      int       `json:"height" gorm:"not null" validate:"min=1"`
 	Layout      MapLayout `json:"layout" gorm:"type:json"`
@@ -38,24 +38,6 @@ type Map struct {
 	NPCPositions    []NPCPosition    `json:"npc_positions,omitempty" gorm:"foreignKey:MapID"`
 }
 
-type MapLayout map[string]interface{}
-
-func (ml MapLayout) Value() (driver.Value, error) {
-	return json.Marshal(ml)
-}
-
-func (ml *MapLayout) Scan(value interface{}) error {
-	if value == nil {
-		*ml = make(MapLayout)
-		return nil
-	}
-	bytes, ok := value.([]byte)
-	if !ok {
-		return nil
-	}
-	return json.Unmarshal(bytes, ml)
-}
-
 func (m *Map) BeforeCreate(tx *gorm.DB) error {
 	if m.ID == uuid.Nil {
 		m.ID = uuid.New()
@@ -63,8 +45,13 @@ func (m *Map) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeSave keeps layouts that do not fit the map out of the database.
+func (m *Map) BeforeSave(tx *gorm.DB) error {
+	return m.Layout.Validate(m.Width, m.Height)
+}
+
 type PlayerPosition struct {
-	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID    uuid.UUID `json:"user_id" gorm:"type:char(36);not null;uniqueIndex"`
 	MapID     uuid.UUID `json:"map_id" gorm:"type:char(36);not null;index"`
 	PosX      int       `json:"pos_x" gorm:"not null"`
@@ -97,6 +84,17 @@ const (
 	ObjectTypeBugHive   ObjectType = "bug_hive"
 )
 
+// ObjectTypes lists every world object type.
+var ObjectTypes = []ObjectType{
+	ObjectTypeTree,
+	ObjectTypeRock,
+	ObjectTypeChest,
+	ObjectTypeServer,
+	ObjectTypeWorkstation,
+	ObjectTypeCodeBlock,
+	ObjectTypeBugHive,
+}
+
 type ObjectState map[string]interface{}
 
 func (os ObjectState) Value() (driver.Value, error) {
@@ -108,7 +106,7 @@ func (os *ObjectState) Scan(value interface{}) error {
 		*os = make(ObjectState)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -116,9 +114,9 @@ func (os *ObjectState) Scan(value interface{}) error {
 }
 
 type WorldObject struct {
-	ID         uuid.UUID   `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID         uuid.UUID   `json:"id" gorm:"type:char(36);primary_key"`
 	MapID      uuid.UUID   `json:"map_id" gorm:"type:char(36);not null;index"`
-	ObjectType ObjectType  `json:"object_type" gorm:"type:enum('tree','rock','chest','server','workstation','code_block','bug_hive');not null"`
+	ObjectType ObjectType  `json:"object_type" gorm:"type:varchar(32);not null"`
 	PosX       int         `json:"pos_x" gorm:"not null"`
 	PosY       int         `json:"pos_y" gorm:"not null"`
 	State      ObjectState `json:"state" gorm:"type:json"`
@@ -138,7 +136,7 @@ func (wo *WorldObject) BeforeCreate(tx *gorm.DB) error {
 }
 
 type NPCPosition struct {
-	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	NPCID     uuid.UUID `json:"npc_id" gorm:"type:char(36);not null;index"`
 	MapID     uuid.UUID `json:"map_id" gorm:"type:char(36);not null;index"`
 	PosX      int       `json:"pos_x" gorm:"not null"`
@@ -159,7 +157,7 @@ func (np *NPCPosition) BeforeCreate(tx *gorm.DB) error {
 }
 
 type NPCSchedule struct {
-	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID        uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	NPCID     uuid.UUID `json:"npc_id" gorm:"type:char(36);not null;index"`
 	DayOfWeek int       `json:"day_of_week" gorm:"not null"` // 0-6 (Sunday-Saturday)
 	TimeOfDay int       `json:"time_of_day" gorm:"not null"` // 0-2359 (24-hour format)
@@ -182,7 +180,7 @@ func (ns *NPCSchedule) BeforeCreate(tx *gorm.DB) error {
 }
 
 type GameClock struct {
-	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	GameYear    int       `json:"game_year" gorm:"default:1"`
 	GameSeason  string    `json:"game_season" gorm:"default:'spring'"` // spring, summer, fall, winter
 	GameDay     int       `json:"game_day" gorm:"default:1"`
@@ -200,8 +198,25 @@ func (gc *GameClock) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// Seasons in the order they pass; a year is one of each.
+var Seasons = []string{"spring", "summer", "fall", "winter"}
+
+const DaysPerSeason = 28
+
+// DayNumber counts game days since the clock started, the first day of
+// year 1 being day 1.
+func (gc *GameClock) DayNumber() int {
+	season := 0
+	for i, name := range Seasons {
+		if name == gc.GameSeason {
+			season = i
+		}
+	}
+	return ((gc.GameYear-1)*len(Seasons)+season)*DaysPerSeason + gc.GameDay
+}
+
 type CodeFarm struct {
-	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key;default:(UUID())"`
+	ID          uuid.UUID `json:"id" gorm:"type:char(36);primary_key"`
 	UserID      uuid.UUID `json:"user_id" gorm:"type:char(36);not null;index"`
 	PlotX       int       `json:"plot_x" gorm:"not null"`
 	PlotY       int       `json:"plot_y" gorm:"not null"`
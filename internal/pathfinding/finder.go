@@ -0,0 +1,92 @@
+package pathfinding
+
+import (
+	"fmt"
+	"sync"
+)
+
+// maxCachedGoals bounds how many destinations a Finder remembers paths to
+// before it starts over.
+const maxCachedGoals = 256
+
+// Finder searches one grid and remembers what it found: every tile on a
+// found path knows its next step towards the same goals, so following a
+// path, or joining it part-way, needs no new search. The grid must not
+// change while a Finder uses it; build a new Finder instead.
+type Finder struct {
+	grid Grid
+	opts Options
+
+	mu sync.Mutex
+	// next maps a goal key to each known tile's next step towards it
+	next map[string]map[Point]Point
+}
+
+func NewFinder(grid Grid, opts Options) *Finder {
+	return &Finder{grid: grid, opts: opts, next: make(map[string]map[Point]Point)}
+}
+
+// Find is Find on the Finder's grid, using remembered paths.
+func (f *Finder) Find(from, to Point) ([]Point, bool) {
+	return f.FindAny(from, []Point{to})
+}
+
+// FindAny is FindAny on the Finder's grid, using remembered paths.
+func (f *Finder) FindAny(from Point, goals []Point) ([]Point, bool) {
+	key := fmt.Sprint(goals)
+	if path, ok := f.remembered(key, from, goals); ok {
+		return path, true
+	}
+
+	path, ok := FindAny(f.grid, from, goals, f.opts)
+	if ok && len(path) > 0 {
+		f.remember(key, from, path)
+	}
+	return path, ok
+}
+
+func (f *Finder) remembered(key string, from Point, goals []Point) ([]Point, bool) {
+	isGoal := make(map[Point]bool, len(goals))
+	for _, goal := range goals {
+		isGoal[goal] = true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	next := f.next[key]
+	if next == nil {
+		return nil, false
+	}
+	var path []Point
+	for at := from; !isGoal[at]; {
+		step, ok := next[at]
+		// Paths found with a heuristic that overestimates may not agree,
+		// so a walk longer than the known tiles is going in circles
+		if !ok || len(path) > len(next) {
+			return nil, false
+		}
+		path = append(path, step)
+		at = step
+	}
+	return path, true
+}
+
+func (f *Finder) remember(key string, from Point, path []Point) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	next := f.next[key]
+	if next == nil {
+		if len(f.next) >= maxCachedGoals {
+			f.next = make(map[string]map[Point]Point)
+		}
+		next = make(map[Point]Point)
+		f.next[key] = next
+	}
+	at := from
+	for _, step := range path {
+		next[at] = step
+		at = step
+	}
+}
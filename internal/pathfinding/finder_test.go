@@ -0,0 +1,77 @@
+package pathfinding
+
+import (
+	"reflect"
+	"testing"
+)
+
+// countingGrid counts the tiles looked at, so tests can tell whether a
+// Finder searched or used a remembered path.
+type countingGrid struct {
+	Grid
+	looks int
+}
+
+func (g *countingGrid) Walkable(x, y int) bool {
+	g.looks++
+	return g.Grid.Walkable(x, y)
+}
+
+func TestFinderRemembersPaths(t *testing.T) {
+	grid := &countingGrid{Grid: testGrid{
+		"......",
+		"..##..",
+		"......",
+	}}
+	finder := NewFinder(grid, Options{})
+	from, to := Point{0, 1}, Point{5, 1}
+
+	path, ok := finder.Find(from, to)
+	if !ok {
+		t.Fatal("no path")
+	}
+	checkPath(t, grid.Grid, from, to, path, false)
+	if want, _ := Find(grid.Grid, from, to, Options{}); !reflect.DeepEqual(path, want) {
+		t.Fatalf("Finder path %v, want the same as Find: %v", path, want)
+	}
+
+	tests := []struct {
+		name string
+		from Point
+		want []Point
+	}{
+		{"the same route", from, path},
+		{"joining part-way", path[1], path[2:]},
+		{"from the goal", to, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid.looks = 0
+			got, ok := finder.Find(tt.from, to)
+			if !ok || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Find = %v, %v, want %v", got, ok, tt.want)
+			}
+			if grid.looks != 0 {
+				t.Errorf("searched %d tiles, want the remembered path", grid.looks)
+			}
+		})
+	}
+
+	grid.looks = 0
+	if _, ok := finder.Find(Point{0, 2}, to); !ok || grid.looks == 0 {
+		t.Errorf("Find from a tile off the path = %v after %d looks, want a new search", ok, grid.looks)
+	}
+}
+
+func TestFinderDoesNotRememberFailures(t *testing.T) {
+	finder := NewFinder(testGrid{
+		".#.",
+		".#.",
+	}, Options{})
+
+	for i := 0; i < 2; i++ {
+		if path, ok := finder.Find(Point{0, 0}, Point{2, 0}); ok {
+			t.Fatalf("attempt %d: Find = %v, want no path", i, path)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package pathfinding
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Heuristic estimates the cost of the cheapest path between two tiles.
+type Heuristic func(from, to Point) float64
+
+func distances(from, to Point) (float64, float64) {
+	return math.Abs(float64(to.X - from.X)), math.Abs(float64(to.Y - from.Y))
+}
+
+// Manhattan suits paths without diagonal steps.
+func Manhattan(from, to Point) float64 {
+	dx, dy := distances(from, to)
+	return dx + dy
+}
+
+// Chebyshev suits diagonal steps costing the same as straight ones.
+func Chebyshev(from, to Point) float64 {
+	dx, dy := distances(from, to)
+	return math.Max(dx, dy)
+}
+
+// Octile suits diagonal steps costing √2.
+func Octile(from, to Point) float64 {
+	dx, dy := distances(from, to)
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+// Euclidean is the straight-line distance. It overestimates when diagonal
+// steps cost less than √2, and guides the search less than Octile when
+// they cost exactly that.
+func Euclidean(from, to Point) float64 {
+	dx, dy := distances(from, to)
+	return math.Hypot(dx, dy)
+}
+
+var heuristics = map[string]Heuristic{
+	"manhattan": Manhattan,
+	"chebyshev": Chebyshev,
+	"octile":    Octile,
+	"euclidean": Euclidean,
+}
+
+// HeuristicByName looks a heuristic up by its lower-case name, e.g. for
+// configuration.
+func HeuristicByName(name string) (Heuristic, error) {
+	if heuristic, ok := heuristics[strings.ToLower(name)]; ok {
+		return heuristic, nil
+	}
+
+	names := make([]string, 0, len(heuristics))
+	for known := range heuristics {
+		names = append(names, known)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown heuristic %q, expected one of %s", name, strings.Join(names, ", "))
+}
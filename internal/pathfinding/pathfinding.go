@@ -0,0 +1,162 @@
+// Package pathfinding finds routes across tile grids with A*.
+package pathfinding
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Grid is what paths are searched on.
+type Grid interface {
+	// Walkable reports whether a tile can be stood on; tiles off the grid
+	// are not walkable.
+	Walkable(x, y int) bool
+}
+
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Options tune a search. The zero value searches 8-directionally with
+// every step costing 1, guided by Chebyshev distance, without a limit.
+type Options struct {
+	// Heuristic estimates the cost between two tiles; nil uses Chebyshev.
+	// It should never overestimate, or paths may not be the shortest.
+	Heuristic Heuristic
+	// NoDiagonals restricts paths to straight steps
+	NoDiagonals bool
+	// DiagonalCost is the cost of a diagonal step, a straight step costing
+	// 1; 0 means 1
+	DiagonalCost float64
+	// MaxNodes gives up after expanding this many tiles; 0 means no limit
+	MaxNodes int
+}
+
+var (
+	straightSteps = []Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	diagonalSteps = []Point{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+)
+
+// Find returns the cheapest path from one tile to another. The path leaves
+// out the starting tile, so it is empty when from is to. A diagonal step
+// needs both tiles it cuts between to be walkable.
+func Find(grid Grid, from, to Point, opts Options) ([]Point, bool) {
+	return FindAny(grid, from, []Point{to}, opts)
+}
+
+// FindAny returns the cheapest path from a tile to whichever of goals is
+// cheapest to reach.
+func FindAny(grid Grid, from Point, goals []Point, opts Options) ([]Point, bool) {
+	isGoal := make(map[Point]bool, len(goals))
+	for _, goal := range goals {
+		isGoal[goal] = true
+	}
+	if isGoal[from] {
+		return nil, true
+	}
+	if len(goals) == 0 {
+		return nil, false
+	}
+
+	heuristic := opts.Heuristic
+	if heuristic == nil {
+		heuristic = Chebyshev
+	}
+	estimate := func(p Point) float64 {
+		best := math.Inf(1)
+		for _, goal := range goals {
+			best = math.Min(best, heuristic(p, goal))
+		}
+		return best
+	}
+	diagonalCost := opts.DiagonalCost
+	if diagonalCost == 0 {
+		diagonalCost = 1
+	}
+
+	cost := map[Point]float64{from: 0}
+	previous := make(map[Point]Point)
+	closed := make(map[Point]bool)
+	open := &openSet{}
+	heap.Push(open, &node{point: from, estimate: estimate(from)})
+
+	for expanded := 0; open.Len() > 0; expanded++ {
+		if opts.MaxNodes > 0 && expanded >= opts.MaxNodes {
+			return nil, false
+		}
+
+		current := heap.Pop(open).(*node).point
+		if closed[current] {
+			continue
+		}
+		if isGoal[current] {
+			return buildPath(previous, from, current), true
+		}
+		closed[current] = true
+
+		try := func(step Point, stepCost float64) {
+			next := Point{current.X + step.X, current.Y + step.Y}
+			if closed[next] || !grid.Walkable(next.X, next.Y) {
+				return
+			}
+			nextCost := cost[current] + stepCost
+			if known, ok := cost[next]; ok && known <= nextCost {
+				return
+			}
+			cost[next] = nextCost
+			previous[next] = current
+			heap.Push(open, &node{point: next, cost: nextCost, estimate: nextCost + estimate(next)})
+		}
+
+		for _, step := range straightSteps {
+			try(step, 1)
+		}
+		if opts.NoDiagonals {
+			continue
+		}
+		for _, step := range diagonalSteps {
+			if grid.Walkable(current.X+step.X, current.Y) && grid.Walkable(current.X, current.Y+step.Y) {
+				try(step, diagonalCost)
+			}
+		}
+	}
+	return nil, false
+}
+
+func buildPath(previous map[Point]Point, from, to Point) []Point {
+	var path []Point
+	for at := to; at != from; at = previous[at] {
+		path = append(path, at)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+type node struct {
+	point    Point
+	cost     float64
+	estimate float64
+}
+
+// openSet is a min-heap of nodes by estimated total cost, preferring the
+// node furthest along on ties.
+type openSet []*node
+
+func (s openSet) Len() int { return len(s) }
+func (s openSet) Less(i, j int) bool {
+	if s[i].estimate != s[j].estimate {
+		return s[i].estimate < s[j].estimate
+	}
+	return s[i].cost > s[j].cost
+}
+func (s openSet) Swap(i, j int)       { s[i], s[j] = s[j], s[i] }
+func (s *openSet) Push(x interface{}) { *s = append(*s, x.(*node)) }
+func (s *openSet) Pop() interface{} {
+	old := *s
+	n := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return n
+}
@@ -0,0 +1,209 @@
+package pathfinding
+
+import (
+	"math"
+	"testing"
+)
+
+// testGrid is drawn one row per string, '#' marking a blocked tile.
+type testGrid []string
+
+func (g testGrid) Walkable(x, y int) bool {
+	return y >= 0 && y < len(g) && x >= 0 && x < len(g[y]) && g[y][x] != '#'
+}
+
+// checkPath fails unless path is a walk from from to to over walkable
+// tiles, one step at a time, without cutting the corner of a blocked tile.
+func checkPath(t *testing.T, grid Grid, from, to Point, path []Point, noDiagonals bool) {
+	t.Helper()
+	at := from
+	for i, step := range path {
+		dx, dy := step.X-at.X, step.Y-at.Y
+		switch {
+		case !grid.Walkable(step.X, step.Y):
+			t.Fatalf("step %d to %v is blocked: %v", i, step, path)
+		case dx < -1 || dx > 1 || dy < -1 || dy > 1 || (dx == 0 && dy == 0):
+			t.Fatalf("step %d from %v to %v is not to a neighbour: %v", i, at, step, path)
+		case dx != 0 && dy != 0 && noDiagonals:
+			t.Fatalf("step %d from %v to %v is diagonal: %v", i, at, step, path)
+		case dx != 0 && dy != 0 && (!grid.Walkable(at.X+dx, at.Y) || !grid.Walkable(at.X, at.Y+dy)):
+			t.Fatalf("step %d from %v to %v cuts a corner: %v", i, at, step, path)
+		}
+		at = step
+	}
+	if at != to {
+		t.Fatalf("path ends at %v, want %v: %v", at, to, path)
+	}
+}
+
+func TestFind(t *testing.T) {
+	open := testGrid{
+		".....",
+		".....",
+		".....",
+	}
+
+	tests := []struct {
+		name     string
+		grid     testGrid
+		from, to Point
+		opts     Options
+		want     bool
+		steps    int
+	}{
+		{"start is the goal", open, Point{2, 1}, Point{2, 1}, Options{}, true, 0},
+		{"open floor", open, Point{0, 0}, Point{4, 2}, Options{}, true, 4},
+		{"open floor without diagonals", open, Point{0, 0}, Point{4, 2}, Options{NoDiagonals: true}, true, 6},
+		{"around a wall", testGrid{
+			"..#..",
+			"..#..",
+			".....",
+		}, Point{0, 0}, Point{4, 0}, Options{}, true, 6},
+		{"through a gap", testGrid{
+			"..#..",
+			".....",
+			"..#..",
+		}, Point{0, 0}, Point{4, 0}, Options{}, true, 4},
+		{"no squeezing between diagonal blockers", testGrid{
+			".#.",
+			"#..",
+			"...",
+		}, Point{0, 0}, Point{1, 1}, Options{}, false, 0},
+		{"no cutting past one blocker", testGrid{
+			".#",
+			"..",
+		}, Point{0, 0}, Point{1, 1}, Options{}, true, 2},
+		{"walled off", testGrid{
+			"..#..",
+			"..#..",
+			"..#..",
+		}, Point{0, 1}, Point{4, 1}, Options{}, false, 0},
+		{"blocked goal", testGrid{
+			"...",
+			"..#",
+		}, Point{0, 0}, Point{2, 1}, Options{}, false, 0},
+		{"goal off the grid", open, Point{0, 0}, Point{9, 9}, Options{}, false, 0},
+		{"search limit", open, Point{0, 0}, Point{4, 2}, Options{MaxNodes: 2}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ok := Find(tt.grid, tt.from, tt.to, tt.opts)
+			if ok != tt.want {
+				t.Fatalf("Find = %v, %v, want found %v", path, ok, tt.want)
+			}
+			if !ok {
+				return
+			}
+			if len(path) != tt.steps {
+				t.Fatalf("path %v has %d steps, want %d", path, len(path), tt.steps)
+			}
+			checkPath(t, tt.grid, tt.from, tt.to, path, tt.opts.NoDiagonals)
+		})
+	}
+}
+
+// pathCost adds up a path's steps, diagonal ones at diagonalCost.
+func pathCost(from Point, path []Point, diagonalCost float64) float64 {
+	cost := 0.0
+	at := from
+	for _, step := range path {
+		if step.X != at.X && step.Y != at.Y {
+			cost += diagonalCost
+		} else {
+			cost++
+		}
+		at = step
+	}
+	return cost
+}
+
+func TestFindWithDiagonalCost(t *testing.T) {
+	grid := testGrid{
+		"....",
+		"....",
+		"....",
+	}
+	from, to := Point{0, 0}, Point{3, 2}
+
+	tests := []struct {
+		name string
+		opts Options
+		want float64
+	}{
+		{"default", Options{}, 3},
+		{"octile", Options{DiagonalCost: math.Sqrt2, Heuristic: Octile}, 1 + 2*math.Sqrt2},
+		{"diagonals as dear as two steps", Options{DiagonalCost: 2, Heuristic: Manhattan}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, ok := Find(grid, from, to, tt.opts)
+			if !ok {
+				t.Fatal("no path")
+			}
+			checkPath(t, grid, from, to, path, false)
+
+			diagonalCost := tt.opts.DiagonalCost
+			if diagonalCost == 0 {
+				diagonalCost = 1
+			}
+			if cost := pathCost(from, path, diagonalCost); math.Abs(cost-tt.want) > 1e-9 {
+				t.Errorf("path %v costs %.3f, want %.3f", path, cost, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAnyTakesTheNearestGoal(t *testing.T) {
+	grid := testGrid{
+		".........",
+		"....#....",
+		".........",
+	}
+	from := Point{4, 0}
+	near, far := Point{8, 1}, Point{0, 2}
+
+	path, ok := FindAny(grid, from, []Point{far, near}, Options{})
+	if !ok {
+		t.Fatal("no path to either goal")
+	}
+	checkPath(t, grid, from, near, path, false)
+
+	if path, ok := FindAny(grid, from, nil, Options{}); ok {
+		t.Fatalf("FindAny without goals = %v, want no path", path)
+	}
+	if path, ok := FindAny(grid, from, []Point{{4, 1}, from}, Options{}); !ok || len(path) != 0 {
+		t.Fatalf("FindAny from a goal = %v, %v, want an empty path", path, ok)
+	}
+}
+
+func TestHeuristics(t *testing.T) {
+	from, to := Point{1, 1}, Point{4, -3}
+
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{"manhattan", 7},
+		{"chebyshev", 4},
+		{"octile", 4 + 3*(math.Sqrt2-1)},
+		{"Euclidean", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heuristic, err := HeuristicByName(tt.name)
+			if err != nil {
+				t.Fatalf("HeuristicByName: %v", err)
+			}
+			if got := heuristic(from, to); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("estimate %.4f, want %.4f", got, tt.want)
+			}
+			if got := heuristic(to, from); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("estimate back %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := HeuristicByName("dijkstra"); err == nil {
+		t.Error("unknown heuristic: got no error")
+	}
+}
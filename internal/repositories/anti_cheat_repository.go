@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AntiCheatFilter narrows an anti-cheat search. Zero values match
+// everything.
+type AntiCheatFilter struct {
+	UserID *uuid.UUID
+	Kind   models.CheatKind
+	From   *time.Time
+	To     *time.Time
+}
+
+// Matches reports whether an event passes the filter.
+func (f AntiCheatFilter) Matches(event models.AntiCheatEvent) bool {
+	switch {
+	case f.UserID != nil && event.UserID != *f.UserID:
+		return false
+	case f.Kind != "" && event.Kind != f.Kind:
+		return false
+	case f.From != nil && event.CreatedAt.Before(*f.From):
+		return false
+	case f.To != nil && !event.CreatedAt.Before(*f.To):
+		return false
+	}
+	return true
+}
+
+type AntiCheatRepository interface {
+	Create(event *models.AntiCheatEvent) error
+	Search(filter AntiCheatFilter, pagination utils.PaginationParams) ([]models.AntiCheatEvent, int64, error)
+	// CountByUser counts events per user and kind since the given time.
+	CountByUser(since time.Time) ([]models.AntiCheatCount, error)
+}
+
+type antiCheatRepository struct {
+	db *gorm.DB
+}
+
+func NewAntiCheatRepository(db *gorm.DB) AntiCheatRepository {
+	return &antiCheatRepository{
+		db: db,
+	}
+}
+
+func (r *antiCheatRepository) Create(event *models.AntiCheatEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *antiCheatRepository) Search(filter AntiCheatFilter, pagination utils.PaginationParams) ([]models.AntiCheatEvent, int64, error) {
+	var events []models.AntiCheatEvent
+	var total int64
+
+	query := r.db.Model(&models.AntiCheatEvent{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC, id").
+		Offset(pagination.Offset).
+		Limit(pagination.PerPage).
+		Find(&events).Error
+
+	return events, total, err
+}
+
+func (r *antiCheatRepository) CountByUser(since time.Time) ([]models.AntiCheatCount, error) {
+	// Events are rare, so they are tallied here rather than with MAX() over
+	// timestamps, which each driver scans differently
+	var events []models.AntiCheatEvent
+	err := r.db.Select("user_id, kind, created_at").
+		Where("created_at >= ?", since).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return CountAntiCheatEvents(events), nil
+}
+
+// CountAntiCheatEvents tallies events per user and kind.
+func CountAntiCheatEvents(events []models.AntiCheatEvent) []models.AntiCheatCount {
+	type key struct {
+		userID uuid.UUID
+		kind   models.CheatKind
+	}
+	index := make(map[key]int)
+	var counts []models.AntiCheatCount
+	for _, event := range events {
+		k := key{event.UserID, event.Kind}
+		i, ok := index[k]
+		if !ok {
+			i = len(counts)
+			index[k] = i
+			counts = append(counts, models.AntiCheatCount{UserID: event.UserID, Kind: event.Kind})
+		}
+		counts[i].Count++
+		if event.CreatedAt.After(counts[i].LastAt) {
+			counts[i].LastAt = event.CreatedAt
+		}
+	}
+	return counts
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows an audit log search. Zero values match everything.
+type AuditLogFilter struct {
+	ActorID    *uuid.UUID
+	Action     models.AuditAction
+	TargetType string
+	TargetID   *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+// Matches reports whether an entry passes the filter.
+func (f AuditLogFilter) Matches(entry models.AuditLog) bool {
+	switch {
+	case f.ActorID != nil && (entry.ActorID == nil || *entry.ActorID != *f.ActorID):
+		return false
+	case f.Action != "" && entry.Action != f.Action:
+		return false
+	case f.TargetType != "" && entry.TargetType != f.TargetType:
+		return false
+	case f.TargetID != nil && (entry.TargetID == nil || *entry.TargetID != *f.TargetID):
+		return false
+	case f.From != nil && entry.CreatedAt.Before(*f.From):
+		return false
+	case f.To != nil && !entry.CreatedAt.Before(*f.To):
+		return false
+	}
+	return true
+}
+
+type AuditLogRepository interface {
+	Create(entry *models.AuditLog) error
+	Search(filter AuditLogFilter, pagination utils.PaginationParams) ([]models.AuditLog, int64, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+func (r *auditLogRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *auditLogRepository) Search(filter AuditLogFilter, pagination utils.PaginationParams) ([]models.AuditLog, int64, error) {
+	var entries []models.AuditLog
+	var total int64
+
+	query := r.db.Model(&models.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC, id").
+		Offset(pagination.Offset).
+		Limit(pagination.PerPage).
+		Find(&entries).Error
+
+	return entries, total, err
+}
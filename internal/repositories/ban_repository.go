@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BanRepository interface {
+	Create(ban *models.UserBan) error
+	GetActiveBan(userID uuid.UUID) (*models.UserBan, error)
+	GetUserBans(userID uuid.UUID) ([]models.UserBan, error)
+	GetAll(pagination utils.PaginationParams, activeOnly bool) ([]models.UserBan, int64, error)
+	LiftActive(userID, liftedByID uuid.UUID) (int64, error)
+}
+
+type banRepository struct {
+	db *gorm.DB
+}
+
+func NewBanRepository(db *gorm.DB) BanRepository {
+	return &banRepository{
+		db: db,
+	}
+}
+
+func (r *banRepository) Create(ban *models.UserBan) error {
+	return r.db.Create(ban).Error
+}
+
+// active limits a query to bans in force right now. Expired bans simply
+// stop matching, so no job is needed to end them.
+func active(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("lifted_at IS NULL AND starts_at <= ? AND (expires_at IS NULL OR expires_at > ?)", now, now)
+}
+
+// GetActiveBan returns the ban in force that lasts longest, permanent bans
+// first.
+func (r *banRepository) GetActiveBan(userID uuid.UUID) (*models.UserBan, error) {
+	var ban models.UserBan
+	err := active(r.db, time.Now()).
+		Where("user_id = ?", userID).
+		Order("CASE WHEN expires_at IS NULL THEN 0 ELSE 1 END, expires_at DESC").
+		First(&ban).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ban, nil
+}
+
+func (r *banRepository) GetUserBans(userID uuid.UUID) ([]models.UserBan, error) {
+	var bans []models.UserBan
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&bans).Error
+	return bans, err
+}
+
+func (r *banRepository) GetAll(pagination utils.PaginationParams, activeOnly bool) ([]models.UserBan, int64, error) {
+	var bans []models.UserBan
+	var total int64
+
+	query := r.db.Model(&models.UserBan{})
+	if activeOnly {
+		query = active(query, time.Now())
+	}
+	query.Count(&total)
+
+	err := query.Order("created_at DESC").
+		Offset(pagination.Offset).
+		Limit(pagination.PerPage).
+		Find(&bans).Error
+
+	return bans, total, err
+}
+
+func (r *banRepository) LiftActive(userID, liftedByID uuid.UUID) (int64, error) {
+	now := time.Now()
+	result := active(r.db.Model(&models.UserBan{}), now).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"lifted_at":    now,
+			"lifted_by_id": liftedByID,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChatHistoryFilter selects the history of one channel. ScopeID picks the
+// map or guild; whisper history is the conversation between UserID and
+// PeerID. Messages from ExcludeSenders are left out.
+type ChatHistoryFilter struct {
+	Channel        models.ChatChannel
+	ScopeID        *uuid.UUID
+	UserID         uuid.UUID
+	PeerID         uuid.UUID
+	ExcludeSenders []uuid.UUID
+}
+
+// Matches reports whether a visible message belongs to the history.
+func (f ChatHistoryFilter) Matches(msg models.ChatMessage) bool {
+	if msg.RemovedAt != nil || msg.Channel != f.Channel {
+		return false
+	}
+	if f.ScopeID != nil && (msg.ScopeID == nil || *msg.ScopeID != *f.ScopeID) {
+		return false
+	}
+	if f.Channel == models.ChatChannelWhisper {
+		if msg.RecipientID == nil {
+			return false
+		}
+		forward := msg.SenderID == f.UserID && *msg.RecipientID == f.PeerID
+		backward := msg.SenderID == f.PeerID && *msg.RecipientID == f.UserID
+		if !forward && !backward {
+			return false
+		}
+	}
+	for _, id := range f.ExcludeSenders {
+		if msg.SenderID == id {
+			return false
+		}
+	}
+	return true
+}
+
+type ChatRepository interface {
+	CreateMessage(msg *models.ChatMessage) error
+	GetMessage(id uuid.UUID) (*models.ChatMessage, error)
+	RemoveMessage(id, removedByID uuid.UUID) (int64, error)
+	GetHistory(filter ChatHistoryFilter, pagination utils.PaginationParams) ([]models.ChatMessage, int64, error)
+	GetLastSentAt(senderID uuid.UUID, channel models.ChatChannel, scopeID *uuid.UUID) (time.Time, error)
+	SaveMute(mute *models.ChatMute) error
+	DeleteMute(userID, mutedUserID uuid.UUID) (int64, error)
+	GetMutes(userID uuid.UUID) ([]models.ChatMute, error)
+	GetMuterIDs(mutedUserID uuid.UUID) ([]uuid.UUID, error)
+	GetGuildMemberIDs(guildID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type chatRepository struct {
+	db *gorm.DB
+}
+
+func NewChatRepository(db *gorm.DB) ChatRepository {
+	return &chatRepository{
+		db: db,
+	}
+}
+
+func (r *chatRepository) CreateMessage(msg *models.ChatMessage) error {
+	return r.db.Omit("Sender").Create(msg).Error
+}
+
+func (r *chatRepository) GetMessage(id uuid.UUID) (*models.ChatMessage, error) {
+	var msg models.ChatMessage
+	err := r.db.Preload("Sender").Where("id = ?", id).First(&msg).Error
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// RemoveMessage hides a message from history, keeping the row for
+// moderation.
+func (r *chatRepository) RemoveMessage(id, removedByID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.ChatMessage{}).
+		Where("id = ? AND removed_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"removed_at":    time.Now(),
+			"removed_by_id": removedByID,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// GetHistory returns matching messages newest first.
+func (r *chatRepository) GetHistory(filter ChatHistoryFilter, pagination utils.PaginationParams) ([]models.ChatMessage, int64, error) {
+	var messages []models.ChatMessage
+	var total int64
+
+	query := r.db.Model(&models.ChatMessage{}).
+		Where("channel = ? AND removed_at IS NULL", filter.Channel)
+	if filter.ScopeID != nil {
+		query = query.Where("scope_id = ?", *filter.ScopeID)
+	}
+	if filter.Channel == models.ChatChannelWhisper {
+		query = query.Where("(sender_id = ? AND recipient_id = ?) OR (sender_id = ? AND recipient_id = ?)",
+			filter.UserID, filter.PeerID, filter.PeerID, filter.UserID)
+	}
+	if len(filter.ExcludeSenders) > 0 {
+		query = query.Where("sender_id NOT IN ?", filter.ExcludeSenders)
+	}
+	query.Count(&total)
+
+	err := query.Preload("Sender").
+		Order("created_at DESC").
+		Offset(pagination.Offset).
+		Limit(pagination.PerPage).
+		Find(&messages).Error
+
+	return messages, total, err
+}
+
+// GetLastSentAt returns when the sender last posted to a channel's scope,
+// or the zero time if they never did. Removed messages count.
+func (r *chatRepository) GetLastSentAt(senderID uuid.UUID, channel models.ChatChannel, scopeID *uuid.UUID) (time.Time, error) {
+	query := r.db.Model(&models.ChatMessage{}).
+		Where("sender_id = ? AND channel = ?", senderID, channel)
+	if scopeID != nil {
+		query = query.Where("scope_id = ?", *scopeID)
+	} else {
+		query = query.Where("scope_id IS NULL")
+	}
+
+	var messages []models.ChatMessage
+	err := query.Select("created_at").Order("created_at DESC").Limit(1).Find(&messages).Error
+	if err != nil || len(messages) == 0 {
+		return time.Time{}, err
+	}
+	return messages[0].CreatedAt, nil
+}
+
+// SaveMute mutes a user, replacing the expiry of an existing mute.
+func (r *chatRepository) SaveMute(mute *models.ChatMute) error {
+	var existing models.ChatMute
+	err := r.db.Where("user_id = ? AND muted_user_id = ?", mute.UserID, mute.MutedUserID).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.db.Omit("MutedUser").Create(mute).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	mute.ID = existing.ID
+	mute.CreatedAt = existing.CreatedAt
+	return r.db.Model(&existing).Update("expires_at", mute.ExpiresAt).Error
+}
+
+func (r *chatRepository) DeleteMute(userID, mutedUserID uuid.UUID) (int64, error) {
+	result := r.db.Where("user_id = ? AND muted_user_id = ?", userID, mutedUserID).Delete(&models.ChatMute{})
+	return result.RowsAffected, result.Error
+}
+
+// activeMutes limits a query to mutes that have not expired.
+func activeMutes(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("expires_at IS NULL OR expires_at > ?", now)
+}
+
+func (r *chatRepository) GetMutes(userID uuid.UUID) ([]models.ChatMute, error) {
+	var mutes []models.ChatMute
+	err := activeMutes(r.db, time.Now()).
+		Preload("MutedUser").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&mutes).Error
+	return mutes, err
+}
+
+// GetMuterIDs lists the users who currently mute mutedUserID.
+func (r *chatRepository) GetMuterIDs(mutedUserID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := activeMutes(r.db.Model(&models.ChatMute{}), time.Now()).
+		Where("muted_user_id = ?", mutedUserID).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *chatRepository) GetGuildMemberIDs(guildID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.GuildMember{}).
+		Where("guild_id = ?", guildID).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
@@ -1,44 +1,59 @@
 package repositories
 
 import (
-	"code-valley-api/internal/database"
+	"time"
+
 	"code-valley-api/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type FriendRepository struct {
+type FriendRepository interface {
+	CreateFriendship(friendship *models.Friendship) error
+	GetFriendship(requesterID, addresseeID uuid.UUID) (*models.Friendship, error)
+	UpdateFriendship(friendship *models.Friendship) error
+	DeleteFriendship(requesterID, addresseeID uuid.UUID) error
+	GetUserFriends(userID uuid.UUID) ([]models.User, error)
+	GetPendingRequests(userID uuid.UUID) ([]models.Friendship, error)
+	GetOnlineFriends(userID uuid.UUID) ([]models.OnlineUser, error)
+	GetBlockedIDs(userID uuid.UUID) ([]uuid.UUID, error)
+	GetPresence(userID uuid.UUID) (*models.OnlineUser, error)
+	SavePresence(presence *models.OnlineUser) error
+	ResetPresence(keep []uuid.UUID) (int64, error)
+}
+
+type friendRepository struct {
 	db *gorm.DB
 }
 
-func NewFriendRepository() *FriendRepository {
-	return &FriendRepository{
-		db: database.GetDB(),
+func NewFriendRepository(db *gorm.DB) FriendRepository {
+	return &friendRepository{
+		db: db,
 	}
 }
 
-func (r *FriendRepository) CreateFriendship(friendship *models.Friendship) error {
+func (r *friendRepository) CreateFriendship(friendship *models.Friendship) error {
 	return r.db.Create(friendship).Error
 }
 
-func (r *FriendRepository) GetFriendship(requesterID, addresseeID uuid.UUID) (*models.Friendship, error) {
+func (r *friendRepository) GetFriendship(requesterID, addresseeID uuid.UUID) (*models.Friendship, error) {
 	var friendship models.Friendship
 	err := r.db.Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)",
 		requesterID, addresseeID, addresseeID, requesterID).First(&friendship).Error
 	return &friendship, err
 }
 
-func (r *FriendRepository) UpdateFriendship(friendship *models.Friendship) error {
+func (r *friendRepository) UpdateFriendship(friendship *models.Friendship) error {
 	return r.db.Save(friendship).Error
 }
 
-func (r *FriendRepository) DeleteFriendship(requesterID, addresseeID uuid.UUID) error {
+func (r *friendRepository) DeleteFriendship(requesterID, addresseeID uuid.UUID) error {
 	return r.db.Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)",
 		requesterID, addresseeID, addresseeID, requesterID).Delete(&models.Friendship{}).Error
 }
 
-func (r *FriendRepository) GetUserFriends(userID uuid.UUID) ([]models.User, error) {
+func (r *friendRepository) GetUserFriends(userID uuid.UUID) ([]models.User, error) {
 	var friends []models.User
 	err := r.db.Table("users").
 		Joins("JOIN friendships ON (users.id = friendships.requester_id OR users.id = friendships.addressee_id)").
@@ -48,7 +63,7 @@ func (r *FriendRepository) GetUserFriends(userID uuid.UUID) ([]models.User, erro
 	return friends, err
 }
 
-func (r *FriendRepository) GetPendingRequests(userID uuid.UUID) ([]models.Friendship, error) {
+func (r *friendRepository) GetPendingRequests(userID uuid.UUID) ([]models.Friendship, error) {
 	var requests []models.Friendship
 	err := r.db.Preload("Requester").Preload("Addressee").
 		Where("addressee_id = ? AND status = ?", userID, models.FriendshipStatusPending).
@@ -56,13 +71,60 @@ func (r *FriendRepository) GetPendingRequests(userID uuid.UUID) ([]models.Friend
 	return requests, err
 }
 
-func (r *FriendRepository) GetOnlineFriends(userID uuid.UUID) ([]models.User, error) {
-	var friends []models.User
-	err := r.db.Table("users").
-		Joins("JOIN friendships ON (users.id = friendships.requester_id OR users.id = friendships.addressee_id)").
-		Joins("JOIN online_users ON users.id = online_users.user_id").
-		Where("(friendships.requester_id = ? OR friendships.addressee_id = ?) AND friendships.status = ? AND users.id != ? AND online_users.is_online = ?",
-			userID, userID, models.FriendshipStatusAccepted, userID, true).
+// GetOnlineFriends returns the presence of accepted friends who are online
+// and not invisible.
+func (r *friendRepository) GetOnlineFriends(userID uuid.UUID) ([]models.OnlineUser, error) {
+	var friends []models.OnlineUser
+	err := r.db.Preload("User").
+		Joins("JOIN friendships ON (online_users.user_id = friendships.requester_id OR online_users.user_id = friendships.addressee_id)").
+		Where("(friendships.requester_id = ? OR friendships.addressee_id = ?) AND friendships.status = ? AND online_users.user_id != ? AND online_users.is_online = ? AND online_users.status != ?",
+			userID, userID, models.FriendshipStatusAccepted, userID, true, models.PresenceInvisible).
 		Find(&friends).Error
 	return friends, err
-}
\ No newline at end of file
+}
+
+// GetBlockedIDs lists users in a blocked friendship with userID, whichever
+// side did the blocking.
+func (r *friendRepository) GetBlockedIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	var friendships []models.Friendship
+	err := r.db.Where("(requester_id = ? OR addressee_id = ?) AND status = ?",
+		userID, userID, models.FriendshipStatusBlocked).
+		Find(&friendships).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(friendships))
+	for _, friendship := range friendships {
+		if friendship.RequesterID == userID {
+			ids = append(ids, friendship.AddresseeID)
+		} else {
+			ids = append(ids, friendship.RequesterID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *friendRepository) GetPresence(userID uuid.UUID) (*models.OnlineUser, error) {
+	var presence models.OnlineUser
+	err := r.db.Where("user_id = ?", userID).First(&presence).Error
+	return &presence, err
+}
+
+func (r *friendRepository) SavePresence(presence *models.OnlineUser) error {
+	// Select every column so a new offline row doesn't pick up the
+	// is_online default
+	return r.db.Select("*").Omit("User").Save(presence).Error
+}
+
+// ResetPresence marks every user offline except those in keep. Rows left
+// online by a crashed server would otherwise show users online forever.
+func (r *friendRepository) ResetPresence(keep []uuid.UUID) (int64, error) {
+	query := r.db.Model(&models.OnlineUser{}).Where("is_online = ?", true)
+	if len(keep) > 0 {
+		query = query.Where("user_id NOT IN ?", keep)
+	}
+	result := query.
+		Updates(map[string]interface{}{"is_online": false, "last_seen": time.Now()})
+	return result.RowsAffected, result.Error
+}
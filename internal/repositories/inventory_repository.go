@@ -1,27 +1,35 @@
 package repositories
 
 import (
-	"code-valley-api/internal/database"
 	"code-valley-api/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type InventoryRepository struct {
+type InventoryRepository interface {
+	AddItem(item *models.Inventory) error
+	GetUserItem(userID, itemID uuid.UUID) (*models.Inventory, error)
+	GetUserItemForUpdate(userID, itemID uuid.UUID) (*models.Inventory, error)
+	UpdateItem(item *models.Inventory) error
+	RemoveItem(userID, itemID uuid.UUID) error
+	GetUserInventory(userID uuid.UUID) ([]models.Inventory, error)
+}
+
+type inventoryRepository struct {
 	db *gorm.DB
 }
 
-func NewInventoryRepository() *InventoryRepository {
-	return &InventoryRepository{
-		db: database.GetDB(),
+func NewInventoryRepository(db *gorm.DB) InventoryRepository {
+	return &inventoryRepository{
+		db: db,
 	}
 }
 
-func (r *InventoryRepository) AddItem(item *models.Inventory) error {
+func (r *inventoryRepository) AddItem(item *models.Inventory) error {
 	// Check if item already exists
 	var existing models.Inventory
-	err := r.db.Where("user_id = ? AND item_name = ?", item.UserID, item.ItemName).First(&existing).Error
+	err := forUpdate(r.db).Where("user_id = ? AND item_name = ?", item.UserID, item.ItemName).First(&existing).Error
 	
 	if err == nil {
 		// Item exists, update quantity
@@ -35,21 +43,27 @@ func (r *InventoryRepository) AddItem(item *models.Inventory) error {
 	return err
 }
 
-func (r *InventoryRepository) GetUserItem(userID, itemID uuid.UUID) (*models.Inventory, error) {
+func (r *inventoryRepository) GetUserItem(userID, itemID uuid.UUID) (*models.Inventory, error) {
 	var item models.Inventory
 	err := r.db.Where("user_id = ? AND id = ?", userID, itemID).First(&item).Error
 	return &item, err
 }
 
-func (r *InventoryRepository) UpdateItem(item *models.Inventory) error {
+func (r *inventoryRepository) GetUserItemForUpdate(userID, itemID uuid.UUID) (*models.Inventory, error) {
+	var item models.Inventory
+	err := forUpdate(r.db).Where("user_id = ? AND id = ?", userID, itemID).First(&item).Error
+	return &item, err
+}
+
+func (r *inventoryRepository) UpdateItem(item *models.Inventory) error {
 	return r.db.Save(item).Error
 }
 
-func (r *InventoryRepository) RemoveItem(userID, itemID uuid.UUID) error {
+func (r *inventoryRepository) RemoveItem(userID, itemID uuid.UUID) error {
 	return r.db.Where("user_id = ? AND id = ?", userID, itemID).Delete(&models.Inventory{}).Error
 }
 
-func (r *InventoryRepository) GetUserInventory(userID uuid.UUID) ([]models.Inventory, error) {
+func (r *inventoryRepository) GetUserInventory(userID uuid.UUID) ([]models.Inventory, error) {
 	var items []models.Inventory
 	err := r.db.Where("user_id = ?", userID).Find(&items).Error
 	return items, err
@@ -1,35 +1,40 @@
 package repositories
 
 import (
-	"code-valley-api/internal/database"
 	"code-valley-api/internal/models"
 
 	"gorm.io/gorm"
 )
 
-type LeaderboardRepository struct {
+type LeaderboardRepository interface {
+	GetTopUsersByCoins(limit int) ([]models.User, error)
+	GetTopUsersByEXP(limit int) ([]models.User, error)
+	GetTopUsersByTasksCompleted(limit int) ([]models.UserStatistics, error)
+}
+
+type leaderboardRepository struct {
 	db *gorm.DB
 }
 
-func NewLeaderboardRepository() *LeaderboardRepository {
-	return &LeaderboardRepository{
-		db: database.GetDB(),
+func NewLeaderboardRepository(db *gorm.DB) LeaderboardRepository {
+	return &leaderboardRepository{
+		db: db,
 	}
 }
 
-func (r *LeaderboardRepository) GetTopUsersByCoins(limit int) ([]models.User, error) {
+func (r *leaderboardRepository) GetTopUsersByCoins(limit int) ([]models.User, error) {
 	var users []models.User
 	err := r.db.Order("coins DESC").Limit(limit).Find(&users).Error
 	return users, err
 }
 
-func (r *LeaderboardRepository) GetTopUsersByEXP(limit int) ([]models.User, error) {
+func (r *leaderboardRepository) GetTopUsersByEXP(limit int) ([]models.User, error) {
 	var users []models.User
 	err := r.db.Order("exp DESC").Limit(limit).Find(&users).Error
 	return users, err
 }
 
-func (r *LeaderboardRepository) GetTopUsersByTasksCompleted(limit int) ([]models.UserStatistics, error) {
+func (r *leaderboardRepository) GetTopUsersByTasksCompleted(limit int) ([]models.UserStatistics, error) {
 	var stats []models.UserStatistics
 	err := r.db.Preload("User").Order("tasks_completed DESC").Limit(limit).Find(&stats).Error
 	return stats, err
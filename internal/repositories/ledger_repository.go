@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LedgerRepository interface {
+	Post(entries []models.CoinLedgerEntry) error
+	GetBalance(userID uuid.UUID) (int, error)
+	GetUserHistory(userID uuid.UUID, pagination utils.PaginationParams) ([]models.CoinLedgerEntry, int64, error)
+	GetDrift() ([]models.CoinDrift, error)
+	GetUnbalancedTransactions() ([]uuid.UUID, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{
+		db: db,
+	}
+}
+
+func (r *ledgerRepository) Post(entries []models.CoinLedgerEntry) error {
+	return r.db.Create(&entries).Error
+}
+
+func (r *ledgerRepository) GetBalance(userID uuid.UUID) (int, error) {
+	var balance int
+	err := r.db.Model(&models.CoinLedgerEntry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account = ? AND user_id = ?", models.LedgerAccountWallet, userID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (r *ledgerRepository) GetUserHistory(userID uuid.UUID, pagination utils.PaginationParams) ([]models.CoinLedgerEntry, int64, error) {
+	var entries []models.CoinLedgerEntry
+	var total int64
+
+	query := r.db.Model(&models.CoinLedgerEntry{}).
+		Where("account = ? AND user_id = ?", models.LedgerAccountWallet, userID)
+	query.Count(&total)
+
+	err := query.Order("created_at DESC").
+		Offset(pagination.Offset).
+		Limit(pagination.PerPage).
+		Find(&entries).Error
+
+	return entries, total, err
+}
+
+// GetDrift compares every user's cached coin balance with the sum of their
+// wallet entries and returns the users that disagree.
+func (r *ledgerRepository) GetDrift() ([]models.CoinDrift, error) {
+	var drift []models.CoinDrift
+	err := r.db.Table("users").
+		Select("users.id AS user_id, users.username, users.coins AS cached_balance, COALESCE(SUM(coin_ledger.amount), 0) AS ledger_balance").
+		Joins("LEFT JOIN coin_ledger ON coin_ledger.user_id = users.id AND coin_ledger.account = ?", models.LedgerAccountWallet).
+		Group("users.id, users.username, users.coins").
+		Having("users.coins <> COALESCE(SUM(coin_ledger.amount), 0)").
+		Scan(&drift).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range drift {
+		drift[i].Drift = drift[i].CachedBalance - drift[i].LedgerBalance
+	}
+	return drift, nil
+}
+
+// GetUnbalancedTransactions returns transactions whose legs do not sum to
+// zero, which would mean coins were created or destroyed.
+func (r *ledgerRepository) GetUnbalancedTransactions() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.CoinLedgerEntry{}).
+		Select("transaction_id").
+		Group("transaction_id").
+		Having("SUM(amount) <> 0").
+		Pluck("transaction_id", &ids).Error
+	return ids, err
+}
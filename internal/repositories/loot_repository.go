@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LootRepository interface {
+	GetAll() ([]models.LootTable, error)
+	GetByName(name string) (*models.LootTable, error)
+	Create(table *models.LootTable) error
+	Update(table *models.LootTable) error
+	Delete(id uuid.UUID) error
+}
+
+type lootRepository struct {
+	db *gorm.DB
+}
+
+func NewLootRepository(db *gorm.DB) LootRepository {
+	return &lootRepository{
+		db: db,
+	}
+}
+
+func (r *lootRepository) GetAll() ([]models.LootTable, error) {
+	var tables []models.LootTable
+	err := r.db.Order("name").Find(&tables).Error
+	return tables, err
+}
+
+func (r *lootRepository) GetByName(name string) (*models.LootTable, error) {
+	var table models.LootTable
+	err := r.db.Where("name = ?", name).First(&table).Error
+	return &table, err
+}
+
+func (r *lootRepository) Create(table *models.LootTable) error {
+	return r.db.Create(table).Error
+}
+
+func (r *lootRepository) Update(table *models.LootTable) error {
+	return r.db.Save(table).Error
+}
+
+func (r *lootRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.LootTable{}, "id = ?", id).Error
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/utils"
+)
+
+type AntiCheatRepository struct {
+	s *Store
+}
+
+func (r *AntiCheatRepository) Create(event *models.AntiCheatEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	event.ID = ensureID(event.ID)
+	event.CreatedAt, _ = stamp(event.CreatedAt)
+	r.s.antiCheatEvents = append(r.s.antiCheatEvents, *event)
+	return nil
+}
+
+func (r *AntiCheatRepository) Search(filter repositories.AntiCheatFilter, pagination utils.PaginationParams) ([]models.AntiCheatEvent, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	// Events are appended in order, so walking backwards is newest first
+	var events []models.AntiCheatEvent
+	for i := len(r.s.antiCheatEvents) - 1; i >= 0; i-- {
+		if filter.Matches(r.s.antiCheatEvents[i]) {
+			events = append(events, r.s.antiCheatEvents[i])
+		}
+	}
+	return paginate(events, pagination), int64(len(events)), nil
+}
+
+func (r *AntiCheatRepository) CountByUser(since time.Time) ([]models.AntiCheatCount, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var events []models.AntiCheatEvent
+	for _, event := range r.s.antiCheatEvents {
+		if !event.CreatedAt.Before(since) {
+			events = append(events, event)
+		}
+	}
+	return repositories.CountAntiCheatEvents(events), nil
+}
@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/utils"
+)
+
+type AuditLogRepository struct {
+	s *Store
+}
+
+func (r *AuditLogRepository) Create(entry *models.AuditLog) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	entry.ID = ensureID(entry.ID)
+	entry.CreatedAt, _ = stamp(entry.CreatedAt)
+	r.s.auditLogs = append(r.s.auditLogs, *entry)
+	return nil
+}
+
+func (r *AuditLogRepository) Search(filter repositories.AuditLogFilter, pagination utils.PaginationParams) ([]models.AuditLog, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	// Entries are appended in order, so walking backwards is newest first
+	var entries []models.AuditLog
+	for i := len(r.s.auditLogs) - 1; i >= 0; i-- {
+		if filter.Matches(r.s.auditLogs[i]) {
+			entries = append(entries, r.s.auditLogs[i])
+		}
+	}
+	return paginate(entries, pagination), int64(len(entries)), nil
+}
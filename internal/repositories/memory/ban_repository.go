@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+type BanRepository struct {
+	s *Store
+}
+
+func (r *BanRepository) Create(ban *models.UserBan) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ban.ID = ensureID(ban.ID)
+	ban.CreatedAt, _ = stamp(ban.CreatedAt)
+	r.s.bans[ban.ID] = *ban
+	return nil
+}
+
+func (r *BanRepository) GetActiveBan(userID uuid.UUID) (*models.UserBan, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	now := time.Now()
+	var longest *models.UserBan
+	for _, ban := range r.s.bans {
+		if ban.UserID != userID || !ban.IsActive(now) {
+			continue
+		}
+		if longest == nil || ban.ExpiresAt == nil || (longest.ExpiresAt != nil && ban.ExpiresAt.After(*longest.ExpiresAt)) {
+			b := ban
+			longest = &b
+		}
+	}
+	if longest == nil {
+		return notFound[models.UserBan]()
+	}
+	return longest, nil
+}
+
+func (r *BanRepository) GetUserBans(userID uuid.UUID) ([]models.UserBan, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var bans []models.UserBan
+	for _, ban := range r.s.bans {
+		if ban.UserID == userID {
+			bans = append(bans, ban)
+		}
+	}
+	newestFirst(bans)
+	return bans, nil
+}
+
+func (r *BanRepository) GetAll(pagination utils.PaginationParams, activeOnly bool) ([]models.UserBan, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	now := time.Now()
+	var bans []models.UserBan
+	for _, ban := range r.s.bans {
+		if !activeOnly || ban.IsActive(now) {
+			bans = append(bans, ban)
+		}
+	}
+	newestFirst(bans)
+	return paginate(bans, pagination), int64(len(bans)), nil
+}
+
+func (r *BanRepository) LiftActive(userID, liftedByID uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	var lifted int64
+	for id, ban := range r.s.bans {
+		if ban.UserID == userID && ban.IsActive(now) {
+			ban.LiftedAt = &now
+			ban.LiftedByID = &liftedByID
+			r.s.bans[id] = ban
+			lifted++
+		}
+	}
+	return lifted, nil
+}
+
+func newestFirst(bans []models.UserBan) {
+	byCreated(bans, func(b models.UserBan) time.Time { return b.CreatedAt }, func(b models.UserBan) uuid.UUID { return b.ID })
+	for i, j := 0, len(bans)-1; i < j; i, j = i+1, j-1 {
+		bans[i], bans[j] = bans[j], bans[i]
+	}
+}
@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+type ChatRepository struct {
+	s *Store
+}
+
+func (r *ChatRepository) CreateMessage(msg *models.ChatMessage) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	msg.ID = ensureID(msg.ID)
+	msg.CreatedAt, _ = stamp(msg.CreatedAt)
+	stored := *msg
+	stored.Sender = models.User{}
+	r.s.chatMessages[msg.ID] = stored
+	return nil
+}
+
+func (r *ChatRepository) GetMessage(id uuid.UUID) (*models.ChatMessage, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	msg, ok := r.s.chatMessages[id]
+	if !ok {
+		return notFound[models.ChatMessage]()
+	}
+	msg.Sender = r.s.users[msg.SenderID]
+	return &msg, nil
+}
+
+func (r *ChatRepository) RemoveMessage(id, removedByID uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	msg, ok := r.s.chatMessages[id]
+	if !ok || msg.RemovedAt != nil {
+		return 0, nil
+	}
+	now := time.Now()
+	msg.RemovedAt = &now
+	msg.RemovedByID = &removedByID
+	r.s.chatMessages[id] = msg
+	return 1, nil
+}
+
+func (r *ChatRepository) GetHistory(filter repositories.ChatHistoryFilter, pagination utils.PaginationParams) ([]models.ChatMessage, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var messages []models.ChatMessage
+	for _, msg := range r.s.chatMessages {
+		if filter.Matches(msg) {
+			msg.Sender = r.s.users[msg.SenderID]
+			messages = append(messages, msg)
+		}
+	}
+	byCreated(messages, func(m models.ChatMessage) time.Time { return m.CreatedAt }, func(m models.ChatMessage) uuid.UUID { return m.ID })
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return paginate(messages, pagination), int64(len(messages)), nil
+}
+
+func (r *ChatRepository) GetLastSentAt(senderID uuid.UUID, channel models.ChatChannel, scopeID *uuid.UUID) (time.Time, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var last time.Time
+	for _, msg := range r.s.chatMessages {
+		sameScope := (msg.ScopeID == nil) == (scopeID == nil) && (scopeID == nil || *msg.ScopeID == *scopeID)
+		if msg.SenderID == senderID && msg.Channel == channel && sameScope && msg.CreatedAt.After(last) {
+			last = msg.CreatedAt
+		}
+	}
+	return last, nil
+}
+
+func (r *ChatRepository) SaveMute(mute *models.ChatMute) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, existing := range r.s.chatMutes {
+		if existing.UserID == mute.UserID && existing.MutedUserID == mute.MutedUserID {
+			existing.ExpiresAt = mute.ExpiresAt
+			r.s.chatMutes[id] = existing
+			mute.ID, mute.CreatedAt = existing.ID, existing.CreatedAt
+			return nil
+		}
+	}
+
+	mute.ID = ensureID(mute.ID)
+	mute.CreatedAt, _ = stamp(mute.CreatedAt)
+	stored := *mute
+	stored.MutedUser = models.User{}
+	r.s.chatMutes[mute.ID] = stored
+	return nil
+}
+
+func (r *ChatRepository) DeleteMute(userID, mutedUserID uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var deleted int64
+	for id, mute := range r.s.chatMutes {
+		if mute.UserID == userID && mute.MutedUserID == mutedUserID {
+			delete(r.s.chatMutes, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *ChatRepository) GetMutes(userID uuid.UUID) ([]models.ChatMute, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	now := time.Now()
+	var mutes []models.ChatMute
+	for _, mute := range r.s.chatMutes {
+		if mute.UserID == userID && mute.IsActive(now) {
+			mute.MutedUser = r.s.users[mute.MutedUserID]
+			mutes = append(mutes, mute)
+		}
+	}
+	byCreated(mutes, func(m models.ChatMute) time.Time { return m.CreatedAt }, func(m models.ChatMute) uuid.UUID { return m.ID })
+	for i, j := 0, len(mutes)-1; i < j; i, j = i+1, j-1 {
+		mutes[i], mutes[j] = mutes[j], mutes[i]
+	}
+	return mutes, nil
+}
+
+func (r *ChatRepository) GetMuterIDs(mutedUserID uuid.UUID) ([]uuid.UUID, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	now := time.Now()
+	var ids []uuid.UUID
+	for _, mute := range r.s.chatMutes {
+		if mute.MutedUserID == mutedUserID && mute.IsActive(now) {
+			ids = append(ids, mute.UserID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *ChatRepository) GetGuildMemberIDs(guildID uuid.UUID) ([]uuid.UUID, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var ids []uuid.UUID
+	for _, member := range r.s.guildMembers {
+		if member.GuildID == guildID {
+			ids = append(ids, member.UserID)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type FriendRepository struct {
+	s *Store
+}
+
+func (r *FriendRepository) CreateFriendship(friendship *models.Friendship) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	friendship.ID = ensureID(friendship.ID)
+	friendship.CreatedAt, friendship.UpdatedAt = stamp(friendship.CreatedAt)
+	r.s.friendships[friendship.ID] = *friendship
+	return nil
+}
+
+func (r *FriendRepository) GetFriendship(requesterID, addresseeID uuid.UUID) (*models.Friendship, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, friendship := range r.s.friendships {
+		if between(friendship, requesterID, addresseeID) {
+			return &friendship, nil
+		}
+	}
+	return notFound[models.Friendship]()
+}
+
+func (r *FriendRepository) UpdateFriendship(friendship *models.Friendship) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	friendship.UpdatedAt = time.Now()
+	r.s.friendships[friendship.ID] = *friendship
+	return nil
+}
+
+func (r *FriendRepository) DeleteFriendship(requesterID, addresseeID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, friendship := range r.s.friendships {
+		if between(friendship, requesterID, addresseeID) {
+			delete(r.s.friendships, id)
+		}
+	}
+	return nil
+}
+
+func (r *FriendRepository) GetUserFriends(userID uuid.UUID) ([]models.User, error) {
+	return r.friends(userID, func(uuid.UUID) bool { return true })
+}
+
+func (r *FriendRepository) GetPendingRequests(userID uuid.UUID) ([]models.Friendship, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var requests []models.Friendship
+	for _, friendship := range r.s.friendships {
+		if friendship.AddresseeID == userID && friendship.Status == models.FriendshipStatusPending {
+			friendship.Requester = r.s.users[friendship.RequesterID]
+			friendship.Addressee = r.s.users[friendship.AddresseeID]
+			requests = append(requests, friendship)
+		}
+	}
+	return requests, nil
+}
+
+func (r *FriendRepository) GetOnlineFriends(userID uuid.UUID) ([]models.OnlineUser, error) {
+	users, err := r.friends(userID, func(friendID uuid.UUID) bool {
+		online, ok := r.s.onlineUsers[friendID]
+		return ok && online.IsOnline && online.Status != models.PresenceInvisible
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	friends := make([]models.OnlineUser, 0, len(users))
+	for _, user := range users {
+		presence := r.s.onlineUsers[user.ID]
+		presence.User = user
+		friends = append(friends, presence)
+	}
+	return friends, nil
+}
+
+func (r *FriendRepository) GetBlockedIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var ids []uuid.UUID
+	for _, friendship := range r.s.friendships {
+		if friendship.Status != models.FriendshipStatusBlocked {
+			continue
+		}
+		switch userID {
+		case friendship.RequesterID:
+			ids = append(ids, friendship.AddresseeID)
+		case friendship.AddresseeID:
+			ids = append(ids, friendship.RequesterID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *FriendRepository) GetPresence(userID uuid.UUID) (*models.OnlineUser, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	presence, ok := r.s.onlineUsers[userID]
+	if !ok {
+		return notFound[models.OnlineUser]()
+	}
+	return &presence, nil
+}
+
+func (r *FriendRepository) SavePresence(presence *models.OnlineUser) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	presence.ID = ensureID(presence.ID)
+	stored := *presence
+	stored.User = models.User{}
+	r.s.onlineUsers[presence.UserID] = stored
+	return nil
+}
+
+func (r *FriendRepository) ResetPresence(keep []uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var reset int64
+	for userID, presence := range r.s.onlineUsers {
+		if presence.IsOnline && !containsUUID(keep, userID) {
+			presence.IsOnline = false
+			presence.LastSeen = time.Now()
+			r.s.onlineUsers[userID] = presence
+			reset++
+		}
+	}
+	return reset, nil
+}
+
+func (r *FriendRepository) friends(userID uuid.UUID, include func(uuid.UUID) bool) ([]models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var friends []models.User
+	for _, friendship := range r.s.friendships {
+		if friendship.Status != models.FriendshipStatusAccepted {
+			continue
+		}
+
+		var friendID uuid.UUID
+		switch userID {
+		case friendship.RequesterID:
+			friendID = friendship.AddresseeID
+		case friendship.AddresseeID:
+			friendID = friendship.RequesterID
+		default:
+			continue
+		}
+
+		if user, ok := r.s.users[friendID]; ok && include(friendID) {
+			friends = append(friends, user)
+		}
+	}
+	return friends, nil
+}
+
+func between(friendship models.Friendship, a, b uuid.UUID) bool {
+	return (friendship.RequesterID == a && friendship.AddresseeID == b) ||
+		(friendship.RequesterID == b && friendship.AddresseeID == a)
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type InventoryRepository struct {
+	s *Store
+}
+
+func (r *InventoryRepository) AddItem(item *models.Inventory) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, existing := range r.s.inventory {
+		if existing.UserID == item.UserID && existing.ItemName == item.ItemName {
+			existing.Quantity += item.Quantity
+			existing.UpdatedAt = time.Now()
+			r.s.inventory[id] = existing
+			return nil
+		}
+	}
+
+	item.ID = ensureID(item.ID)
+	item.CreatedAt, item.UpdatedAt = stamp(item.CreatedAt)
+	r.s.inventory[item.ID] = *item
+	return nil
+}
+
+func (r *InventoryRepository) GetUserItem(userID, itemID uuid.UUID) (*models.Inventory, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	item, ok := r.s.inventory[itemID]
+	if !ok || item.UserID != userID {
+		return notFound[models.Inventory]()
+	}
+	return &item, nil
+}
+
+func (r *InventoryRepository) GetUserItemForUpdate(userID, itemID uuid.UUID) (*models.Inventory, error) {
+	return r.GetUserItem(userID, itemID)
+}
+
+func (r *InventoryRepository) UpdateItem(item *models.Inventory) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	item.UpdatedAt = time.Now()
+	r.s.inventory[item.ID] = *item
+	return nil
+}
+
+func (r *InventoryRepository) RemoveItem(userID, itemID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if item, ok := r.s.inventory[itemID]; ok && item.UserID == userID {
+		delete(r.s.inventory, itemID)
+	}
+	return nil
+}
+
+func (r *InventoryRepository) GetUserInventory(userID uuid.UUID) ([]models.Inventory, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var items []models.Inventory
+	for _, item := range r.s.inventory {
+		if item.UserID == userID {
+			items = append(items, item)
+		}
+	}
+	byCreated(items, func(i models.Inventory) time.Time { return i.CreatedAt }, func(i models.Inventory) uuid.UUID { return i.ID })
+	return items, nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"sort"
+
+	"code-valley-api/internal/models"
+)
+
+type LeaderboardRepository struct {
+	s *Store
+}
+
+func (r *LeaderboardRepository) GetTopUsersByCoins(limit int) ([]models.User, error) {
+	return r.topUsers(limit, func(u models.User) int { return u.Coins })
+}
+
+func (r *LeaderboardRepository) GetTopUsersByEXP(limit int) ([]models.User, error) {
+	return r.topUsers(limit, func(u models.User) int { return u.EXP })
+}
+
+func (r *LeaderboardRepository) GetTopUsersByTasksCompleted(limit int) ([]models.UserStatistics, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	stats := make([]models.UserStatistics, 0, len(r.s.userStatistics))
+	for _, stat := range r.s.userStatistics {
+		stat.User = r.s.users[stat.UserID]
+		stats = append(stats, stat)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].TasksCompleted > stats[j].TasksCompleted
+	})
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+func (r *LeaderboardRepository) topUsers(limit int, score func(models.User) int) ([]models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	users := make([]models.User, 0, len(r.s.users))
+	for _, user := range r.s.users {
+		users = append(users, user)
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		return score(users[i]) > score(users[j])
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
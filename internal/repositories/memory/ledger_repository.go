@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"sort"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+type LedgerRepository struct {
+	s *Store
+}
+
+func (r *LedgerRepository) Post(entries []models.CoinLedgerEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.ID = ensureID(entry.ID)
+		entry.CreatedAt, _ = stamp(entry.CreatedAt)
+		r.s.coinLedger = append(r.s.coinLedger, entry)
+	}
+	return nil
+}
+
+func (r *LedgerRepository) GetBalance(userID uuid.UUID) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.s.walletBalances()[userID], nil
+}
+
+func (r *LedgerRepository) GetUserHistory(userID uuid.UUID, pagination utils.PaginationParams) ([]models.CoinLedgerEntry, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var entries []models.CoinLedgerEntry
+	for _, entry := range r.s.coinLedger {
+		if isWalletOf(entry, userID) {
+			entries = append(entries, entry)
+		}
+	}
+	// Newest first; the ledger is append-only so reversing insertion order
+	// keeps entries with equal timestamps stable
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return paginate(entries, pagination), int64(len(entries)), nil
+}
+
+func (r *LedgerRepository) GetDrift() ([]models.CoinDrift, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	balances := r.s.walletBalances()
+	var drift []models.CoinDrift
+	for _, user := range r.s.users {
+		if balance := balances[user.ID]; balance != user.Coins {
+			drift = append(drift, models.CoinDrift{
+				UserID:        user.ID,
+				Username:      user.Username,
+				CachedBalance: user.Coins,
+				LedgerBalance: balance,
+				Drift:         user.Coins - balance,
+			})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Username < drift[j].Username })
+	return drift, nil
+}
+
+func (r *LedgerRepository) GetUnbalancedTransactions() ([]uuid.UUID, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	sums := make(map[uuid.UUID]int)
+	var order []uuid.UUID
+	for _, entry := range r.s.coinLedger {
+		if _, ok := sums[entry.TransactionID]; !ok {
+			order = append(order, entry.TransactionID)
+		}
+		sums[entry.TransactionID] += entry.Amount
+	}
+
+	var unbalanced []uuid.UUID
+	for _, id := range order {
+		if sums[id] != 0 {
+			unbalanced = append(unbalanced, id)
+		}
+	}
+	return unbalanced, nil
+}
+
+// walletBalances sums wallet entries per user. Callers must hold s.mu.
+func (s *Store) walletBalances() map[uuid.UUID]int {
+	balances := make(map[uuid.UUID]int)
+	for _, entry := range s.coinLedger {
+		if entry.Account == models.LedgerAccountWallet && entry.UserID != nil {
+			balances[*entry.UserID] += entry.Amount
+		}
+	}
+	return balances
+}
+
+func isWalletOf(entry models.CoinLedgerEntry, userID uuid.UUID) bool {
+	return entry.Account == models.LedgerAccountWallet && entry.UserID != nil && *entry.UserID == userID
+}
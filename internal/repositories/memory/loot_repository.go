@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"sort"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LootRepository struct {
+	s *Store
+}
+
+func (r *LootRepository) GetAll() ([]models.LootTable, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	tables := make([]models.LootTable, 0, len(r.s.lootTables))
+	for _, table := range r.s.lootTables {
+		tables = append(tables, copyLootTable(table))
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables, nil
+}
+
+func (r *LootRepository) GetByName(name string) (*models.LootTable, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, table := range r.s.lootTables {
+		if table.Name == name {
+			table = copyLootTable(table)
+			return &table, nil
+		}
+	}
+	return notFound[models.LootTable]()
+}
+
+func (r *LootRepository) Create(table *models.LootTable) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.lootTables {
+		if existing.Name == table.Name {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	table.ID = ensureID(table.ID)
+	table.CreatedAt, table.UpdatedAt = stamp(table.CreatedAt)
+	r.s.lootTables[table.ID] = copyLootTable(*table)
+	return nil
+}
+
+func (r *LootRepository) Update(table *models.LootTable) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.lootTables {
+		if existing.Name == table.Name && existing.ID != table.ID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	table.CreatedAt, table.UpdatedAt = stamp(table.CreatedAt)
+	r.s.lootTables[table.ID] = copyLootTable(*table)
+	return nil
+}
+
+func (r *LootRepository) Delete(id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.lootTables, id)
+	return nil
+}
+
+// copyLootTable keeps callers from changing stored entries through the
+// shared slice.
+func copyLootTable(table models.LootTable) models.LootTable {
+	table.Entries = append(models.LootEntries(nil), table.Entries...)
+	return table
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"sort"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type NotificationRepository struct {
+	s *Store
+}
+
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	notification.ID = ensureID(notification.ID)
+	notification.CreatedAt, _ = stamp(notification.CreatedAt)
+	r.s.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (r *NotificationRepository) GetUserNotifications(userID uuid.UUID) ([]models.Notification, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var notifications []models.Notification
+	for _, notification := range r.s.notifications {
+		if notification.UserID == userID {
+			notifications = append(notifications, notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+	return notifications, nil
+}
+
+func (r *NotificationRepository) MarkAsRead(notificationID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if notification, ok := r.s.notifications[notificationID]; ok {
+		notification.IsRead = true
+		r.s.notifications[notificationID] = notification
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllAsRead(userID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, notification := range r.s.notifications {
+		if notification.UserID == userID {
+			notification.IsRead = true
+			r.s.notifications[id] = notification
+		}
+	}
+	return nil
+}
+
+func (r *NotificationRepository) GetUnreadCount(userID uuid.UUID) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var count int64
+	for _, notification := range r.s.notifications {
+		if notification.UserID == userID && !notification.IsRead {
+			count++
+		}
+	}
+	return count, nil
+}
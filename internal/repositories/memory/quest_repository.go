@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+type QuestRepository struct {
+	s *Store
+}
+
+func (r *QuestRepository) Create(quest *models.Quest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	quest.ID = ensureID(quest.ID)
+	quest.CreatedAt, quest.UpdatedAt = stamp(quest.CreatedAt)
+	r.s.quests[quest.ID] = *quest
+	return nil
+}
+
+func (r *QuestRepository) GetByID(id uuid.UUID) (*models.Quest, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	quest, ok := r.s.quests[id]
+	if !ok {
+		return notFound[models.Quest]()
+	}
+	return &quest, nil
+}
+
+func (r *QuestRepository) GetAll(pagination utils.PaginationParams) ([]models.Quest, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var active []models.Quest
+	for _, quest := range r.s.quests {
+		if quest.IsActive {
+			active = append(active, quest)
+		}
+	}
+	byCreated(active, func(q models.Quest) time.Time { return q.CreatedAt }, func(q models.Quest) uuid.UUID { return q.ID })
+	return paginate(active, pagination), int64(len(active)), nil
+}
+
+func (r *QuestRepository) Update(quest *models.Quest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	quest.UpdatedAt = time.Now()
+	r.s.quests[quest.ID] = *quest
+	return nil
+}
+
+func (r *QuestRepository) Delete(id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.quests, id)
+	return nil
+}
+
+func (r *QuestRepository) GetUserProgress(userID uuid.UUID, questID uuid.UUID) (*models.UserQuestProgress, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, progress := range r.s.questProgress {
+		if progress.UserID == userID && progress.QuestID == questID {
+			progress.Quest = r.s.quests[progress.QuestID]
+			return &progress, nil
+		}
+	}
+	return notFound[models.UserQuestProgress]()
+}
+
+func (r *QuestRepository) GetUserProgressForUpdate(userID uuid.UUID, questID uuid.UUID) (*models.UserQuestProgress, error) {
+	return r.GetUserProgress(userID, questID)
+}
+
+func (r *QuestRepository) CreateProgress(progress *models.UserQuestProgress) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	progress.ID = ensureID(progress.ID)
+	r.s.questProgress[progress.ID] = *progress
+	return nil
+}
+
+func (r *QuestRepository) UpdateProgress(progress *models.UserQuestProgress) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.questProgress[progress.ID] = *progress
+	return nil
+}
+
+func (r *QuestRepository) GetUserAllProgress(userID uuid.UUID) ([]models.UserQuestProgress, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var all []models.UserQuestProgress
+	for _, progress := range r.s.questProgress {
+		if progress.UserID == userID {
+			progress.Quest = r.s.quests[progress.QuestID]
+			all = append(all, progress)
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository struct {
+	s *Store
+}
+
+func (r *RefreshTokenRepository) Create(token *models.RefreshToken) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	token.ID = ensureID(token.ID)
+	token.CreatedAt, _ = stamp(token.CreatedAt)
+	r.s.refreshTokens[token.ID] = *token
+	return nil
+}
+
+func (r *RefreshTokenRepository) GetByHashForUpdate(hash string) (*models.RefreshToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, token := range r.s.refreshTokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return notFound[models.RefreshToken]()
+}
+
+func (r *RefreshTokenRepository) MarkUsed(id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if token, ok := r.s.refreshTokens[id]; ok {
+		now := time.Now()
+		token.UsedAt = &now
+		r.s.refreshTokens[id] = token
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.s.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			r.s.refreshTokens[id] = token
+		}
+	}
+	return nil
+}
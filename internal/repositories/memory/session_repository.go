@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type SessionRepository struct {
+	s *Store
+}
+
+func (r *SessionRepository) Create(session *models.Session) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	session.ID = ensureID(session.ID)
+	session.CreatedAt, _ = stamp(session.CreatedAt)
+	r.s.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *SessionRepository) GetByID(id uuid.UUID) (*models.Session, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	session, ok := r.s.sessions[id]
+	if !ok {
+		return notFound[models.Session]()
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) GetActiveByUser(userID uuid.UUID) ([]models.Session, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	now := time.Now()
+	var sessions []models.Session
+	for _, session := range r.s.sessions {
+		if session.UserID == userID && session.IsActive(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (r *SessionRepository) Update(session *models.Session) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.s.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *SessionRepository) Touch(id uuid.UUID, seenAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if session, ok := r.s.sessions[id]; ok {
+		session.LastSeenAt = seenAt
+		r.s.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *SessionRepository) Revoke(id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if session, ok := r.s.sessions[id]; ok && session.RevokedAt == nil {
+		now := time.Now()
+		session.RevokedAt = &now
+		r.s.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllForUser(userID uuid.UUID) ([]uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	var ids []uuid.UUID
+	for id, session := range r.s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			r.s.sessions[id] = session
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
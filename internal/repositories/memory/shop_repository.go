@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+type ShopRepository struct {
+	s *Store
+}
+
+func (r *ShopRepository) GetAllItems(pagination utils.PaginationParams) ([]models.ShopItem, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var available []models.ShopItem
+	for _, item := range r.s.shopItems {
+		if item.IsAvailable {
+			available = append(available, item)
+		}
+	}
+	byCreated(available, func(i models.ShopItem) time.Time { return i.CreatedAt }, func(i models.ShopItem) uuid.UUID { return i.ID })
+	return paginate(available, pagination), int64(len(available)), nil
+}
+
+func (r *ShopRepository) GetItemByID(id uuid.UUID) (*models.ShopItem, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	item, ok := r.s.shopItems[id]
+	if !ok || !item.IsAvailable {
+		return notFound[models.ShopItem]()
+	}
+	return &item, nil
+}
+
+func (r *ShopRepository) GetItemByIDForUpdate(id uuid.UUID) (*models.ShopItem, error) {
+	return r.GetItemByID(id)
+}
+
+func (r *ShopRepository) CreatePurchase(purchase *models.UserPurchase) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	purchase.ID = ensureID(purchase.ID)
+	r.s.purchases[purchase.ID] = *purchase
+	return nil
+}
+
+func (r *ShopRepository) UpdateItemStock(itemID uuid.UUID, newStock int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if item, ok := r.s.shopItems[itemID]; ok {
+		item.Stock = newStock
+		item.UpdatedAt = time.Now()
+		r.s.shopItems[itemID] = item
+	}
+	return nil
+}
+
+func (r *ShopRepository) GetUserPurchases(userID uuid.UUID) ([]models.UserPurchase, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var purchases []models.UserPurchase
+	for _, purchase := range r.s.purchases {
+		if purchase.UserID == userID {
+			purchase.ShopItem = r.s.shopItems[purchase.ShopItemID]
+			purchases = append(purchases, purchase)
+		}
+	}
+	return purchases, nil
+}
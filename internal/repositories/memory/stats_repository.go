@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type StatsRepository struct {
+	s *Store
+}
+
+func (r *StatsRepository) RecordActivity(userID uuid.UUID, at time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	found := false
+	for id, stats := range r.s.userStatistics {
+		if stats.UserID == userID {
+			stats.LastActive = at
+			r.s.userStatistics[id] = stats
+			found = true
+		}
+	}
+	if !found {
+		stats := models.UserStatistics{ID: uuid.New(), UserID: userID, LastActive: at}
+		stats.CreatedAt, stats.UpdatedAt = stamp(stats.CreatedAt)
+		r.s.userStatistics[stats.ID] = stats
+	}
+
+	day := models.ActivityDay(at)
+	for _, daily := range r.s.dailyStatistics {
+		if daily.UserID == userID && daily.Date.Equal(day) {
+			return nil
+		}
+	}
+	daily := models.DailyStatistics{ID: uuid.New(), UserID: userID, Date: day}
+	r.s.dailyStatistics[daily.ID] = daily
+	return nil
+}
+
+func (r *StatsRepository) CountUsers() (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.s.users)), nil
+}
+
+func (r *StatsRepository) CountActiveUsers(since time.Time) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var count int64
+	for _, stats := range r.s.userStatistics {
+		if !stats.LastActive.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *StatsRepository) CountQuests() (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.s.quests)), nil
+}
+
+func (r *StatsRepository) CountQuestCompletions() (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var count int64
+	for _, progress := range r.s.questProgress {
+		if progress.Status == models.QuestStatusCompleted {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *StatsRepository) CountGuilds() (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return int64(len(r.s.guilds)), nil
+}
+
+func (r *StatsRepository) GetEconomyTotals() (*models.EconomyTotals, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var totals models.EconomyTotals
+	for _, user := range r.s.users {
+		totals.CoinsInCirculation += int64(user.Coins)
+	}
+
+	transactions := make(map[uuid.UUID]bool)
+	for _, entry := range r.s.coinLedger {
+		if entry.Account != models.LedgerAccountWallet {
+			continue
+		}
+		if entry.Amount > 0 {
+			totals.CoinsEarned += int64(entry.Amount)
+		} else {
+			totals.CoinsSpent -= int64(entry.Amount)
+		}
+		transactions[entry.TransactionID] = true
+	}
+	totals.Transactions = int64(len(transactions))
+	return &totals, nil
+}
+
+func (r *StatsRepository) GetDailySeries(metric models.StatsMetric, since time.Time) ([]models.DailyCount, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	values := make(map[string]int64)
+	add := func(at time.Time, value int64) {
+		if !at.Before(since) {
+			values[at.UTC().Format("2006-01-02")] += value
+		}
+	}
+
+	switch metric {
+	case models.StatsMetricNewUsers:
+		for _, user := range r.s.users {
+			add(user.CreatedAt, 1)
+		}
+	case models.StatsMetricActiveUsers:
+		seen := make(map[string]bool)
+		for _, daily := range r.s.dailyStatistics {
+			key := daily.UserID.String() + daily.Date.Format("2006-01-02")
+			if !seen[key] {
+				seen[key] = true
+				add(daily.Date, 1)
+			}
+		}
+	case models.StatsMetricQuestCompletions:
+		for _, progress := range r.s.questProgress {
+			if progress.Status == models.QuestStatusCompleted && progress.CompletedAt != nil {
+				add(*progress.CompletedAt, 1)
+			}
+		}
+	case models.StatsMetricNewGuilds:
+		for _, guild := range r.s.guilds {
+			add(guild.CreatedAt, 1)
+		}
+	case models.StatsMetricCoinsEarned, models.StatsMetricCoinsSpent:
+		for _, entry := range r.s.coinLedger {
+			if entry.Account != models.LedgerAccountWallet {
+				continue
+			}
+			if metric == models.StatsMetricCoinsEarned && entry.Amount > 0 {
+				add(entry.CreatedAt, int64(entry.Amount))
+			}
+			if metric == models.StatsMetricCoinsSpent && entry.Amount < 0 {
+				add(entry.CreatedAt, -int64(entry.Amount))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown stats metric %q", metric)
+	}
+
+	series := make([]models.DailyCount, 0, len(values))
+	for day, value := range values {
+		series = append(series, models.DailyCount{Day: day, Value: value})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Day < series[j].Day })
+	return series, nil
+}
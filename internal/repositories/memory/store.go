@@ -0,0 +1,264 @@
+// Package memory provides in-memory implementations of the repository
+// interfaces so services can be exercised without a database.
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Store holds every table in memory. All repositories created from the same
+// store share its data, the same way GORM repositories share a database.
+type Store struct {
+	mu   sync.RWMutex
+	txMu sync.Mutex
+
+	users           map[uuid.UUID]models.User
+	userStatistics  map[uuid.UUID]models.UserStatistics
+	dailyStatistics map[uuid.UUID]models.DailyStatistics
+	onlineUsers     map[uuid.UUID]models.OnlineUser
+	quests          map[uuid.UUID]models.Quest
+	questProgress   map[uuid.UUID]models.UserQuestProgress
+	shopItems       map[uuid.UUID]models.ShopItem
+	purchases       map[uuid.UUID]models.UserPurchase
+	inventory       map[uuid.UUID]models.Inventory
+	friendships     map[uuid.UUID]models.Friendship
+	notifications   map[uuid.UUID]models.Notification
+	maps            map[uuid.UUID]models.Map
+	playerPositions map[uuid.UUID]models.PlayerPosition
+	worldObjects    map[uuid.UUID]models.WorldObject
+	npcs            map[uuid.UUID]models.NPC
+	npcPositions    map[uuid.UUID]models.NPCPosition
+	npcSchedules    map[uuid.UUID]models.NPCSchedule
+	codeFarms       map[uuid.UUID]models.CodeFarm
+	guilds          map[uuid.UUID]models.Guild
+	guildMembers    map[uuid.UUID]models.GuildMember
+	chatMessages    map[uuid.UUID]models.ChatMessage
+	chatMutes       map[uuid.UUID]models.ChatMute
+	coinLedger      []models.CoinLedgerEntry
+	auditLogs       []models.AuditLog
+	antiCheatEvents []models.AntiCheatEvent
+	lootTables      map[uuid.UUID]models.LootTable
+	refreshTokens   map[uuid.UUID]models.RefreshToken
+	sessions        map[uuid.UUID]models.Session
+	bans            map[uuid.UUID]models.UserBan
+	gameClock       *models.GameClock
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:           make(map[uuid.UUID]models.User),
+		userStatistics:  make(map[uuid.UUID]models.UserStatistics),
+		dailyStatistics: make(map[uuid.UUID]models.DailyStatistics),
+		onlineUsers:     make(map[uuid.UUID]models.OnlineUser),
+		quests:          make(map[uuid.UUID]models.Quest),
+		questProgress:   make(map[uuid.UUID]models.UserQuestProgress),
+		shopItems:       make(map[uuid.UUID]models.ShopItem),
+		purchases:       make(map[uuid.UUID]models.UserPurchase),
+		inventory:       make(map[uuid.UUID]models.Inventory),
+		friendships:     make(map[uuid.UUID]models.Friendship),
+		notifications:   make(map[uuid.UUID]models.Notification),
+		maps:            make(map[uuid.UUID]models.Map),
+		playerPositions: make(map[uuid.UUID]models.PlayerPosition),
+		worldObjects:    make(map[uuid.UUID]models.WorldObject),
+		npcs:            make(map[uuid.UUID]models.NPC),
+		npcPositions:    make(map[uuid.UUID]models.NPCPosition),
+		npcSchedules:    make(map[uuid.UUID]models.NPCSchedule),
+		codeFarms:       make(map[uuid.UUID]models.CodeFarm),
+		guilds:          make(map[uuid.UUID]models.Guild),
+		guildMembers:    make(map[uuid.UUID]models.GuildMember),
+		chatMessages:    make(map[uuid.UUID]models.ChatMessage),
+		chatMutes:       make(map[uuid.UUID]models.ChatMute),
+		refreshTokens:   make(map[uuid.UUID]models.RefreshToken),
+		sessions:        make(map[uuid.UUID]models.Session),
+		bans:            make(map[uuid.UUID]models.UserBan),
+		lootTables:      make(map[uuid.UUID]models.LootTable),
+	}
+}
+
+// New returns a fresh store wrapped as a full set of repositories.
+func New() *repositories.Repositories {
+	return NewStore().Repositories()
+}
+
+// Repositories returns every repository backed by this store.
+func (s *Store) Repositories() *repositories.Repositories {
+	return &repositories.Repositories{
+		Users:         &UserRepository{s},
+		Quests:        &QuestRepository{s},
+		Shop:          &ShopRepository{s},
+		Inventory:     &InventoryRepository{s},
+		Friends:       &FriendRepository{s},
+		Notifications: &NotificationRepository{s},
+		Leaderboard:   &LeaderboardRepository{s},
+		World:         &WorldRepository{s},
+		Ledger:        &LedgerRepository{s},
+		RefreshTokens: &RefreshTokenRepository{s},
+		Sessions:      &SessionRepository{s},
+		Bans:          &BanRepository{s},
+		AuditLogs:     &AuditLogRepository{s},
+		Stats:         &StatsRepository{s},
+		Chat:          &ChatRepository{s},
+		AntiCheat:     &AntiCheatRepository{s},
+		Loot:          &LootRepository{s},
+	}
+}
+
+// Seeding helpers for tables that have no create method on the interfaces.
+
+func (s *Store) AddShopItem(item models.ShopItem) models.ShopItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item.ID = ensureID(item.ID)
+	item.CreatedAt, item.UpdatedAt = stamp(item.CreatedAt)
+	s.shopItems[item.ID] = item
+	return item
+}
+
+func (s *Store) AddUserStatistics(stats models.UserStatistics) models.UserStatistics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats.ID = ensureID(stats.ID)
+	stats.CreatedAt, stats.UpdatedAt = stamp(stats.CreatedAt)
+	s.userStatistics[stats.ID] = stats
+	return stats
+}
+
+func (s *Store) AddGuild(guild models.Guild) models.Guild {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	guild.ID = ensureID(guild.ID)
+	guild.CreatedAt, guild.UpdatedAt = stamp(guild.CreatedAt)
+	s.guilds[guild.ID] = guild
+	return guild
+}
+
+func (s *Store) AddGuildMember(member models.GuildMember) models.GuildMember {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member.ID = ensureID(member.ID)
+	if member.JoinedAt.IsZero() {
+		member.JoinedAt = time.Now()
+	}
+	s.guildMembers[member.ID] = member
+	return member
+}
+
+func (s *Store) SetOnline(userID uuid.UUID, online bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.onlineUsers[userID]
+	if !ok {
+		entry = models.OnlineUser{ID: uuid.New(), UserID: userID}
+	}
+	entry.IsOnline = online
+	entry.LastSeen = time.Now()
+	s.onlineUsers[userID] = entry
+}
+
+func (s *Store) AddMap(m models.Map) models.Map {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m.ID = ensureID(m.ID)
+	m.CreatedAt, m.UpdatedAt = stamp(m.CreatedAt)
+	s.maps[m.ID] = m
+	return m
+}
+
+func (s *Store) AddWorldObject(obj models.WorldObject) models.WorldObject {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj.ID = ensureID(obj.ID)
+	obj.CreatedAt, obj.UpdatedAt = stamp(obj.CreatedAt)
+	if obj.State == nil {
+		obj.State = make(models.ObjectState)
+	}
+	s.worldObjects[obj.ID] = obj
+	return obj
+}
+
+func (s *Store) AddNPC(npc models.NPC) models.NPC {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	npc.ID = ensureID(npc.ID)
+	npc.CreatedAt, npc.UpdatedAt = stamp(npc.CreatedAt)
+	s.npcs[npc.ID] = npc
+	return npc
+}
+
+func (s *Store) AddNPCPosition(position models.NPCPosition) models.NPCPosition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	position.ID = ensureID(position.ID)
+	position.UpdatedAt = time.Now()
+	s.npcPositions[position.ID] = position
+	return position
+}
+
+func (s *Store) AddNPCSchedule(schedule models.NPCSchedule) models.NPCSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedule.ID = ensureID(schedule.ID)
+	schedule.CreatedAt, _ = stamp(schedule.CreatedAt)
+	s.npcSchedules[schedule.ID] = schedule
+	return schedule
+}
+
+func ensureID(id uuid.UUID) uuid.UUID {
+	if id == uuid.Nil {
+		return uuid.New()
+	}
+	return id
+}
+
+// stamp mimics GORM's CreatedAt/UpdatedAt handling on insert.
+func stamp(createdAt time.Time) (time.Time, time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+	return createdAt, now
+}
+
+// byCreated sorts rows oldest first, breaking ties on ID so results are stable.
+func byCreated[T any](rows []T, created func(T) time.Time, id func(T) uuid.UUID) {
+	sort.Slice(rows, func(i, j int) bool {
+		ci, cj := created(rows[i]), created(rows[j])
+		if !ci.Equal(cj) {
+			return ci.Before(cj)
+		}
+		return id(rows[i]).String() < id(rows[j]).String()
+	})
+}
+
+func paginate[T any](rows []T, pagination utils.PaginationParams) []T {
+	if pagination.Offset >= len(rows) {
+		return []T{}
+	}
+	end := pagination.Offset + pagination.PerPage
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows[pagination.Offset:end]
+}
+
+func notFound[T any]() (*T, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
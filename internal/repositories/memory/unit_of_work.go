@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+)
+
+// UnitOfWork serialises units of work against a Store and restores the
+// previous contents of the store when one fails.
+type UnitOfWork struct {
+	s *Store
+}
+
+// UnitOfWork returns a unit of work backed by this store.
+func (s *Store) UnitOfWork() repositories.UnitOfWork {
+	return &UnitOfWork{s}
+}
+
+func (u *UnitOfWork) Do(fn func(repos *repositories.Repositories) error) error {
+	u.s.txMu.Lock()
+	defer u.s.txMu.Unlock()
+
+	snapshot := u.s.snapshot()
+	if err := fn(u.s.Repositories()); err != nil {
+		u.s.restore(snapshot)
+		return err
+	}
+	return nil
+}
+
+// snapshot copies every table. Rows are stored by value, so copying the maps
+// is enough to undo any writes made afterwards.
+func (s *Store) snapshot() *Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	copied := &Store{
+		users:           cloneTable(s.users),
+		userStatistics:  cloneTable(s.userStatistics),
+		dailyStatistics: cloneTable(s.dailyStatistics),
+		onlineUsers:     cloneTable(s.onlineUsers),
+		quests:          cloneTable(s.quests),
+		questProgress:   cloneTable(s.questProgress),
+		shopItems:       cloneTable(s.shopItems),
+		purchases:       cloneTable(s.purchases),
+		inventory:       cloneTable(s.inventory),
+		friendships:     cloneTable(s.friendships),
+		notifications:   cloneTable(s.notifications),
+		maps:            cloneTable(s.maps),
+		playerPositions: cloneTable(s.playerPositions),
+		worldObjects:    cloneTable(s.worldObjects),
+		npcs:            cloneTable(s.npcs),
+		npcPositions:    cloneTable(s.npcPositions),
+		npcSchedules:    cloneTable(s.npcSchedules),
+		codeFarms:       cloneTable(s.codeFarms),
+		guilds:          cloneTable(s.guilds),
+		guildMembers:    cloneTable(s.guildMembers),
+		chatMessages:    cloneTable(s.chatMessages),
+		chatMutes:       cloneTable(s.chatMutes),
+		coinLedger:      append([]models.CoinLedgerEntry(nil), s.coinLedger...),
+		auditLogs:       append([]models.AuditLog(nil), s.auditLogs...),
+		antiCheatEvents: append([]models.AntiCheatEvent(nil), s.antiCheatEvents...),
+		refreshTokens:   cloneTable(s.refreshTokens),
+		sessions:        cloneTable(s.sessions),
+		bans:            cloneTable(s.bans),
+		lootTables:      cloneTable(s.lootTables),
+	}
+	if s.gameClock != nil {
+		clock := *s.gameClock
+		copied.gameClock = &clock
+	}
+	return copied
+}
+
+func (s *Store) restore(from *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = from.users
+	s.userStatistics = from.userStatistics
+	s.dailyStatistics = from.dailyStatistics
+	s.onlineUsers = from.onlineUsers
+	s.quests = from.quests
+	s.questProgress = from.questProgress
+	s.shopItems = from.shopItems
+	s.purchases = from.purchases
+	s.inventory = from.inventory
+	s.friendships = from.friendships
+	s.notifications = from.notifications
+	s.maps = from.maps
+	s.playerPositions = from.playerPositions
+	s.worldObjects = from.worldObjects
+	s.npcs = from.npcs
+	s.npcPositions = from.npcPositions
+	s.npcSchedules = from.npcSchedules
+	s.codeFarms = from.codeFarms
+	s.guilds = from.guilds
+	s.guildMembers = from.guildMembers
+	s.chatMessages = from.chatMessages
+	s.chatMutes = from.chatMutes
+	s.coinLedger = from.coinLedger
+	s.auditLogs = from.auditLogs
+	s.antiCheatEvents = from.antiCheatEvents
+	s.refreshTokens = from.refreshTokens
+	s.sessions = from.sessions
+	s.bans = from.bans
+	s.lootTables = from.lootTables
+	s.gameClock = from.gameClock
+}
+
+func cloneTable[K comparable, V any](table map[K]V) map[K]V {
+	copied := make(map[K]V, len(table))
+	for k, v := range table {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+)
+
+func TestUnitOfWorkRollsBackOnError(t *testing.T) {
+	store := NewStore()
+	repos := store.Repositories()
+	user := &models.User{Email: "a@example.com", Username: "a", Coins: 100}
+	if err := repos.Users.Create(user); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+
+	failed := errors.New("failed")
+	err := store.UnitOfWork().Do(func(tx *repositories.Repositories) error {
+		if err := tx.Users.UpdateCoins(user.ID, 40); err != nil {
+			return err
+		}
+		if err := tx.Inventory.AddItem(&models.Inventory{UserID: user.ID, ItemName: "Debug Tool", Quantity: 1}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Do: got %v, want the error fn returned", err)
+	}
+
+	got, _ := repos.Users.GetByID(user.ID)
+	if got.Coins != 100 {
+		t.Errorf("coins %d after rollback, want 100", got.Coins)
+	}
+	if items, _ := repos.Inventory.GetUserInventory(user.ID); len(items) != 0 {
+		t.Errorf("inventory %+v after rollback, want it empty", items)
+	}
+}
+
+func TestUnitOfWorkKeepsWritesOnSuccess(t *testing.T) {
+	store := NewStore()
+	repos := store.Repositories()
+	user := &models.User{Email: "a@example.com", Username: "a", Coins: 100}
+	if err := repos.Users.Create(user); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+
+	err := store.UnitOfWork().Do(func(tx *repositories.Repositories) error {
+		return tx.Users.UpdateCoins(user.ID, 40)
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if got, _ := repos.Users.GetByID(user.ID); got.Coins != 40 {
+		t.Errorf("coins %d, want 40", got.Coins)
+	}
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserRepository struct {
+	s *Store
+}
+
+func (r *UserRepository) Create(user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.users {
+		if existing.Email == user.Email || existing.Username == user.Username {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	// Mirror the column defaults GORM leaves to the database
+	user.ID = ensureID(user.ID)
+	if user.Coins == 0 {
+		user.Coins = 100
+	}
+	if user.Level == 0 {
+		user.Level = 1
+	}
+	if user.Role == "" {
+		user.Role = models.RolePlayer
+	}
+	user.CreatedAt, user.UpdatedAt = stamp(user.CreatedAt)
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	user, ok := r.s.users[id]
+	if !ok {
+		return notFound[models.User]()
+	}
+	return &user, nil
+}
+
+// GetByIDForUpdate needs no row lock: UnitOfWork already runs one
+// transaction at a time.
+func (r *UserRepository) GetByIDForUpdate(id uuid.UUID) (*models.User, error) {
+	return r.GetByID(id)
+}
+
+func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
+	return r.find(func(u models.User) bool { return u.Email == email })
+}
+
+func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
+	return r.find(func(u models.User) bool { return u.Username == username })
+}
+
+func (r *UserRepository) find(match func(models.User) bool) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, user := range r.s.users {
+		if match(user) {
+			return &user, nil
+		}
+	}
+	return notFound[models.User]()
+}
+
+func (r *UserRepository) Update(user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user.UpdatedAt = time.Now()
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) GetAll(pagination utils.PaginationParams) ([]models.User, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return pageUsers(r.s.users, pagination)
+}
+
+func pageUsers(users map[uuid.UUID]models.User, pagination utils.PaginationParams) ([]models.User, int64, error) {
+	all := make([]models.User, 0, len(users))
+	for _, user := range users {
+		all = append(all, user)
+	}
+	byCreated(all, func(u models.User) time.Time { return u.CreatedAt }, func(u models.User) uuid.UUID { return u.ID })
+	return paginate(all, pagination), int64(len(all)), nil
+}
+
+func (r *UserRepository) UpdateEXPAndLevel(userID uuid.UUID, exp, level int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if user, ok := r.s.users[userID]; ok {
+		user.EXP = exp
+		user.Level = level
+		user.UpdatedAt = time.Now()
+		r.s.users[userID] = user
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdateCoins(userID uuid.UUID, coins int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if user, ok := r.s.users[userID]; ok {
+		user.Coins = coins
+		user.UpdatedAt = time.Now()
+		r.s.users[userID] = user
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package memory
+
+import (
+	"encoding/json"
+	"time"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type WorldRepository struct {
+	s *Store
+}
+
+// Map operations
+func (r *WorldRepository) GetMapByName(name string) (*models.Map, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, m := range r.s.maps {
+		if m.Name == name && m.IsActive {
+			return &m, nil
+		}
+	}
+	return notFound[models.Map]()
+}
+
+func (r *WorldRepository) GetMapByID(id uuid.UUID) (*models.Map, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	m, ok := r.s.maps[id]
+	if !ok {
+		return notFound[models.Map]()
+	}
+	return &m, nil
+}
+
+func (r *WorldRepository) CreateMap(mapData *models.Map) error {
+	// Mirrors the Map.BeforeSave hook
+	if err := mapData.Layout.Validate(mapData.Width, mapData.Height); err != nil {
+		return err
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	mapData.ID = ensureID(mapData.ID)
+	mapData.CreatedAt, mapData.UpdatedAt = stamp(mapData.CreatedAt)
+	r.s.maps[mapData.ID] = *mapData
+	return nil
+}
+
+func (r *WorldRepository) UpdateMap(mapData *models.Map) error {
+	// Mirrors the Map.BeforeSave hook
+	if err := mapData.Layout.Validate(mapData.Width, mapData.Height); err != nil {
+		return err
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	mapData.UpdatedAt = time.Now()
+	r.s.maps[mapData.ID] = *mapData
+	return nil
+}
+
+// Player position operations
+func (r *WorldRepository) GetPlayerPosition(userID uuid.UUID) (*models.PlayerPosition, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	position, ok := r.s.playerPositions[userID]
+	if !ok {
+		return notFound[models.PlayerPosition]()
+	}
+	position.Map = r.s.maps[position.MapID]
+	return &position, nil
+}
+
+func (r *WorldRepository) GetPlayerPositionForUpdate(userID uuid.UUID) (*models.PlayerPosition, error) {
+	return r.GetPlayerPosition(userID)
+}
+
+func (r *WorldRepository) CreatePlayerPosition(position *models.PlayerPosition) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	position.ID = ensureID(position.ID)
+	position.UpdatedAt = time.Now()
+	r.s.playerPositions[position.UserID] = *position
+	return nil
+}
+
+func (r *WorldRepository) UpdatePlayerPosition(position *models.PlayerPosition) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	position.UpdatedAt = time.Now()
+	r.s.playerPositions[position.UserID] = *position
+	return nil
+}
+
+func (r *WorldRepository) GetPlayersInMap(mapID uuid.UUID) ([]models.PlayerPosition, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var positions []models.PlayerPosition
+	for _, position := range r.s.playerPositions {
+		if position.MapID == mapID {
+			position.User = r.s.users[position.UserID]
+			positions = append(positions, position)
+		}
+	}
+	return positions, nil
+}
+
+// World object operations
+func (r *WorldRepository) GetWorldObjects(mapID uuid.UUID) ([]models.WorldObject, error) {
+	return r.objects(func(obj models.WorldObject) bool {
+		return obj.MapID == mapID && obj.IsActive
+	})
+}
+
+func (r *WorldRepository) GetWorldObjectsAt(mapID uuid.UUID, posX, posY int) ([]models.WorldObject, error) {
+	return r.objects(func(obj models.WorldObject) bool {
+		return obj.MapID == mapID && obj.PosX == posX && obj.PosY == posY && obj.IsActive
+	})
+}
+
+func (r *WorldRepository) GetAllWorldObjects(mapID uuid.UUID) ([]models.WorldObject, error) {
+	return r.objects(func(obj models.WorldObject) bool {
+		return obj.MapID == mapID
+	})
+}
+
+func (r *WorldRepository) GetWorldObjectsByType(types []models.ObjectType) ([]models.WorldObject, error) {
+	return r.objects(func(obj models.WorldObject) bool {
+		for _, objectType := range types {
+			if obj.ObjectType == objectType {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (r *WorldRepository) objects(match func(models.WorldObject) bool) ([]models.WorldObject, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var objects []models.WorldObject
+	for _, obj := range r.s.worldObjects {
+		if match(obj) {
+			obj.State = copyState(obj.State)
+			objects = append(objects, obj)
+		}
+	}
+	byCreated(objects, func(o models.WorldObject) time.Time { return o.CreatedAt }, func(o models.WorldObject) uuid.UUID { return o.ID })
+	return objects, nil
+}
+
+func (r *WorldRepository) GetWorldObjectForUpdate(id uuid.UUID) (*models.WorldObject, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	obj, ok := r.s.worldObjects[id]
+	if !ok {
+		return notFound[models.WorldObject]()
+	}
+	obj.State = copyState(obj.State)
+	return &obj, nil
+}
+
+func (r *WorldRepository) CreateWorldObject(obj *models.WorldObject) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	obj.ID = ensureID(obj.ID)
+	obj.CreatedAt, obj.UpdatedAt = stamp(obj.CreatedAt)
+	stored := *obj
+	stored.State = copyState(obj.State)
+	r.s.worldObjects[obj.ID] = stored
+	return nil
+}
+
+func (r *WorldRepository) UpdateWorldObject(obj *models.WorldObject) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := *obj
+	stored.State = copyState(obj.State)
+	stored.UpdatedAt = time.Now()
+	r.s.worldObjects[obj.ID] = stored
+	return nil
+}
+
+func (r *WorldRepository) DeleteWorldObject(id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.worldObjects, id)
+	return nil
+}
+
+// NPC position operations
+func (r *WorldRepository) GetNPCByName(name string) (*models.NPC, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, npc := range r.s.npcs {
+		if npc.Name == name {
+			return &npc, nil
+		}
+	}
+	return notFound[models.NPC]()
+}
+
+func (r *WorldRepository) GetNPCPosition(npcID uuid.UUID) (*models.NPCPosition, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, position := range r.s.npcPositions {
+		if position.NPCID == npcID {
+			return &position, nil
+		}
+	}
+	return notFound[models.NPCPosition]()
+}
+
+func (r *WorldRepository) GetNPCPositions(mapID uuid.UUID) ([]models.NPCPosition, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var positions []models.NPCPosition
+	for _, position := range r.s.npcPositions {
+		if position.MapID == mapID {
+			position.NPC = r.s.npcs[position.NPCID]
+			positions = append(positions, position)
+		}
+	}
+	return positions, nil
+}
+
+func (r *WorldRepository) CreateNPCPosition(position *models.NPCPosition) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	position.ID = ensureID(position.ID)
+	position.UpdatedAt = time.Now()
+	r.s.npcPositions[position.ID] = *position
+	return nil
+}
+
+func (r *WorldRepository) UpdateNPCPosition(position *models.NPCPosition) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	position.UpdatedAt = time.Now()
+	r.s.npcPositions[position.ID] = *position
+	return nil
+}
+
+func (r *WorldRepository) GetNPCSchedules(npcID uuid.UUID) ([]models.NPCSchedule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var schedules []models.NPCSchedule
+	for _, schedule := range r.s.npcSchedules {
+		if schedule.NPCID == npcID {
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
+
+func (r *WorldRepository) GetActiveNPCSchedules() ([]models.NPCSchedule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var schedules []models.NPCSchedule
+	for _, schedule := range r.s.npcSchedules {
+		if r.s.npcs[schedule.NPCID].IsActive {
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
+
+// Game clock operations
+func (r *WorldRepository) GetGameClock() (*models.GameClock, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.gameClock == nil {
+		r.s.gameClock = &models.GameClock{
+			ID:         uuid.New(),
+			GameYear:   1,
+			GameSeason: "spring",
+			GameDay:    1,
+			GameHour:   6,
+			GameMinute: 0,
+			TimeScale:  1.0,
+		}
+	}
+	clock := *r.s.gameClock
+	return &clock, nil
+}
+
+func (r *WorldRepository) UpdateGameClock(clock *models.GameClock) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := *clock
+	r.s.gameClock = &stored
+	return nil
+}
+
+// Code farm operations
+func (r *WorldRepository) GetUserCodeFarms(userID uuid.UUID) ([]models.CodeFarm, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var farms []models.CodeFarm
+	for _, farm := range r.s.codeFarms {
+		if farm.UserID == userID {
+			farms = append(farms, farm)
+		}
+	}
+	byCreated(farms, func(f models.CodeFarm) time.Time { return f.CreatedAt }, func(f models.CodeFarm) uuid.UUID { return f.ID })
+	return farms, nil
+}
+
+func (r *WorldRepository) GetCodeFarm(farmID uuid.UUID) (*models.CodeFarm, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	farm, ok := r.s.codeFarms[farmID]
+	if !ok {
+		return notFound[models.CodeFarm]()
+	}
+	return &farm, nil
+}
+
+func (r *WorldRepository) GetCodeFarmForUpdate(farmID uuid.UUID) (*models.CodeFarm, error) {
+	return r.GetCodeFarm(farmID)
+}
+
+func (r *WorldRepository) GetCodeFarmAt(userID uuid.UUID, plotX, plotY int) (*models.CodeFarm, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, farm := range r.s.codeFarms {
+		if farm.UserID == userID && farm.PlotX == plotX && farm.PlotY == plotY {
+			return &farm, nil
+		}
+	}
+	return notFound[models.CodeFarm]()
+}
+
+func (r *WorldRepository) CreateCodeFarm(farm *models.CodeFarm) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	farm.ID = ensureID(farm.ID)
+	farm.CreatedAt, farm.UpdatedAt = stamp(farm.CreatedAt)
+	r.s.codeFarms[farm.ID] = *farm
+	return nil
+}
+
+func (r *WorldRepository) UpdateCodeFarm(farm *models.CodeFarm) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	farm.UpdatedAt = time.Now()
+	r.s.codeFarms[farm.ID] = *farm
+	return nil
+}
+
+func (r *WorldRepository) DeleteCodeFarm(farmID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.codeFarms, farmID)
+	return nil
+}
+
+// copyState round-trips object state through JSON, like a database column
+// would, so callers cannot mutate stored rows and numbers come back as float64.
+func copyState(state models.ObjectState) models.ObjectState {
+	cloned := make(models.ObjectState)
+	if data, err := json.Marshal(state); err == nil {
+		json.Unmarshal(data, &cloned)
+	}
+	return cloned
+}
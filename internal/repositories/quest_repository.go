@@ -1,7 +1,6 @@
 package repositories
 
 import (
-	"code-valley-api/internal/database"
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/utils"
 
@@ -9,21 +8,34 @@ import (
 	"gorm.io/gorm"
 )
 
-type QuestRepository struct {
+type QuestRepository interface {
+	Create(quest *models.Quest) error
+	GetByID(id uuid.UUID) (*models.Quest, error)
+	GetAll(pagination utils.PaginationParams) ([]models.Quest, int64, error)
+	Update(quest *models.Quest) error
+	Delete(id uuid.UUID) error
+	GetUserProgress(userID uuid.UUID, questID uuid.UUID) (*models.UserQuestProgress, error)
+	GetUserProgressForUpdate(userID uuid.UUID, questID uuid.UUID) (*models.UserQuestProgress, error)
+	CreateProgress(progress *models.UserQuestProgress) error
+	UpdateProgress(progress *models.UserQuestProgress) error
+	GetUserAllProgress(userID uuid.UUID) ([]models.UserQuestProgress, error)
+}
+
+type questRepository struct {
 	db *gorm.DB
 }
 
-func NewQuestRepository() *QuestRepository {
-	return &QuestRepository{
-		db: database.GetDB(),
+func NewQuestRepository(db *gorm.DB) QuestRepository {
+	return &questRepository{
+		db: db,
 	}
 }
 
-func (r *QuestRepository) Create(quest *models.Quest) error {
+func (r *questRepository) Create(quest *models.Quest) error {
 	return r.db.Create(quest).Error
 }
 
-func (r *QuestRepository) GetByID(id uuid.UUID) (*models.Quest, error) {
+func (r *questRepository) GetByID(id uuid.UUID) (*models.Quest, error) {
 	var quest models.Quest
 	err := r.db.First(&quest, "id = ?", id).Error
 	if err != nil {
@@ -32,7 +44,7 @@ func (r *QuestRepository) GetByID(id uuid.UUID) (*models.Quest, error) {
 	return &quest, nil
 }
 
-func (r *QuestRepository) GetAll(pagination utils.PaginationParams) ([]models.Quest, int64, error) {
+func (r *questRepository) GetAll(pagination utils.PaginationParams) ([]models.Quest, int64, error) {
 	var quests []models.Quest
 	var total int64
 
@@ -47,15 +59,15 @@ func (r *QuestRepository) GetAll(pagination utils.PaginationParams) ([]models.Qu
 	return quests, total, err
 }
 
-func (r *QuestRepository) Update(quest *models.Quest) error {
+func (r *questRepository) Update(quest *models.Quest) error {
 	return r.db.Save(quest).Error
 }
 
-func (r *QuestRepository) Delete(id uuid.UUID) error {
+func (r *questRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.Quest{}, "id = ?", id).Error
 }
 
-func (r *QuestRepository) GetUserProgress(userID uuid.UUID, questID uuid.UUID) (*models.UserQuestProgress, error) {
+func (r *questRepository) GetUserProgress(userID uuid.UUID, questID uuid.UUID) (*models.UserQuestProgress, error) {
 	var progress models.UserQuestProgress
 	err := r.db.Preload("Quest").
 		First(&progress, "user_id = ? AND quest_id = ?", userID, questID).Error
@@ -65,15 +77,25 @@ func (r *QuestRepository) GetUserProgress(userID uuid.UUID, questID uuid.UUID) (
 	return &progress, nil
 }
 
-func (r *QuestRepository) CreateProgress(progress *models.UserQuestProgress) error {
+func (r *questRepository) GetUserProgressForUpdate(userID uuid.UUID, questID uuid.UUID) (*models.UserQuestProgress, error) {
+	var progress models.UserQuestProgress
+	err := forUpdate(r.db).
+		First(&progress, "user_id = ? AND quest_id = ?", userID, questID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (r *questRepository) CreateProgress(progress *models.UserQuestProgress) error {
 	return r.db.Create(progress).Error
 }
 
-func (r *QuestRepository) UpdateProgress(progress *models.UserQuestProgress) error {
+func (r *questRepository) UpdateProgress(progress *models.UserQuestProgress) error {
 	return r.db.Save(progress).Error
 }
 
-func (r *QuestRepository) GetUserAllProgress(userID uuid.UUID) ([]models.UserQuestProgress, error) {
+func (r *questRepository) GetUserAllProgress(userID uuid.UUID) ([]models.UserQuestProgress, error) {
 	var progress []models.UserQuestProgress
 	err := r.db.Preload("Quest").
 		Find(&progress, "user_id = ?", userID).Error
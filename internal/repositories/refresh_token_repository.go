@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"time"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	GetByHashForUpdate(hash string) (*models.RefreshToken, error)
+	MarkUsed(id uuid.UUID) error
+	RevokeFamily(familyID uuid.UUID) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{
+		db: db,
+	}
+}
+
+func (r *refreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *refreshTokenRepository) GetByHashForUpdate(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := forUpdate(r.db).First(&token, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) MarkUsed(id uuid.UUID) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("id = ?", id).
+		Update("used_at", time.Now()).Error
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
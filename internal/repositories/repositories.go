@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+// Repositories bundles every repository so services can be wired once.
+type Repositories struct {
+	Users         UserRepository
+	Quests        QuestRepository
+	Shop          ShopRepository
+	Inventory     InventoryRepository
+	Friends       FriendRepository
+	Notifications NotificationRepository
+	Leaderboard   LeaderboardRepository
+	World         WorldRepository
+	Ledger        LedgerRepository
+	RefreshTokens RefreshTokenRepository
+	Sessions      SessionRepository
+	Bans          BanRepository
+	AuditLogs     AuditLogRepository
+	Stats         StatsRepository
+	Chat          ChatRepository
+	AntiCheat     AntiCheatRepository
+	Loot          LootRepository
+}
+
+// New builds the GORM-backed repositories on top of db.
+func New(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:         NewUserRepository(db),
+		Quests:        NewQuestRepository(db),
+		Shop:          NewShopRepository(db),
+		Inventory:     NewInventoryRepository(db),
+		Friends:       NewFriendRepository(db),
+		Notifications: NewNotificationRepository(db),
+		Leaderboard:   NewLeaderboardRepository(db),
+		World:         NewWorldRepository(db),
+		Ledger:        NewLedgerRepository(db),
+		RefreshTokens: NewRefreshTokenRepository(db),
+		Sessions:      NewSessionRepository(db),
+		Bans:          NewBanRepository(db),
+		AuditLogs:     NewAuditLogRepository(db),
+		Stats:         NewStatsRepository(db),
+		Chat:          NewChatRepository(db),
+		AntiCheat:     NewAntiCheatRepository(db),
+		Loot:          NewLootRepository(db),
+	}
+}
@@ -1,7 +1,6 @@
 package repositories
 
 import (
-	"code-valley-api/internal/database"
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/utils"
 
@@ -9,17 +8,26 @@ import (
 	"gorm.io/gorm"
 )
 
-type ShopRepository struct {
+type ShopRepository interface {
+	GetAllItems(pagination utils.PaginationParams) ([]models.ShopItem, int64, error)
+	GetItemByID(id uuid.UUID) (*models.ShopItem, error)
+	GetItemByIDForUpdate(id uuid.UUID) (*models.ShopItem, error)
+	CreatePurchase(purchase *models.UserPurchase) error
+	UpdateItemStock(itemID uuid.UUID, newStock int) error
+	GetUserPurchases(userID uuid.UUID) ([]models.UserPurchase, error)
+}
+
+type shopRepository struct {
 	db *gorm.DB
 }
 
-func NewShopRepository() *ShopRepository {
-	return &ShopRepository{
-		db: database.GetDB(),
+func NewShopRepository(db *gorm.DB) ShopRepository {
+	return &shopRepository{
+		db: db,
 	}
 }
 
-func (r *ShopRepository) GetAllItems(pagination utils.PaginationParams) ([]models.ShopItem, int64, error) {
+func (r *shopRepository) GetAllItems(pagination utils.PaginationParams) ([]models.ShopItem, int64, error) {
 	var items []models.ShopItem
 	var total int64
 
@@ -33,21 +41,27 @@ func (r *ShopRepository) GetAllItems(pagination utils.PaginationParams) ([]model
 	return items, total, err
 }
 
-func (r *ShopRepository) GetItemByID(id uuid.UUID) (*models.ShopItem, error) {
+func (r *shopRepository) GetItemByID(id uuid.UUID) (*models.ShopItem, error) {
 	var item models.ShopItem
 	err := r.db.First(&item, "id = ? AND is_available = ?", id, true).Error
 	return &item, err
 }
 
-func (r *ShopRepository) CreatePurchase(purchase *models.UserPurchase) error {
+func (r *shopRepository) GetItemByIDForUpdate(id uuid.UUID) (*models.ShopItem, error) {
+	var item models.ShopItem
+	err := forUpdate(r.db).First(&item, "id = ? AND is_available = ?", id, true).Error
+	return &item, err
+}
+
+func (r *shopRepository) CreatePurchase(purchase *models.UserPurchase) error {
 	return r.db.Create(purchase).Error
 }
 
-func (r *ShopRepository) UpdateItemStock(itemID uuid.UUID, newStock int) error {
+func (r *shopRepository) UpdateItemStock(itemID uuid.UUID, newStock int) error {
 	return r.db.Model(&models.ShopItem{}).Where("id = ?", itemID).Update("stock", newStock).Error
 }
 
-func (r *ShopRepository) GetUserPurchases(userID uuid.UUID) ([]models.UserPurchase, error) {
+func (r *shopRepository) GetUserPurchases(userID uuid.UUID) ([]models.UserPurchase, error) {
 	var purchases []models.UserPurchase
 	err := r.db.Preload("ShopItem").Where("user_id = ?", userID).Find(&purchases).Error
 	return purchases, err
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StatsRepository interface {
+	RecordActivity(userID uuid.UUID, at time.Time) error
+	CountUsers() (int64, error)
+	CountActiveUsers(since time.Time) (int64, error)
+	CountQuests() (int64, error)
+	CountQuestCompletions() (int64, error)
+	CountGuilds() (int64, error)
+	GetEconomyTotals() (*models.EconomyTotals, error)
+	GetDailySeries(metric models.StatsMetric, since time.Time) ([]models.DailyCount, error)
+}
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{
+		db: db,
+	}
+}
+
+// RecordActivity stamps UserStatistics.LastActive and makes sure the user
+// has a DailyStatistics row for the day, creating either row on first use.
+func (r *statsRepository) RecordActivity(userID uuid.UUID, at time.Time) error {
+	result := r.db.Model(&models.UserStatistics{}).
+		Where("user_id = ?", userID).
+		Update("last_active", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		stats := &models.UserStatistics{UserID: userID, LastActive: at}
+		if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(stats).Error; err != nil {
+			return err
+		}
+	}
+
+	day := models.ActivityDay(at)
+	var count int64
+	err := r.db.Model(&models.DailyStatistics{}).
+		Where("user_id = ? AND date = ?", userID, day).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+	return r.db.Create(&models.DailyStatistics{UserID: userID, Date: day}).Error
+}
+
+func (r *statsRepository) CountUsers() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+func (r *statsRepository) CountActiveUsers(since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserStatistics{}).
+		Where("last_active >= ?", since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *statsRepository) CountQuests() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Quest{}).Count(&count).Error
+	return count, err
+}
+
+func (r *statsRepository) CountQuestCompletions() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserQuestProgress{}).
+		Where("status = ?", models.QuestStatusCompleted).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *statsRepository) CountGuilds() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Guild{}).Count(&count).Error
+	return count, err
+}
+
+func (r *statsRepository) GetEconomyTotals() (*models.EconomyTotals, error) {
+	var totals models.EconomyTotals
+	err := r.db.Model(&models.User{}).
+		Select("COALESCE(SUM(coins), 0)").
+		Scan(&totals.CoinsInCirculation).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&models.CoinLedgerEntry{}).
+		Select("COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS coins_earned, "+
+			"COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0) AS coins_spent, "+
+			"COUNT(DISTINCT transaction_id) AS transactions").
+		Where("account = ?", models.LedgerAccountWallet).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// GetDailySeries returns one row per UTC day since the given time on which
+// the metric was non-zero, oldest first.
+func (r *statsRepository) GetDailySeries(metric models.StatsMetric, since time.Time) ([]models.DailyCount, error) {
+	var query *gorm.DB
+	column, value := "created_at", "COUNT(*)"
+
+	switch metric {
+	case models.StatsMetricNewUsers:
+		query = r.db.Model(&models.User{})
+	case models.StatsMetricActiveUsers:
+		query = r.db.Model(&models.DailyStatistics{})
+		column, value = "date", "COUNT(DISTINCT user_id)"
+	case models.StatsMetricQuestCompletions:
+		query = r.db.Model(&models.UserQuestProgress{}).Where("status = ?", models.QuestStatusCompleted)
+		column = "completed_at"
+	case models.StatsMetricNewGuilds:
+		query = r.db.Model(&models.Guild{})
+	case models.StatsMetricCoinsEarned:
+		query = r.db.Model(&models.CoinLedgerEntry{}).Where("account = ? AND amount > 0", models.LedgerAccountWallet)
+		value = "SUM(amount)"
+	case models.StatsMetricCoinsSpent:
+		query = r.db.Model(&models.CoinLedgerEntry{}).Where("account = ? AND amount < 0", models.LedgerAccountWallet)
+		value = "-SUM(amount)"
+	default:
+		return nil, fmt.Errorf("unknown stats metric %q", metric)
+	}
+
+	var series []models.DailyCount
+	err := query.
+		Select(dayExpr(r.db, column)+" AS day, "+value+" AS value").
+		Where(column+" >= ?", since).
+		Group("day").
+		Order("day").
+		Scan(&series).Error
+	return series, err
+}
+
+// dayExpr formats a timestamp column as YYYY-MM-DD in the current dialect.
+func dayExpr(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "TO_CHAR(" + column + ", 'YYYY-MM-DD')"
+	case "mysql":
+		return "DATE_FORMAT(" + column + ", '%Y-%m-%d')"
+	default:
+		return "DATE(" + column + ")"
+	}
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UnitOfWork runs multi-step operations atomically. The repositories handed
+// to fn are bound to a single transaction that is committed when fn returns
+// nil and rolled back when it returns an error.
+type UnitOfWork interface {
+	Do(fn func(repos *Repositories) error) error
+}
+
+type gormUnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &gormUnitOfWork{
+		db: db,
+	}
+}
+
+func (u *gormUnitOfWork) Do(fn func(repos *Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
+
+// forUpdate adds SELECT ... FOR UPDATE. Dialects without row locks (SQLite)
+// drop the clause and rely on the transaction itself.
+func forUpdate(db *gorm.DB) *gorm.DB {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
@@ -1,7 +1,6 @@
 package repositories
 
 import (
-	"code-valley-api/internal/database"
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/utils"
 
@@ -9,21 +8,33 @@ import (
 	"gorm.io/gorm"
 )
 
-type UserRepository struct {
+type UserRepository interface {
+	Create(user *models.User) error
+	GetByID(id uuid.UUID) (*models.User, error)
+	GetByIDForUpdate(id uuid.UUID) (*models.User, error)
+	GetByEmail(email string) (*models.User, error)
+	GetByUsername(username string) (*models.User, error)
+	Update(user *models.User) error
+	GetAll(pagination utils.PaginationParams) ([]models.User, int64, error)
+	UpdateEXPAndLevel(userID uuid.UUID, exp, level int) error
+	UpdateCoins(userID uuid.UUID, coins int) error
+}
+
+type userRepository struct {
 	db *gorm.DB
 }
 
-func NewUserRepository() *UserRepository {
-	return &UserRepository{
-		db: database.GetDB(),
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{
+		db: db,
 	}
 }
 
-func (r *UserRepository) Create(user *models.User) error {
+func (r *userRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
-func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
+func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	var user models.User
 	err := r.db.First(&user, "id = ?", id).Error
 	if err != nil {
@@ -32,7 +43,17 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
+// GetByIDForUpdate locks the user row until the surrounding transaction ends.
+func (r *userRepository) GetByIDForUpdate(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := forUpdate(r.db).First(&user, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	var user models.User
 	err := r.db.First(&user, "email = ?", email).Error
 	if err != nil {
@@ -41,7 +62,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
+func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	var user models.User
 	err := r.db.First(&user, "username = ?", username).Error
 	if err != nil {
@@ -50,11 +71,11 @@ func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) Update(user *models.User) error {
+func (r *userRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
-func (r *UserRepository) GetAll(pagination utils.PaginationParams) ([]models.User, int64, error) {
+func (r *userRepository) GetAll(pagination utils.PaginationParams) ([]models.User, int64, error) {
 	var users []models.User
 	var total int64
 
@@ -69,7 +90,7 @@ func (r *UserRepository) GetAll(pagination utils.PaginationParams) ([]models.Use
 	return users, total, err
 }
 
-func (r *UserRepository) UpdateEXPAndLevel(userID uuid.UUID, exp, level int) error {
+func (r *userRepository) UpdateEXPAndLevel(userID uuid.UUID, exp, level int) error {
 	return r.db.Model(&models.User{}).
 		Where("id = ?", userID).
 		Updates(map[string]interface{}{
@@ -78,7 +99,7 @@ func (r *UserRepository) UpdateEXPAndLevel(userID uuid.UUID, exp, level int) err
 		}).Error
 }
 
-func (r *UserRepository) UpdateCoins(userID uuid.UUID, coins int) error {
+func (r *userRepository) UpdateCoins(userID uuid.UUID, coins int) error {
 	return r.db.Model(&models.User{}).
 		Where("id = ?", userID).
 		Update("coins", coins).Error
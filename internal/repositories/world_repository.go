@@ -1,94 +1,204 @@
 package repositories
 
 import (
-	"code-valley-api/internal/database"
 	"code-valley-api/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type WorldRepository struct {
+type WorldRepository interface {
+	GetMapByName(name string) (*models.Map, error)
+	GetMapByID(id uuid.UUID) (*models.Map, error)
+	CreateMap(mapData *models.Map) error
+	// UpdateMap saves a map; its layout is validated against its size.
+	UpdateMap(mapData *models.Map) error
+	GetPlayerPosition(userID uuid.UUID) (*models.PlayerPosition, error)
+	// GetPlayerPositionForUpdate locks the position until the surrounding
+	// transaction ends. The Map is not loaded.
+	GetPlayerPositionForUpdate(userID uuid.UUID) (*models.PlayerPosition, error)
+	CreatePlayerPosition(position *models.PlayerPosition) error
+	UpdatePlayerPosition(position *models.PlayerPosition) error
+	GetPlayersInMap(mapID uuid.UUID) ([]models.PlayerPosition, error)
+	GetWorldObjects(mapID uuid.UUID) ([]models.WorldObject, error)
+	GetWorldObjectsAt(mapID uuid.UUID, posX, posY int) ([]models.WorldObject, error)
+	// GetAllWorldObjects includes objects that are no longer active.
+	GetAllWorldObjects(mapID uuid.UUID) ([]models.WorldObject, error)
+	// GetWorldObjectsByType returns objects of the types on every map,
+	// including inactive ones.
+	GetWorldObjectsByType(types []models.ObjectType) ([]models.WorldObject, error)
+	// GetWorldObjectForUpdate locks the object until the surrounding
+	// transaction ends.
+	GetWorldObjectForUpdate(id uuid.UUID) (*models.WorldObject, error)
+	CreateWorldObject(obj *models.WorldObject) error
+	UpdateWorldObject(obj *models.WorldObject) error
+	DeleteWorldObject(id uuid.UUID) error
+	GetNPCByName(name string) (*models.NPC, error)
+	GetNPCPosition(npcID uuid.UUID) (*models.NPCPosition, error)
+	GetNPCPositions(mapID uuid.UUID) ([]models.NPCPosition, error)
+	CreateNPCPosition(position *models.NPCPosition) error
+	UpdateNPCPosition(position *models.NPCPosition) error
+	GetNPCSchedules(npcID uuid.UUID) ([]models.NPCSchedule, error)
+	GetActiveNPCSchedules() ([]models.NPCSchedule, error)
+	GetGameClock() (*models.GameClock, error)
+	UpdateGameClock(clock *models.GameClock) error
+	GetUserCodeFarms(userID uuid.UUID) ([]models.CodeFarm, error)
+	GetCodeFarm(farmID uuid.UUID) (*models.CodeFarm, error)
+	GetCodeFarmForUpdate(farmID uuid.UUID) (*models.CodeFarm, error)
+	GetCodeFarmAt(userID uuid.UUID, plotX, plotY int) (*models.CodeFarm, error)
+	CreateCodeFarm(farm *models.CodeFarm) error
+	UpdateCodeFarm(farm *models.CodeFarm) error
+	DeleteCodeFarm(farmID uuid.UUID) error
+}
+
+type worldRepository struct {
 	db *gorm.DB
 }
 
-func NewWorldRepository() *WorldRepository {
-	return &WorldRepository{
-		db: database.GetDB(),
+func NewWorldRepository(db *gorm.DB) WorldRepository {
+	return &worldRepository{
+		db: db,
 	}
 }
 
 // Map operations
-func (r *WorldRepository) GetMapByName(name string) (*models.Map, error) {
+func (r *worldRepository) GetMapByName(name string) (*models.Map, error) {
 	var mapData models.Map
 	err := r.db.Where("name = ? AND is_active = ?", name, true).First(&mapData).Error
 	return &mapData, err
 }
 
-func (r *WorldRepository) GetMapByID(id uuid.UUID) (*models.Map, error) {
+func (r *worldRepository) GetMapByID(id uuid.UUID) (*models.Map, error) {
 	var mapData models.Map
 	err := r.db.First(&mapData, "id = ?", id).Error
 	return &mapData, err
 }
 
+func (r *worldRepository) CreateMap(mapData *models.Map) error {
+	return r.db.Omit(clause.Associations).Create(mapData).Error
+}
+
+func (r *worldRepository) UpdateMap(mapData *models.Map) error {
+	return r.db.Omit(clause.Associations).Save(mapData).Error
+}
+
 // Player position operations
-func (r *WorldRepository) GetPlayerPosition(userID uuid.UUID) (*models.PlayerPosition, error) {
+func (r *worldRepository) GetPlayerPosition(userID uuid.UUID) (*models.PlayerPosition, error) {
 	var position models.PlayerPosition
 	err := r.db.Preload("Map").Where("user_id = ?", userID).First(&position).Error
 	return &position, err
 }
 
-func (r *WorldRepository) CreatePlayerPosition(position *models.PlayerPosition) error {
+func (r *worldRepository) GetPlayerPositionForUpdate(userID uuid.UUID) (*models.PlayerPosition, error) {
+	var position models.PlayerPosition
+	err := forUpdate(r.db).Where("user_id = ?", userID).First(&position).Error
+	return &position, err
+}
+
+func (r *worldRepository) CreatePlayerPosition(position *models.PlayerPosition) error {
 	return r.db.Create(position).Error
 }
 
-func (r *WorldRepository) UpdatePlayerPosition(position *models.PlayerPosition) error {
-	return r.db.Save(position).Error
+func (r *worldRepository) UpdatePlayerPosition(position *models.PlayerPosition) error {
+	// Skip the preloaded Map, otherwise GORM resets map_id from it
+	return r.db.Omit(clause.Associations).Save(position).Error
 }
 
-func (r *WorldRepository) GetPlayersInMap(mapID uuid.UUID) ([]models.PlayerPosition, error) {
+func (r *worldRepository) GetPlayersInMap(mapID uuid.UUID) ([]models.PlayerPosition, error) {
 	var positions []models.PlayerPosition
 	err := r.db.Preload("User").Where("map_id = ?", mapID).Find(&positions).Error
 	return positions, err
 }
 
 // World object operations
-func (r *WorldRepository) GetWorldObjects(mapID uuid.UUID) ([]models.WorldObject, error) {
+func (r *worldRepository) GetWorldObjects(mapID uuid.UUID) ([]models.WorldObject, error) {
 	var objects []models.WorldObject
 	err := r.db.Where("map_id = ? AND is_active = ?", mapID, true).Find(&objects).Error
 	return objects, err
 }
 
-func (r *WorldRepository) GetWorldObjectsAt(mapID uuid.UUID, posX, posY int) ([]models.WorldObject, error) {
+func (r *worldRepository) GetWorldObjectsAt(mapID uuid.UUID, posX, posY int) ([]models.WorldObject, error) {
 	var objects []models.WorldObject
 	err := r.db.Where("map_id = ? AND pos_x = ? AND pos_y = ? AND is_active = ?", 
 		mapID, posX, posY, true).Find(&objects).Error
 	return objects, err
 }
 
-func (r *WorldRepository) UpdateWorldObject(obj *models.WorldObject) error {
+func (r *worldRepository) GetAllWorldObjects(mapID uuid.UUID) ([]models.WorldObject, error) {
+	var objects []models.WorldObject
+	err := r.db.Where("map_id = ?", mapID).Order("created_at, id").Find(&objects).Error
+	return objects, err
+}
+
+func (r *worldRepository) GetWorldObjectsByType(types []models.ObjectType) ([]models.WorldObject, error) {
+	var objects []models.WorldObject
+	err := r.db.Where("object_type IN ?", types).Order("map_id, created_at, id").Find(&objects).Error
+	return objects, err
+}
+
+func (r *worldRepository) GetWorldObjectForUpdate(id uuid.UUID) (*models.WorldObject, error) {
+	var obj models.WorldObject
+	err := forUpdate(r.db).First(&obj, "id = ?", id).Error
+	return &obj, err
+}
+
+func (r *worldRepository) CreateWorldObject(obj *models.WorldObject) error {
+	return r.db.Omit(clause.Associations).Create(obj).Error
+}
+
+func (r *worldRepository) UpdateWorldObject(obj *models.WorldObject) error {
 	return r.db.Save(obj).Error
 }
 
+func (r *worldRepository) DeleteWorldObject(id uuid.UUID) error {
+	return r.db.Delete(&models.WorldObject{}, "id = ?", id).Error
+}
+
 // NPC position operations
-func (r *WorldRepository) GetNPCPositions(mapID uuid.UUID) ([]models.NPCPosition, error) {
+func (r *worldRepository) GetNPCByName(name string) (*models.NPC, error) {
+	var npc models.NPC
+	err := r.db.Where("name = ?", name).First(&npc).Error
+	return &npc, err
+}
+
+func (r *worldRepository) GetNPCPosition(npcID uuid.UUID) (*models.NPCPosition, error) {
+	var position models.NPCPosition
+	err := r.db.Where("npc_id = ?", npcID).First(&position).Error
+	return &position, err
+}
+
+func (r *worldRepository) GetNPCPositions(mapID uuid.UUID) ([]models.NPCPosition, error) {
 	var positions []models.NPCPosition
 	err := r.db.Preload("NPC").Where("map_id = ?", mapID).Find(&positions).Error
 	return positions, err
 }
 
-func (r *WorldRepository) UpdateNPCPosition(position *models.NPCPosition) error {
+func (r *worldRepository) CreateNPCPosition(position *models.NPCPosition) error {
+	return r.db.Omit(clause.Associations).Create(position).Error
+}
+
+func (r *worldRepository) UpdateNPCPosition(position *models.NPCPosition) error {
 	return r.db.Save(position).Error
 }
 
-func (r *WorldRepository) GetNPCSchedules(npcID uuid.UUID) ([]models.NPCSchedule, error) {
+func (r *worldRepository) GetNPCSchedules(npcID uuid.UUID) ([]models.NPCSchedule, error) {
 	var schedules []models.NPCSchedule
 	err := r.db.Where("npc_id = ?", npcID).Find(&schedules).Error
 	return schedules, err
 }
 
+// GetActiveNPCSchedules returns the schedules of every active NPC.
+func (r *worldRepository) GetActiveNPCSchedules() ([]models.NPCSchedule, error) {
+	var schedules []models.NPCSchedule
+	err := r.db.Joins("JOIN npcs ON npcs.id = npc_schedules.npc_id").
+		Where("npcs.is_active = ?", true).
+		Find(&schedules).Error
+	return schedules, err
+}
+
 // Game clock operations
-func (r *WorldRepository) GetGameClock() (*models.GameClock, error) {
+func (r *worldRepository) GetGameClock() (*models.GameClock, error) {
 	var clock models.GameClock
 	err := r.db.First(&clock).Error
 	if err == gorm.ErrRecordNotFound {
@@ -106,37 +216,43 @@ func (r *WorldRepository) GetGameClock() (*models.GameClock, error) {
 	return &clock, err
 }
 
-func (r *WorldRepository) UpdateGameClock(clock *models.GameClock) error {
+func (r *worldRepository) UpdateGameClock(clock *models.GameClock) error {
 	return r.db.Save(clock).Error
 }
 
 // Code farm operations
-func (r *WorldRepository) GetUserCodeFarms(userID uuid.UUID) ([]models.CodeFarm, error) {
+func (r *worldRepository) GetUserCodeFarms(userID uuid.UUID) ([]models.CodeFarm, error) {
 	var farms []models.CodeFarm
 	err := r.db.Where("user_id = ?", userID).Find(&farms).Error
 	return farms, err
 }
 
-func (r *WorldRepository) GetCodeFarm(farmID uuid.UUID) (*models.CodeFarm, error) {
+func (r *worldRepository) GetCodeFarm(farmID uuid.UUID) (*models.CodeFarm, error) {
 	var farm models.CodeFarm
 	err := r.db.First(&farm, "id = ?", farmID).Error
 	return &farm, err
 }
 
-func (r *WorldRepository) GetCodeFarmAt(userID uuid.UUID, plotX, plotY int) (*models.CodeFarm, error) {
+func (r *worldRepository) GetCodeFarmForUpdate(farmID uuid.UUID) (*models.CodeFarm, error) {
+	var farm models.CodeFarm
+	err := forUpdate(r.db).First(&farm, "id = ?", farmID).Error
+	return &farm, err
+}
+
+func (r *worldRepository) GetCodeFarmAt(userID uuid.UUID, plotX, plotY int) (*models.CodeFarm, error) {
 	var farm models.CodeFarm
 	err := r.db.Where("user_id = ? AND plot_x = ? AND plot_y = ?", userID, plotX, plotY).First(&farm).Error
 	return &farm, err
 }
 
-func (r *WorldRepository) CreateCodeFarm(farm *models.CodeFarm) error {
+func (r *worldRepository) CreateCodeFarm(farm *models.CodeFarm) error {
 	return r.db.Create(farm).Error
 }
 
-func (r *WorldRepository) UpdateCodeFarm(farm *models.CodeFarm) error {
+func (r *worldRepository) UpdateCodeFarm(farm *models.CodeFarm) error {
 	return r.db.Save(farm).Error
 }
 
-func (r *WorldRepository) DeleteCodeFarm(farmID uuid.UUID) error {
+func (r *worldRepository) DeleteCodeFarm(farmID uuid.UUID) error {
 	return r.db.Delete(&models.CodeFarm{}, "id = ?", farmID).Error
 }
\ No newline at end of file
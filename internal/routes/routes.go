@@ -10,44 +10,37 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(app *fiber.App, cfg *config.Config) {
-	// Initialize services
-	authService := services.NewAuthService(cfg)
-	questService := services.NewQuestService()
-	friendService := services.NewFriendService()
-	leaderboardService := services.NewLeaderboardService()
-	shopService := services.NewShopService()
-	notificationService := services.NewNotificationService()
-	inventoryService := services.NewInventoryService()
-	adminService := services.NewAdminService()
-	worldService := services.NewWorldService()
-
+func SetupRoutes(app *fiber.App, cfg *config.Config, svc *services.Services) {
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
-	questHandler := handlers.NewQuestHandler(questService)
-	friendHandler := handlers.NewFriendHandler(friendService)
-	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService)
-	shopHandler := handlers.NewShopHandler(shopService)
-	notificationHandler := handlers.NewNotificationHandler(notificationService)
-	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
-	adminHandler := handlers.NewAdminHandler(adminService)
-	worldHandler := handlers.NewWorldHandler(worldService)
+	authHandler := handlers.NewAuthHandler(svc.Auth)
+	questHandler := handlers.NewQuestHandler(svc.Quest)
+	friendHandler := handlers.NewFriendHandler(svc.Friend, svc.Presence)
+	leaderboardHandler := handlers.NewLeaderboardHandler(svc.Leaderboard)
+	shopHandler := handlers.NewShopHandler(svc.Shop)
+	notificationHandler := handlers.NewNotificationHandler(svc.Notification)
+	inventoryHandler := handlers.NewInventoryHandler(svc.Inventory)
+	adminHandler := handlers.NewAdminHandler(svc.Admin)
+	worldHandler := handlers.NewWorldHandler(svc.World)
+	walletHandler := handlers.NewWalletHandler(svc.Ledger)
+	chatHandler := handlers.NewChatHandler(svc.Chat)
+	lootHandler := handlers.NewLootHandler(svc.Loot)
 
 	// WebSocket endpoint
-	app.Get("/ws", websocket.WebSocketUpgrade(cfg))
+	app.Get("/ws", websocket.WebSocketUpgrade(cfg, svc.Auth, svc.World))
+	app.Get("/ws/schema", websocket.SchemaHandler())
 
 	// API v1 group
 	api := app.Group("/api/v1")
 
 	// World/Map routes
-	world := api.Group("/world", middleware.AuthMiddleware(cfg))
+	world := api.Group("/world", middleware.AuthMiddleware(cfg, svc.Auth))
 	world.Get("/maps/:map_name/state", worldHandler.GetMapState)
 	world.Get("/position", worldHandler.GetPlayerPosition)
-	world.Post("/teleport", worldHandler.TeleportPlayer)
+	world.Post("/teleport", middleware.RequireRole("admin"), worldHandler.TeleportPlayer)
 	world.Get("/time", worldHandler.GetGameTime)
 	
 	// Code farming routes
-	farming := api.Group("/farming", middleware.AuthMiddleware(cfg))
+	farming := api.Group("/farming", middleware.AuthMiddleware(cfg, svc.Auth))
 	farming.Get("/", worldHandler.GetCodeFarms)
 	farming.Post("/plant", worldHandler.PlantCode)
 	farming.Post("/:id/water", worldHandler.WaterCode)
@@ -57,18 +50,20 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	auth := api.Group("/auth")
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
+	auth.Post("/refresh", authHandler.RefreshToken)
 
 	// Protected auth routes
-	authProtected := auth.Group("/", middleware.AuthMiddleware(cfg))
+	authProtected := auth.Group("/", middleware.AuthMiddleware(cfg, svc.Auth))
 	authProtected.Get("/me", authHandler.GetProfile)
 	authProtected.Put("/profile", authHandler.UpdateProfile)
-	authProtected.Post("/refresh", authHandler.RefreshToken)
 	authProtected.Post("/logout", authHandler.Logout)
+	authProtected.Get("/sessions", authHandler.GetSessions)
+	authProtected.Delete("/sessions/:id", authHandler.RevokeSession)
 	authProtected.Post("/avatar", authHandler.UploadAvatar)
 	authProtected.Delete("/delete", authHandler.DeleteAccount)
 
 	// Protected quest routes
-	quests := api.Group("/quests", middleware.AuthMiddleware(cfg))
+	quests := api.Group("/quests", middleware.AuthMiddleware(cfg, svc.Auth))
 	quests.Get("/", questHandler.GetQuests)
 	quests.Get("/:id", questHandler.GetQuestByID)
 	quests.Post("/:id/start", questHandler.StartQuest)
@@ -76,18 +71,32 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	quests.Get("/progress", questHandler.GetUserProgress)
 
 	// Admin quest routes
-	adminQuests := api.Group("/admin/quests", middleware.AuthMiddleware(cfg), middleware.RequireRole("admin"))
+	adminQuests := api.Group("/admin/quests", middleware.AuthMiddleware(cfg, svc.Auth), middleware.RequireRole("admin"))
 	adminQuests.Post("/", questHandler.CreateQuest)
 	adminQuests.Put("/:id", questHandler.UpdateQuest)
 	adminQuests.Delete("/:id", questHandler.DeleteQuest)
 
 	// Friend routes
-	friends := api.Group("/friends", middleware.AuthMiddleware(cfg))
+	friends := api.Group("/friends", middleware.AuthMiddleware(cfg, svc.Auth))
 	friends.Get("/", friendHandler.GetFriends)
 	friends.Post("/:username/add", friendHandler.SendFriendRequest)
 	friends.Post("/:username/accept", friendHandler.AcceptFriendRequest)
 	friends.Delete("/:username/remove", friendHandler.RemoveFriend)
 	friends.Get("/online", friendHandler.GetOnlineFriends)
+	friends.Put("/status", friendHandler.SetStatus)
+	friends.Post("/:username/block", friendHandler.BlockUser)
+	friends.Delete("/:username/block", friendHandler.UnblockUser)
+
+	// Chat routes
+	chat := api.Group("/chat", middleware.AuthMiddleware(cfg, svc.Auth))
+	chat.Post("/messages", chatHandler.SendMessage)
+	chat.Get("/global", chatHandler.GetGlobalHistory)
+	chat.Get("/maps/:id", chatHandler.GetMapHistory)
+	chat.Get("/guilds/:id", chatHandler.GetGuildHistory)
+	chat.Get("/whispers/:username", chatHandler.GetWhisperHistory)
+	chat.Get("/mutes", chatHandler.GetMutes)
+	chat.Post("/mutes/:username", chatHandler.MuteUser)
+	chat.Delete("/mutes/:username", chatHandler.UnmuteUser)
 
 	// Leaderboard routes
 	leaderboard := api.Group("/leaderboard")
@@ -96,19 +105,24 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	leaderboard.Get("/tasks", leaderboardHandler.GetTaskLeaderboard)
 
 	// Shop routes
-	shop := api.Group("/shop", middleware.AuthMiddleware(cfg))
+	shop := api.Group("/shop", middleware.AuthMiddleware(cfg, svc.Auth))
 	shop.Get("/items", shopHandler.GetShopItems)
 	shop.Post("/items/:id/buy", shopHandler.BuyItem)
 	shop.Post("/items/:id/sell", shopHandler.SellItem)
 
+	// Wallet routes
+	wallet := api.Group("/wallet", middleware.AuthMiddleware(cfg, svc.Auth))
+	wallet.Get("/", walletHandler.GetBalance)
+	wallet.Get("/history", walletHandler.GetHistory)
+
 	// Notification routes
-	notifications := api.Group("/notifications", middleware.AuthMiddleware(cfg))
+	notifications := api.Group("/notifications", middleware.AuthMiddleware(cfg, svc.Auth))
 	notifications.Get("/", notificationHandler.GetNotifications)
 	notifications.Post("/:id/read", notificationHandler.MarkAsRead)
 	notifications.Post("/mark-read", notificationHandler.MarkAllAsRead)
 
 	// Inventory routes
-	inventory := api.Group("/inventory", middleware.AuthMiddleware(cfg))
+	inventory := api.Group("/inventory", middleware.AuthMiddleware(cfg, svc.Auth))
 	inventory.Get("/", inventoryHandler.GetInventory)
 	inventory.Get("/:id", inventoryHandler.GetItem)
 	inventory.Post("/use/:id", inventoryHandler.UseItem)
@@ -116,12 +130,28 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	inventory.Post("/unequip/:id", inventoryHandler.UnequipItem)
 
 	// Admin routes
-	admin := api.Group("/admin", middleware.AuthMiddleware(cfg), middleware.RequireRole("admin"))
+	admin := api.Group("/admin", middleware.AuthMiddleware(cfg, svc.Auth), middleware.RequireRole("admin"))
 	admin.Get("/users", adminHandler.GetAllUsers)
 	admin.Put("/users/:id/ban", adminHandler.BanUser)
+	admin.Delete("/users/:id/ban", adminHandler.UnbanUser)
+	admin.Get("/users/:id/bans", adminHandler.GetUserBans)
+	admin.Get("/bans", adminHandler.GetBans)
 	admin.Put("/users/:id/role", adminHandler.ChangeUserRole)
 	admin.Get("/stats", adminHandler.GetSystemStats)
+	admin.Get("/stats/series", adminHandler.GetStatsSeries)
 	admin.Get("/logs", adminHandler.GetAuditLogs)
+	admin.Get("/anticheat", adminHandler.GetAntiCheatEvents)
+	admin.Get("/anticheat/summary", adminHandler.GetAntiCheatSummary)
+	admin.Post("/maps/import", worldHandler.ImportMap)
+	admin.Put("/maps/:id/layout", worldHandler.UpdateMapLayout)
+	admin.Get("/loot-tables", lootHandler.GetLootTables)
+	admin.Get("/loot-tables/:name", lootHandler.GetLootTable)
+	admin.Put("/loot-tables/:name", lootHandler.SaveLootTable)
+	admin.Delete("/loot-tables/:name", lootHandler.DeleteLootTable)
+	admin.Post("/loot-tables/:name/simulate", lootHandler.SimulateLootTable)
+	admin.Get("/ledger/reconciliation", walletHandler.GetReconciliation)
+	admin.Post("/ledger/reconciliation", walletHandler.Reconcile)
+	admin.Delete("/chat/messages/:id", chatHandler.DeleteMessage)
 
 	// Health check
 	api.Get("/health", func(c *fiber.Ctx) error {
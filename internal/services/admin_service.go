@@ -2,28 +2,62 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
+	"code-valley-api/internal/config"
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/repositories"
 	"code-valley-api/internal/utils"
+	"code-valley-api/internal/websocket"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrAlreadyBanned = errors.New("user is already banned")
+	ErrNotBanned     = errors.New("user is not banned")
+	ErrInvalidStats  = errors.New("invalid stats query")
+	ErrInvalidDays   = errors.New("invalid number of days")
+)
+
+// AuditLogFilter narrows GetAuditLogs; zero fields match everything.
+type AuditLogFilter = repositories.AuditLogFilter
+
+// AntiCheatFilter narrows GetAntiCheatEvents; zero fields match everything.
+type AntiCheatFilter = repositories.AntiCheatFilter
+
+const maxAntiCheatSummaryDays = 90
+
 type AdminService struct {
-	userRepo *repositories.UserRepository
+	userRepo   repositories.UserRepository
+	banRepo    repositories.BanRepository
+	auditRepo  repositories.AuditLogRepository
+	statsRepo  repositories.StatsRepository
+	cheatRepo  repositories.AntiCheatRepository
+	uow        repositories.UnitOfWork
+	cfg        *config.Config
+	statsCache *statsCache
 }
 
-func NewAdminService() *AdminService {
+func NewAdminService(cfg *config.Config, userRepo repositories.UserRepository, banRepo repositories.BanRepository, auditRepo repositories.AuditLogRepository, statsRepo repositories.StatsRepository, cheatRepo repositories.AntiCheatRepository, uow repositories.UnitOfWork) *AdminService {
 	return &AdminService{
-		userRepo: repositories.NewUserRepository(),
+		userRepo:   userRepo,
+		banRepo:    banRepo,
+		auditRepo:  auditRepo,
+		statsRepo:  statsRepo,
+		cheatRepo:  cheatRepo,
+		uow:        uow,
+		cfg:        cfg,
+		statsCache: newStatsCache(time.Duration(cfg.Stats.CacheSeconds) * time.Second),
 	}
 }
 
 type BanUserRequest struct {
 	Reason   string `json:"reason" validate:"required"`
-	Duration int    `json:"duration"` // days, 0 for permanent
+	Duration int    `json:"duration" validate:"min=0"` // days, 0 for permanent
 }
 
 type ChangeRoleRequest struct {
@@ -57,81 +91,360 @@ func (s *AdminService) GetAllUsers(pagination utils.PaginationParams) (*models.P
 	}, nil
 }
 
-func (s *AdminService) BanUser(userID uuid.UUID, req BanUserRequest) error {
+// BanUser suspends a user for Duration days, or permanently when Duration is
+// zero. All of the user's sessions are revoked and live sockets kicked.
+func (s *AdminService) BanUser(actor Actor, userID uuid.UUID, req BanUserRequest) (*models.UserBan, error) {
 	if err := utils.ValidateStruct(req); err != nil {
-		return err
+		return nil, err
 	}
 
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		return errors.New("user not found")
+		return nil, errors.New("user not found")
 	}
 
 	if user.Role == models.RoleAdmin {
-		return errors.New("cannot ban admin users")
+		return nil, errors.New("cannot ban admin users")
+	}
+
+	now := time.Now()
+	ban := &models.UserBan{
+		UserID:     user.ID,
+		Reason:     req.Reason,
+		IssuedByID: actor.UserID,
+		StartsAt:   now,
 	}
+	if req.Duration > 0 {
+		expiresAt := now.AddDate(0, 0, req.Duration)
+		ban.ExpiresAt = &expiresAt
+	}
+
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if _, err := repos.Bans.GetActiveBan(user.ID); err == nil {
+			return ErrAlreadyBanned
+		}
+		if err := repos.Bans.Create(ban); err != nil {
+			return err
+		}
+
+		sessionIDs, err := repos.Sessions.RevokeAllForUser(user.ID)
+		if err != nil {
+			return err
+		}
+		for _, sessionID := range sessionIDs {
+			if err := repos.RefreshTokens.RevokeFamily(sessionID); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionUserBan, models.AuditTargetUser, user.ID, nil, ban)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	websocket.DisconnectUser(user.ID, "account_banned", map[string]interface{}{
+		"reason":     ban.Reason,
+		"expires_at": ban.ExpiresAt,
+	})
+
+	return ban, nil
+}
+
+// UnbanUser lifts every ban currently in force on the user.
+func (s *AdminService) UnbanUser(actor Actor, userID uuid.UUID) error {
+	return s.uow.Do(func(repos *repositories.Repositories) error {
+		ban, err := repos.Bans.GetActiveBan(userID)
+		if err != nil {
+			return ErrNotBanned
+		}
+
+		if _, err := repos.Bans.LiftActive(userID, actor.UserID); err != nil {
+			return err
+		}
 
-	// In a real implementation, you'd have a bans table
-	// For now, we'll just mark the user as banned in a comment or separate field
-	user.Bio = "BANNED: " + req.Reason
-	
-	return s.userRepo.Update(user)
+		lifted := *ban
+		now := time.Now()
+		lifted.LiftedAt = &now
+		lifted.LiftedByID = &actor.UserID
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionUserUnban, models.AuditTargetUser, userID, ban, lifted)
+	})
 }
 
-func (s *AdminService) ChangeUserRole(userID uuid.UUID, req ChangeRoleRequest) (*models.UserResponse, error) {
+// GetUserBans returns the full ban history of a user, newest first.
+func (s *AdminService) GetUserBans(userID uuid.UUID) ([]models.UserBan, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+	return s.banRepo.GetUserBans(userID)
+}
+
+func (s *AdminService) GetBans(pagination utils.PaginationParams, activeOnly bool) (*models.PaginatedResponse, error) {
+	bans, total, err := s.banRepo.GetAll(pagination, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]interface{}, len(bans))
+	for i, ban := range bans {
+		data[i] = ban
+	}
+
+	totalPages := int(total) / pagination.PerPage
+	if int(total)%pagination.PerPage > 0 {
+		totalPages++
+	}
+
+	return &models.PaginatedResponse{
+		Data: data,
+		Meta: models.PaginationMeta{
+			CurrentPage: pagination.Page,
+			PerPage:     pagination.PerPage,
+			Total:       int(total),
+			TotalPages:  totalPages,
+		},
+	}, nil
+}
+
+func (s *AdminService) ChangeUserRole(actor Actor, userID uuid.UUID, req ChangeRoleRequest) (*models.UserResponse, error) {
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, err
 	}
 
-	user, err := s.userRepo.GetByID(userID)
+	var response models.UserResponse
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		user, err := repos.Users.GetByIDForUpdate(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		before := user.ToResponse()
+
+		user.Role = models.UserRole(req.Role)
+
+		if err := repos.Users.Update(user); err != nil {
+			return err
+		}
+
+		response = user.ToResponse()
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionUserRoleChange, models.AuditTargetUser, user.ID, before, response)
+	})
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
-	user.Role = models.UserRole(req.Role)
-	
-	if err := s.userRepo.Update(user); err != nil {
+	return &response, nil
+}
+
+// maxStatsSeriesDays bounds how far back a stats series can reach.
+const maxStatsSeriesDays = 365
+
+// processStartedAt is used to report server uptime.
+var processStartedAt = time.Now()
+
+type SystemStats struct {
+	TotalUsers       int64                `json:"total_users"`
+	ActiveUsers      int64                `json:"active_users"`
+	ActiveWindowDays int                  `json:"active_window_days"`
+	OnlineUsers      int                  `json:"online_users"`
+	TotalQuests      int64                `json:"total_quests"`
+	CompletedQuests  int64                `json:"completed_quests"`
+	TotalGuilds      int64                `json:"total_guilds"`
+	Economy          models.EconomyTotals `json:"economy"`
+	ServerUptime     string               `json:"server_uptime"`
+	UptimeSeconds    int64                `json:"uptime_seconds"`
+	StartedAt        time.Time            `json:"started_at"`
+	AggregatedAt     time.Time            `json:"aggregated_at"` // when the cached aggregates were computed
+	LastUpdated      time.Time            `json:"last_updated"`
+}
+
+// statsAggregates are the database-backed parts of SystemStats, cached
+// between requests.
+type statsAggregates struct {
+	totalUsers      int64
+	activeUsers     int64
+	totalQuests     int64
+	completedQuests int64
+	totalGuilds     int64
+	economy         models.EconomyTotals
+	computedAt      time.Time
+}
+
+// GetSystemStats combines cached aggregates with the live online count and
+// process uptime.
+func (s *AdminService) GetSystemStats() (*SystemStats, error) {
+	value, err := s.statsCache.get("aggregates", s.loadStatsAggregates)
+	if err != nil {
 		return nil, err
 	}
+	aggregates := value.(*statsAggregates)
 
-	response := user.ToResponse()
-	return &response, nil
+	now := time.Now()
+	uptime := now.Sub(processStartedAt)
+
+	return &SystemStats{
+		TotalUsers:       aggregates.totalUsers,
+		ActiveUsers:      aggregates.activeUsers,
+		ActiveWindowDays: s.cfg.Stats.ActiveDays,
+		OnlineUsers:      len(websocket.OnlineUsers()),
+		TotalQuests:      aggregates.totalQuests,
+		CompletedQuests:  aggregates.completedQuests,
+		TotalGuilds:      aggregates.totalGuilds,
+		Economy:          aggregates.economy,
+		ServerUptime:     formatUptime(uptime),
+		UptimeSeconds:    int64(uptime.Seconds()),
+		StartedAt:        processStartedAt,
+		AggregatedAt:     aggregates.computedAt,
+		LastUpdated:      now,
+	}, nil
 }
 
-func (s *AdminService) GetSystemStats() (map[string]interface{}, error) {
-	// In a real implementation, you'd query various tables for statistics
-	stats := map[string]interface{}{
-		"total_users":        1000,
-		"active_users":       750,
-		"total_quests":       50,
-		"completed_quests":   2500,
-		"total_guilds":       25,
-		"online_users":       150,
-		"server_uptime":      "5 days, 12 hours",
-		"last_updated":       time.Now(),
-	}
-
-	return stats, nil
-}
-
-func (s *AdminService) GetAuditLogs(pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
-	// In a real implementation, you'd have an audit_logs table
-	// For now, we'll return mock data
-	logs := []map[string]interface{}{
-		{
-			"id":        uuid.New(),
-			"action":    "user_login",
-			"user_id":   uuid.New(),
-			"timestamp": time.Now().Add(-1 * time.Hour),
-			"details":   "User logged in successfully",
-		},
-		{
-			"id":        uuid.New(),
-			"action":    "quest_completed",
-			"user_id":   uuid.New(),
-			"timestamp": time.Now().Add(-2 * time.Hour),
-			"details":   "Quest 'First Steps' completed",
-		},
+func (s *AdminService) loadStatsAggregates() (interface{}, error) {
+	var (
+		aggregates = &statsAggregates{computedAt: time.Now()}
+		err        error
+	)
+	activeSince := aggregates.computedAt.AddDate(0, 0, -s.cfg.Stats.ActiveDays)
+
+	if aggregates.totalUsers, err = s.statsRepo.CountUsers(); err != nil {
+		return nil, err
+	}
+	if aggregates.activeUsers, err = s.statsRepo.CountActiveUsers(activeSince); err != nil {
+		return nil, err
+	}
+	if aggregates.totalQuests, err = s.statsRepo.CountQuests(); err != nil {
+		return nil, err
+	}
+	if aggregates.completedQuests, err = s.statsRepo.CountQuestCompletions(); err != nil {
+		return nil, err
+	}
+	if aggregates.totalGuilds, err = s.statsRepo.CountGuilds(); err != nil {
+		return nil, err
+	}
+	economy, err := s.statsRepo.GetEconomyTotals()
+	if err != nil {
+		return nil, err
+	}
+	aggregates.economy = *economy
+
+	return aggregates, nil
+}
+
+// GetStatsSeries returns a day-by-day series for each requested metric over
+// the last days days, today included. Days without activity are zero.
+// An empty metrics list means every metric.
+func (s *AdminService) GetStatsSeries(metrics []models.StatsMetric, days int) (map[models.StatsMetric][]models.DailyCount, error) {
+	if days < 1 || days > maxStatsSeriesDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidStats, maxStatsSeriesDays)
+	}
+	if len(metrics) == 0 {
+		metrics = models.StatsMetrics
+	}
+
+	today := models.ActivityDay(time.Now())
+	since := today.AddDate(0, 0, -(days - 1))
+
+	result := make(map[models.StatsMetric][]models.DailyCount, len(metrics))
+	for _, metric := range metrics {
+		if !isStatsMetric(metric) {
+			return nil, fmt.Errorf("%w: unknown metric %q", ErrInvalidStats, metric)
+		}
+
+		key := fmt.Sprintf("series:%s:%d:%s", metric, days, today.Format("2006-01-02"))
+		value, err := s.statsCache.get(key, func() (interface{}, error) {
+			rows, err := s.statsRepo.GetDailySeries(metric, since)
+			if err != nil {
+				return nil, err
+			}
+			return fillDays(rows, since, days), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		result[metric] = value.([]models.DailyCount)
+	}
+
+	return result, nil
+}
+
+func isStatsMetric(metric models.StatsMetric) bool {
+	for _, known := range models.StatsMetrics {
+		if metric == known {
+			return true
+		}
+	}
+	return false
+}
+
+// fillDays expands a sparse series into one entry per day starting at since.
+func fillDays(rows []models.DailyCount, since time.Time, days int) []models.DailyCount {
+	values := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		values[row.Day] = row.Value
+	}
+
+	series := make([]models.DailyCount, days)
+	for i := range series {
+		day := since.AddDate(0, 0, i).Format("2006-01-02")
+		series[i] = models.DailyCount{Day: day, Value: values[day]}
+	}
+	return series
+}
+
+func formatUptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	return fmt.Sprintf("%d days, %d hours, %d minutes", days, hours, minutes)
+}
+
+// statsCache memoises expensive aggregates for a fixed time. Loads run under
+// the lock so concurrent requests for a cold key hit the database once.
+type statsCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]statsCacheEntry
+}
+
+type statsCacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	return &statsCache{
+		ttl:     ttl,
+		entries: make(map[string]statsCacheEntry),
+	}
+}
+
+func (c *statsCache) get(key string, load func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := c.entries[key]; ok && now.Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop stale entries, e.g. series keyed by a previous day
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = statsCacheEntry{value: value, expiresAt: now.Add(c.ttl)}
+	return value, nil
+}
+
+func (s *AdminService) GetAuditLogs(filter AuditLogFilter, pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
+	logs, total, err := s.auditRepo.Search(filter, pagination)
+	if err != nil {
+		return nil, err
 	}
 
 	data := make([]interface{}, len(logs))
@@ -139,13 +452,96 @@ func (s *AdminService) GetAuditLogs(pagination utils.PaginationParams) (*models.
 		data[i] = log
 	}
 
+	totalPages := int(total) / pagination.PerPage
+	if int(total)%pagination.PerPage > 0 {
+		totalPages++
+	}
+
 	return &models.PaginatedResponse{
 		Data: data,
 		Meta: models.PaginationMeta{
 			CurrentPage: pagination.Page,
 			PerPage:     pagination.PerPage,
-			Total:       len(logs),
-			TotalPages:  1,
+			Total:       int(total),
+			TotalPages:  totalPages,
 		},
 	}, nil
-}
\ No newline at end of file
+}
+
+func (s *AdminService) GetAntiCheatEvents(filter AntiCheatFilter, pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
+	events, total, err := s.cheatRepo.Search(filter, pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]interface{}, len(events))
+	for i, event := range events {
+		data[i] = event
+	}
+
+	totalPages := int(total) / pagination.PerPage
+	if int(total)%pagination.PerPage > 0 {
+		totalPages++
+	}
+
+	return &models.PaginatedResponse{
+		Data: data,
+		Meta: models.PaginationMeta{
+			CurrentPage: pagination.Page,
+			PerPage:     pagination.PerPage,
+			Total:       int(total),
+			TotalPages:  totalPages,
+		},
+	}, nil
+}
+
+// AntiCheatOffender is one user's anti-cheat record over a period.
+type AntiCheatOffender struct {
+	UserID   uuid.UUID                  `json:"user_id"`
+	Username string                     `json:"username"`
+	Total    int64                      `json:"total"`
+	ByKind   map[models.CheatKind]int64 `json:"by_kind"`
+	LastAt   time.Time                  `json:"last_at"`
+}
+
+// GetAntiCheatSummary lists users with anti-cheat events in the last days,
+// most flagged first.
+func (s *AdminService) GetAntiCheatSummary(days int) ([]AntiCheatOffender, error) {
+	if days < 1 || days > maxAntiCheatSummaryDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidDays, maxAntiCheatSummaryDays)
+	}
+
+	counts, err := s.cheatRepo.CountByUser(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[uuid.UUID]int)
+	offenders := []AntiCheatOffender{}
+	for _, count := range counts {
+		i, ok := index[count.UserID]
+		if !ok {
+			i = len(offenders)
+			index[count.UserID] = i
+			offender := AntiCheatOffender{UserID: count.UserID, ByKind: make(map[models.CheatKind]int64)}
+			if user, err := s.userRepo.GetByID(count.UserID); err == nil {
+				offender.Username = user.Username
+			}
+			offenders = append(offenders, offender)
+		}
+
+		offenders[i].Total += count.Count
+		offenders[i].ByKind[count.Kind] += count.Count
+		if count.LastAt.After(offenders[i].LastAt) {
+			offenders[i].LastAt = count.LastAt
+		}
+	}
+
+	sort.Slice(offenders, func(a, b int) bool {
+		if offenders[a].Total != offenders[b].Total {
+			return offenders[a].Total > offenders[b].Total
+		}
+		return offenders[a].LastAt.After(offenders[b].LastAt)
+	})
+	return offenders, nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// Actor is the user behind an audited action. A zero UserID means the
+// request was not signed in.
+type Actor struct {
+	UserID uuid.UUID
+	ClientInfo
+}
+
+// recordAudit logs action on a target along with the fields that changed
+// between the before and after snapshots. Callers run it in the same unit of
+// work as the change so the entry commits or rolls back with it.
+//
+// Audited actions call it from their services rather than from a request
+// middleware, which could neither diff the target nor share the change's
+// transaction. Every admin route that changes data does: bans, role
+// changes, quest, loot table and map edits, chat deletes and teleports.
+func recordAudit(logs repositories.AuditLogRepository, actor Actor, action models.AuditAction, targetType string, targetID uuid.UUID, before, after interface{}) error {
+	entry := &models.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		Changes:    models.DiffChanges(before, after),
+		IPAddress:  truncate(actor.IP, 45),
+		UserAgent:  truncate(actor.UserAgent, 255),
+		RequestID:  truncate(actor.RequestID, 64),
+	}
+	if actor.UserID != uuid.Nil {
+		entry.ActorID = &actor.UserID
+	}
+	if targetID != uuid.Nil {
+		entry.TargetID = &targetID
+	}
+	return logs.Create(entry)
+}
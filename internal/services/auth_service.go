@@ -2,37 +2,70 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"code-valley-api/internal/config"
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/repositories"
 	"code-valley-api/internal/utils"
+	"code-valley-api/internal/websocket"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// sessionTouchInterval limits how often a session's last-seen time is written.
+const sessionTouchInterval = time.Minute
+
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrUserBanned          = errors.New("account is banned")
+)
+
 type AuthService struct {
-	userRepo *repositories.UserRepository
-	cfg      *config.Config
+	userRepo         repositories.UserRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	sessionRepo      repositories.SessionRepository
+	banRepo          repositories.BanRepository
+	statsRepo        repositories.StatsRepository
+	uow              repositories.UnitOfWork
+	cfg              *config.Config
 }
 
-func NewAuthService(cfg *config.Config) *AuthService {
+func NewAuthService(cfg *config.Config, userRepo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, sessionRepo repositories.SessionRepository, banRepo repositories.BanRepository, statsRepo repositories.StatsRepository, uow repositories.UnitOfWork) *AuthService {
 	return &AuthService{
-		userRepo: repositories.NewUserRepository(),
-		cfg:      cfg,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		banRepo:          banRepo,
+		statsRepo:        statsRepo,
+		uow:              uow,
+		cfg:              cfg,
 	}
 }
 
+// ClientInfo describes the device and request an action came from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
 type RegisterRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Username string `json:"username" validate:"required,min=3,max=30"`
-	Password string `json:"password" validate:"required,min=6"`
+	Email      string `json:"email" validate:"required,email"`
+	Username   string `json:"username" validate:"required,min=3,max=30"`
+	Password   string `json:"password" validate:"required,min=6"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=100"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=100"`
 }
 
 type AuthResponse struct {
@@ -40,9 +73,14 @@ type AuthResponse struct {
 	User         models.UserResponse `json:"user"`
 	AccessToken  string              `json:"access_token"`
 	RefreshToken string              `json:"refresh_token"`
+	ExpiresIn    int                 `json:"expires_in"` // access token lifetime in seconds
 }
 
-func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (s *AuthService) Register(req RegisterRequest, client ClientInfo) (*AuthResponse, error) {
 	// Validate input
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, err
@@ -69,31 +107,30 @@ func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
 		Username:     req.Username,
 		PasswordHash: hashedPassword,
 		Role:         models.RolePlayer,
+		Coins:        signupBonus,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
-		return nil, err
-	}
+	var response *AuthResponse
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Users.Create(user); err != nil {
+			return err
+		}
+		if err := recordCoins(repos, user.ID, user.Coins, models.LedgerAccountSignupBonus, models.LedgerReasonSignupBonus, models.LedgerRefUser, user.ID); err != nil {
+			return err
+		}
 
-	// Generate token
-	token, err := utils.GenerateJWT(
-		user.ID,
-		user.Email,
-		string(user.Role),
-		s.cfg.JWT.Secret,
-		s.cfg.JWT.ExpireHours,
-	)
+		// Start a session for the new account
+		response, err = s.startSession(repos, user, req.DeviceName, client)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  user.ToResponse(),
-	}, nil
+	return response, nil
 }
 
-func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
+func (s *AuthService) Login(req LoginRequest, client ClientInfo) (*AuthResponse, error) {
 	// Validate input
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, err
@@ -110,24 +147,252 @@ func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
 
 	// Check password
 	if err := utils.CheckPassword(user.PasswordHash, req.Password); err != nil {
+		s.uow.Do(func(repos *repositories.Repositories) error {
+			return recordAudit(repos.AuditLogs, Actor{ClientInfo: client}, models.AuditActionLoginFailed, models.AuditTargetUser, user.ID, nil, nil)
+		})
 		return nil, errors.New("invalid credentials")
 	}
 
-	// Generate token
-	token, err := utils.GenerateJWT(
+	if err := s.checkBan(user.ID); err != nil {
+		return nil, err
+	}
+
+	// Every login starts a new session
+	var response *AuthResponse
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		response, err = s.startSession(repos, user, req.DeviceName, client)
+		if err != nil {
+			return err
+		}
+		return recordAudit(repos.AuditLogs, Actor{UserID: user.ID, ClientInfo: client}, models.AuditActionLogin, models.AuditTargetUser, user.ID, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// Each refresh token works once; presenting one that was already rotated
+// means it leaked, so the whole session is revoked.
+func (s *AuthService) Refresh(req RefreshTokenRequest, client ClientInfo) (*AuthResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	var (
+		response *AuthResponse
+		reused   *uuid.UUID
+	)
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		token, err := repos.RefreshTokens.GetByHashForUpdate(utils.HashToken(req.RefreshToken))
+		if err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		if token.UsedAt != nil && token.RevokedAt == nil {
+			reused = &token.FamilyID
+			return revokeSession(repos, token.FamilyID)
+		}
+
+		session, err := repos.Sessions.GetByID(token.FamilyID)
+		if err != nil || !token.IsUsable(time.Now()) || !session.IsActive(time.Now()) {
+			return ErrInvalidRefreshToken
+		}
+
+		if err := repos.RefreshTokens.MarkUsed(token.ID); err != nil {
+			return err
+		}
+
+		user, err := repos.Users.GetByID(token.UserID)
+		if err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		// Keep the device details current and extend the session
+		session.IPAddress = client.IP
+		session.UserAgent = truncate(client.UserAgent, 255)
+		session.LastSeenAt = time.Now()
+		session.ExpiresAt = s.refreshExpiry()
+		if err := repos.Sessions.Update(session); err != nil {
+			return err
+		}
+
+		response, err = s.issueTokens(repos.RefreshTokens, user, session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused != nil {
+		websocket.DisconnectSession(*reused)
+		return nil, ErrRefreshTokenReused
+	}
+
+	return response, nil
+}
+
+// Logout revokes the session the access token belongs to, including its
+// refresh token.
+func (s *AuthService) Logout(claims *utils.Claims) error {
+	return s.RevokeSession(claims.UserID, claims.SessionID)
+}
+
+// GetSessions lists the user's active sessions, flagging the current one.
+func (s *AuthService) GetSessions(claims *utils.Claims) ([]models.Session, error) {
+	sessions, err := s.sessionRepo.GetActiveByUser(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == claims.SessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs a device out: the session and its refresh tokens stop
+// working and any WebSocket connection opened with it is dropped.
+func (s *AuthService) RevokeSession(userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		return revokeSession(repos, sessionID)
+	})
+	if err != nil {
+		return err
+	}
+
+	websocket.DisconnectSession(sessionID)
+	return nil
+}
+
+// ValidateSession implements utils.SessionValidator.
+func (s *AuthService) ValidateSession(claims *utils.Claims) error {
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+
+	now := time.Now()
+	if session.UserID != claims.UserID || !session.IsActive(now) {
+		return ErrSessionRevoked
+	}
+
+	if err := s.checkBan(claims.UserID); err != nil {
+		return err
+	}
+
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		s.sessionRepo.Touch(session.ID, now)
+		s.statsRepo.RecordActivity(claims.UserID, now)
+	}
+	return nil
+}
+
+// checkBan returns an error wrapping ErrUserBanned, with the reason and end
+// date, while the user has a ban in force.
+func (s *AuthService) checkBan(userID uuid.UUID) error {
+	ban, err := s.banRepo.GetActiveBan(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return banError(ban)
+}
+
+func banError(ban *models.UserBan) error {
+	if ban.IsPermanent() {
+		return fmt.Errorf("%w permanently: %s", ErrUserBanned, ban.Reason)
+	}
+	return fmt.Errorf("%w until %s: %s", ErrUserBanned, ban.ExpiresAt.UTC().Format(time.RFC3339), ban.Reason)
+}
+
+func (s *AuthService) refreshExpiry() time.Time {
+	return time.Now().Add(time.Duration(s.cfg.JWT.RefreshHours) * time.Hour)
+}
+
+func revokeSession(repos *repositories.Repositories, sessionID uuid.UUID) error {
+	if err := repos.Sessions.Revoke(sessionID); err != nil {
+		return err
+	}
+	return repos.RefreshTokens.RevokeFamily(sessionID)
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+// startSession records a new device session and issues its first tokens.
+func (s *AuthService) startSession(repos *repositories.Repositories, user *models.User, deviceName string, client ClientInfo) (*AuthResponse, error) {
+	session := &models.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		DeviceName: deviceName,
+		IPAddress:  client.IP,
+		UserAgent:  truncate(client.UserAgent, 255),
+		LastSeenAt: time.Now(),
+		ExpiresAt:  s.refreshExpiry(),
+	}
+	if err := repos.Sessions.Create(session); err != nil {
+		return nil, err
+	}
+	if err := repos.Stats.RecordActivity(user.ID, session.LastSeenAt); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(repos.RefreshTokens, user, session)
+}
+
+// issueTokens creates a refresh token in the session's family and signs a
+// matching access token.
+func (s *AuthService) issueTokens(tokens repositories.RefreshTokenRepository, user *models.User, session *models.Session) (*AuthResponse, error) {
+	refreshToken, hash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tokens.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  session.ID,
+		TokenHash: hash,
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessTTL := time.Duration(s.cfg.JWT.AccessMinutes) * time.Minute
+	accessToken, err := utils.GenerateJWT(
 		user.ID,
+		session.ID,
 		user.Email,
 		string(user.Role),
 		s.cfg.JWT.Secret,
-		s.cfg.JWT.ExpireHours,
+		accessTTL,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	return &AuthResponse{
-		Token: token,
-		User:  user.ToResponse(),
+		Token:        accessToken,
+		User:         user.ToResponse(),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTTL.Seconds()),
 	}, nil
 }
 
@@ -199,21 +464,27 @@ func (s *AuthService) UpdateAvatar(userID uuid.UUID, avatarURL string) (*models.
 	return &response, nil
 }
 
-func (s *AuthService) DeleteAccount(userID uuid.UUID) error {
+func (s *AuthService) DeleteAccount(actor Actor) error {
 	// In a real implementation, you might want to:
 	// 1. Soft delete (mark as deleted)
 	// 2. Remove personal data but keep game data for analytics
 	// 3. Transfer guild ownership if user is guild owner
 	// For now, we'll do a soft delete by updating a deleted_at field
-	
-	user, err := s.userRepo.GetByID(userID)
-	if err != nil {
-		return err
-	}
 
-	// Mark user as deleted (you might want to add a DeletedAt field to User model)
-	user.Email = "deleted_" + user.Email
-	user.Username = "deleted_" + user.Username
-	
-	return s.userRepo.Update(user)
-}
\ No newline at end of file
+	return s.uow.Do(func(repos *repositories.Repositories) error {
+		user, err := repos.Users.GetByIDForUpdate(actor.UserID)
+		if err != nil {
+			return err
+		}
+		before := user.ToResponse()
+
+		// Mark user as deleted (you might want to add a DeletedAt field to User model)
+		user.Email = "deleted_" + user.Email
+		user.Username = "deleted_" + user.Username
+
+		if err := repos.Users.Update(user); err != nil {
+			return err
+		}
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionAccountDelete, models.AuditTargetUser, user.ID, before, user.ToResponse())
+	})
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ProfanityFilter cleans chat text before it is stored and delivered.
+// Filter returns the text to keep and whether anything was changed.
+type ProfanityFilter interface {
+	Filter(text string) (string, bool)
+}
+
+// WordListFilter masks whole words from a fixed list, ignoring case.
+type WordListFilter struct {
+	words map[string]bool
+}
+
+func NewWordListFilter(words []string) *WordListFilter {
+	f := &WordListFilter{words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		f.words[strings.ToLower(word)] = true
+	}
+	return f
+}
+
+func (f *WordListFilter) Filter(text string) (string, bool) {
+	if len(f.words) == 0 {
+		return text, false
+	}
+
+	runes := []rune(text)
+	changed := false
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if f.words[strings.ToLower(string(runes[start:end]))] {
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+			changed = true
+		}
+		start = end
+	}
+	return string(runes), changed
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/utils"
+	"code-valley-api/internal/websocket"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrChatSlowMode        = errors.New("slow mode is on")
+	ErrChatBlocked         = errors.New("you cannot whisper this user")
+	ErrNotGuildMember      = errors.New("you are not a member of this guild")
+	ErrChatMessageNotFound = errors.New("chat message not found")
+	ErrChatNoMap           = errors.New("you are not on a map")
+)
+
+type SendChatRequest struct {
+	Channel models.ChatChannel `json:"channel" validate:"required,oneof=global map guild whisper"`
+	Content string             `json:"content" validate:"required"`
+	GuildID *uuid.UUID         `json:"guild_id"` // guild channel only
+	To      string             `json:"to"`       // whisper recipient's username
+}
+
+type MuteUserRequest struct {
+	Minutes int `json:"minutes" validate:"min=0"` // 0 mutes until lifted
+}
+
+// ChatService posts messages to global, map, guild and whisper channels and
+// delivers them to connected players, skipping anyone who muted or blocked
+// the sender.
+type ChatService struct {
+	chatRepo   repositories.ChatRepository
+	friendRepo repositories.FriendRepository
+	userRepo   repositories.UserRepository
+	uow        repositories.UnitOfWork
+	maps       websocket.MapLocator
+	filter     ProfanityFilter
+	cfg        config.ChatConfig
+}
+
+func NewChatService(cfg *config.Config, chatRepo repositories.ChatRepository, friendRepo repositories.FriendRepository, userRepo repositories.UserRepository, maps websocket.MapLocator, filter ProfanityFilter, uow repositories.UnitOfWork) *ChatService {
+	return &ChatService{
+		chatRepo:   chatRepo,
+		friendRepo: friendRepo,
+		userRepo:   userRepo,
+		uow:        uow,
+		maps:       maps,
+		filter:     filter,
+		cfg:        cfg.Chat,
+	}
+}
+
+// RegisterSocketHandlers adds posting to the WebSocket protocol.
+func (s *ChatService) RegisterSocketHandlers(r *websocket.Registry) {
+	websocket.Handle(r, "chat", "Post a message to a chat channel.",
+		func(ctx *websocket.Context, req SendChatRequest) (*models.ChatMessageResponse, error) {
+			message, err := s.SendMessage(ctx.UserID, req)
+			return message, socketError(err)
+		})
+}
+
+// SendMessage stores a message and pushes it as chat_message to everyone in
+// the channel who is connected.
+func (s *ChatService) SendMessage(senderID uuid.UUID, req SendChatRequest) (*models.ChatMessageResponse, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, errors.New("message is empty")
+	}
+	if s.cfg.MaxLength > 0 && utf8.RuneCountInString(content) > s.cfg.MaxLength {
+		return nil, fmt.Errorf("message is longer than %d characters", s.cfg.MaxLength)
+	}
+
+	sender, err := s.userRepo.GetByID(senderID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	msg := &models.ChatMessage{
+		Channel:  req.Channel,
+		SenderID: senderID,
+	}
+	if err := s.resolveTarget(msg, req); err != nil {
+		return nil, err
+	}
+
+	msg.Content, msg.Filtered = s.filter.Filter(content)
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		// Locking the sender makes checking slow mode and posting one step
+		// for all of a user's connections, whichever node they are on
+		if _, err := repos.Users.GetByIDForUpdate(senderID); err != nil {
+			return err
+		}
+		if window := s.slowModeWindow(msg.Channel); window > 0 {
+			last, err := repos.Chat.GetLastSentAt(senderID, msg.Channel, msg.ScopeID)
+			if err != nil {
+				return err
+			}
+			if remaining := window - time.Since(last); remaining > 0 {
+				return fmt.Errorf("%w: wait %d seconds", ErrChatSlowMode, int(math.Ceil(remaining.Seconds())))
+			}
+		}
+		return repos.Chat.CreateMessage(msg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Sender = *sender
+	response := msg.ToResponse()
+	s.deliver(msg, websocket.Message{
+		Type:   "chat_message",
+		UserID: senderID,
+		Data:   response,
+	})
+	return &response, nil
+}
+
+// resolveTarget fills in the map, guild or recipient the message goes to.
+func (s *ChatService) resolveTarget(msg *models.ChatMessage, req SendChatRequest) error {
+	switch req.Channel {
+	case models.ChatChannelMap:
+		mapID, err := s.maps.CurrentMapID(msg.SenderID)
+		if err != nil {
+			return ErrChatNoMap
+		}
+		msg.ScopeID = &mapID
+
+	case models.ChatChannelGuild:
+		if req.GuildID == nil {
+			return errors.New("guild_id is required for guild chat")
+		}
+		if err := s.requireGuildMember(*req.GuildID, msg.SenderID); err != nil {
+			return err
+		}
+		msg.ScopeID = req.GuildID
+
+	case models.ChatChannelWhisper:
+		recipient, err := s.userRepo.GetByUsername(req.To)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		if recipient.ID == msg.SenderID {
+			return errors.New("cannot whisper yourself")
+		}
+		blocked, err := s.friendRepo.GetBlockedIDs(msg.SenderID)
+		if err != nil {
+			return err
+		}
+		if containsID(blocked, recipient.ID) {
+			return ErrChatBlocked
+		}
+		msg.RecipientID = &recipient.ID
+	}
+	return nil
+}
+
+func (s *ChatService) requireGuildMember(guildID, userID uuid.UUID) error {
+	members, err := s.chatRepo.GetGuildMemberIDs(guildID)
+	if err != nil {
+		return err
+	}
+	if !containsID(members, userID) {
+		return ErrNotGuildMember
+	}
+	return nil
+}
+
+func (s *ChatService) slowModeWindow(channel models.ChatChannel) time.Duration {
+	return time.Duration(s.cfg.SlowMode[string(channel)]) * time.Second
+}
+
+// deliver pushes a message event to the connected audience of msg. Users
+// who muted or blocked the sender are skipped, but the sender always gets
+// their own copy.
+func (s *ChatService) deliver(msg *models.ChatMessage, event websocket.Message) {
+	recipients, err := s.audience(msg)
+	if err != nil {
+		return
+	}
+
+	hidden := make(map[uuid.UUID]bool)
+	if muters, err := s.chatRepo.GetMuterIDs(msg.SenderID); err == nil {
+		for _, id := range muters {
+			hidden[id] = true
+		}
+	}
+	if blocked, err := s.friendRepo.GetBlockedIDs(msg.SenderID); err == nil {
+		for _, id := range blocked {
+			hidden[id] = true
+		}
+	}
+
+	for _, userID := range recipients {
+		if userID == msg.SenderID || !hidden[userID] {
+			websocket.SendToUser(userID, event)
+		}
+	}
+}
+
+// audience lists the users a message is shown to. Only connected users
+// receive anything, so global and map chat go to current connections.
+func (s *ChatService) audience(msg *models.ChatMessage) ([]uuid.UUID, error) {
+	switch msg.Channel {
+	case models.ChatChannelGlobal:
+		return websocket.OnlineUsers(), nil
+	case models.ChatChannelMap:
+		return websocket.MapUsers(*msg.ScopeID), nil
+	case models.ChatChannelGuild:
+		return s.chatRepo.GetGuildMemberIDs(*msg.ScopeID)
+	case models.ChatChannelWhisper:
+		return []uuid.UUID{msg.SenderID, *msg.RecipientID}, nil
+	}
+	return nil, nil
+}
+
+func (s *ChatService) GetGlobalHistory(userID uuid.UUID, pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
+	return s.history(userID, repositories.ChatHistoryFilter{Channel: models.ChatChannelGlobal}, pagination)
+}
+
+func (s *ChatService) GetMapHistory(userID, mapID uuid.UUID, pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
+	return s.history(userID, repositories.ChatHistoryFilter{Channel: models.ChatChannelMap, ScopeID: &mapID}, pagination)
+}
+
+// GetGuildHistory is only open to members of the guild.
+func (s *ChatService) GetGuildHistory(userID, guildID uuid.UUID, pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
+	if err := s.requireGuildMember(guildID, userID); err != nil {
+		return nil, err
+	}
+	return s.history(userID, repositories.ChatHistoryFilter{Channel: models.ChatChannelGuild, ScopeID: &guildID}, pagination)
+}
+
+// history returns a channel's messages newest first, without messages
+// from users the reader muted or is blocked with.
+func (s *ChatService) history(userID uuid.UUID, filter repositories.ChatHistoryFilter, pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
+	hidden, err := s.hiddenSenders(userID)
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = userID
+	filter.ExcludeSenders = hidden
+
+	messages, total, err := s.chatRepo.GetHistory(filter, pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]interface{}, len(messages))
+	for i, msg := range messages {
+		data[i] = msg.ToResponse()
+	}
+
+	totalPages := int(total) / pagination.PerPage
+	if int(total)%pagination.PerPage > 0 {
+		totalPages++
+	}
+
+	return &models.PaginatedResponse{
+		Data: data,
+		Meta: models.PaginationMeta{
+			CurrentPage: pagination.Page,
+			PerPage:     pagination.PerPage,
+			Total:       int(total),
+			TotalPages:  totalPages,
+		},
+	}, nil
+}
+
+// GetWhisperHistory returns the conversation between a user and the named
+// peer.
+func (s *ChatService) GetWhisperHistory(userID uuid.UUID, username string, pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
+	peer, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return s.history(userID, repositories.ChatHistoryFilter{
+		Channel: models.ChatChannelWhisper,
+		PeerID:  peer.ID,
+	}, pagination)
+}
+
+func (s *ChatService) hiddenSenders(userID uuid.UUID) ([]uuid.UUID, error) {
+	mutes, err := s.chatRepo.GetMutes(userID)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := s.friendRepo.GetBlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mute := range mutes {
+		hidden = append(hidden, mute.MutedUserID)
+	}
+	return hidden, nil
+}
+
+// MuteUser hides another user's chat from userID for Minutes minutes, or
+// until unmuted when Minutes is zero.
+func (s *ChatService) MuteUser(userID uuid.UUID, username string, req MuteUserRequest) (*models.ChatMute, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	target, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if target.ID == userID {
+		return nil, errors.New("cannot mute yourself")
+	}
+
+	mute := &models.ChatMute{
+		UserID:      userID,
+		MutedUserID: target.ID,
+	}
+	if req.Minutes > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.Minutes) * time.Minute)
+		mute.ExpiresAt = &expiresAt
+	}
+
+	if err := s.chatRepo.SaveMute(mute); err != nil {
+		return nil, err
+	}
+	return mute, nil
+}
+
+func (s *ChatService) UnmuteUser(userID uuid.UUID, username string) error {
+	target, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	deleted, err := s.chatRepo.DeleteMute(userID, target.ID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.New("user is not muted")
+	}
+	return nil
+}
+
+func (s *ChatService) GetMutes(userID uuid.UUID) ([]models.ChatMute, error) {
+	return s.chatRepo.GetMutes(userID)
+}
+
+// DeleteMessage removes a message for moderation and tells everyone who
+// could have seen it with chat_message_deleted.
+func (s *ChatService) DeleteMessage(actor Actor, messageID uuid.UUID) error {
+	var msg *models.ChatMessage
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		msg, err = repos.Chat.GetMessage(messageID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrChatMessageNotFound
+			}
+			return err
+		}
+
+		removed, err := repos.Chat.RemoveMessage(messageID, actor.UserID)
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return ErrChatMessageNotFound
+		}
+
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionChatDelete, models.AuditTargetChat, msg.ID, msg.ToResponse(), nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.deliver(msg, websocket.Message{
+		Type: "chat_message_deleted",
+		Data: ChatMessageDeleted{
+			ID:      msg.ID,
+			Channel: msg.Channel,
+			ScopeID: msg.ScopeID,
+		},
+	})
+	return nil
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// fixedMaps places every user on the map they are mapped to.
+type fixedMaps map[uuid.UUID]uuid.UUID
+
+func (m fixedMaps) CurrentMapID(userID uuid.UUID) (uuid.UUID, error) {
+	mapID, ok := m[userID]
+	if !ok {
+		return uuid.Nil, errors.New("not on a map")
+	}
+	return mapID, nil
+}
+
+// newTestChatService is one API node's chat; services made from the same
+// store share their database like nodes do.
+func newTestChatService(repos *repositories.Repositories, maps fixedMaps, uow repositories.UnitOfWork) *ChatService {
+	cfg := &config.Config{Chat: config.ChatConfig{
+		MaxLength: 200,
+		SlowMode:  map[string]int{"global": 60, "map": 60},
+	}}
+	return NewChatService(cfg, repos.Chat, repos.Friends, repos.Users, maps, NewWordListFilter(nil), uow)
+}
+
+func globalChat(content string) SendChatRequest {
+	return SendChatRequest{Channel: models.ChatChannelGlobal, Content: content}
+}
+
+func TestSlowModeAdmitsOnePostPerWindowAcrossNodes(t *testing.T) {
+	store, repos := newTestStore()
+	nodes := []*ChatService{
+		newTestChatService(repos, nil, store.UnitOfWork()),
+		newTestChatService(repos, nil, store.UnitOfWork()),
+	}
+	user := addTestUser(t, repos, "chatter", 0)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admitted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(node *ChatService) {
+			defer wg.Done()
+			_, err := node.SendMessage(user.ID, globalChat("hello"))
+			switch {
+			case err == nil:
+				mu.Lock()
+				admitted++
+				mu.Unlock()
+			case !errors.Is(err, ErrChatSlowMode):
+				t.Errorf("SendMessage: %v, want ErrChatSlowMode", err)
+			}
+		}(nodes[i%len(nodes)])
+	}
+	wg.Wait()
+
+	if admitted != 1 {
+		t.Fatalf("admitted %d concurrent posts, want 1", admitted)
+	}
+	_, err := nodes[1].SendMessage(user.ID, globalChat("again"))
+	if !errors.Is(err, ErrChatSlowMode) || err.Error() != "slow mode is on: wait 60 seconds" {
+		t.Fatalf("SendMessage = %v, want a wait of a minute", err)
+	}
+}
+
+func TestSlowModeIsPerChannelAndScope(t *testing.T) {
+	store, repos := newTestStore()
+	user := addTestUser(t, repos, "chatter", 0)
+	other := addTestUser(t, repos, "listener", 0)
+	maps := fixedMaps{user.ID: uuid.New()}
+	service := newTestChatService(repos, maps, store.UnitOfWork())
+
+	mapChat := SendChatRequest{Channel: models.ChatChannelMap, Content: "hi"}
+	whisper := SendChatRequest{Channel: models.ChatChannelWhisper, Content: "psst", To: other.Username}
+	steps := []struct {
+		name    string
+		req     SendChatRequest
+		moveTo  uuid.UUID
+		wantErr error
+	}{
+		{"global", globalChat("hello"), uuid.Nil, nil},
+		{"map chat after global", mapChat, uuid.Nil, nil},
+		{"map chat again", mapChat, uuid.Nil, ErrChatSlowMode},
+		{"map chat on another map", mapChat, uuid.New(), nil},
+		{"whispers without a window", whisper, uuid.Nil, nil},
+		{"another whisper", whisper, uuid.Nil, nil},
+	}
+	for _, step := range steps {
+		if step.moveTo != uuid.Nil {
+			maps[user.ID] = step.moveTo
+		}
+		if _, err := service.SendMessage(user.ID, step.req); !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: SendMessage = %v, want %v", step.name, err, step.wantErr)
+		}
+	}
+}
+
+func TestSlowModeLetsAFailedPostRetry(t *testing.T) {
+	store, repos := newTestStore()
+	user := addTestUser(t, repos, "chatter", 0)
+
+	failing := newTestChatService(repos, nil, failingCommit{store.UnitOfWork()})
+	if _, err := failing.SendMessage(user.ID, globalChat("lost")); !errors.Is(err, errCommitFailed) {
+		t.Fatalf("SendMessage = %v, want the commit to fail", err)
+	}
+
+	service := newTestChatService(repos, nil, store.UnitOfWork())
+	if _, err := service.SendMessage(user.ID, globalChat("retry")); err != nil {
+		t.Fatalf("post after a failed post: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/pathfinding"
+
+	"github.com/google/uuid"
+)
+
+// blocksMovement reports whether a world object occupies its tile.
+func blocksMovement(obj models.WorldObject) bool {
+	return obj.IsActive && (obj.ObjectType == models.ObjectTypeRock || obj.ObjectType == models.ObjectTypeTree)
+}
+
+// collisionGrid is the walkability of one map: the layout's walkable mask
+// with rocks and trees on top of it. Its paths finder remembers routes
+// until the grid is replaced.
+type collisionGrid struct {
+	width   int
+	height  int
+	blocked []bool
+	layout  models.MapLayout
+	builtAt time.Time
+	paths   *pathfinding.Finder
+}
+
+func newCollisionGrid(mapData *models.Map, objects []models.WorldObject, pathOptions pathfinding.Options) *collisionGrid {
+	grid := &collisionGrid{
+		width:   mapData.Width,
+		height:  mapData.Height,
+		blocked: make([]bool, mapData.Width*mapData.Height),
+		layout:  mapData.Layout,
+		builtAt: time.Now(),
+	}
+
+	// A layout saved before validation existed may not match the map
+	// size, so only rows and columns that exist are read
+	for y, row := range mapData.Layout.Walkable {
+		for x := 0; x < len(row) && y < grid.height && x < grid.width; x++ {
+			grid.blocked[y*grid.width+x] = row[x] == models.TileBlocked
+		}
+	}
+	for _, obj := range objects {
+		if blocksMovement(obj) && grid.InBounds(obj.PosX, obj.PosY) {
+			grid.blocked[obj.PosY*grid.width+obj.PosX] = true
+		}
+	}
+	grid.paths = pathfinding.NewFinder(grid, pathOptions)
+	return grid
+}
+
+func (g *collisionGrid) InBounds(x, y int) bool {
+	return x >= 0 && x < g.width && y >= 0 && y < g.height
+}
+
+func (g *collisionGrid) Walkable(x, y int) bool {
+	return g.InBounds(x, y) && !g.blocked[y*g.width+x]
+}
+
+// InZone reports whether the tile lies in a zone of the given type.
+func (g *collisionGrid) InZone(x, y int, zoneType models.ZoneType) bool {
+	for _, zone := range g.layout.ZonesOf(zoneType) {
+		if zone.Contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// collisionGridTTL bounds how long a grid is reused, so changes made by
+// other processes, such as the map import command or another API node,
+// are picked up without a restart.
+const collisionGridTTL = time.Minute
+
+// collisionCache keeps each map's grid until its layout or objects change.
+// Grids are never modified once built, so callers may hold on to one.
+type collisionCache struct {
+	mu    sync.RWMutex
+	grids map[uuid.UUID]*collisionGrid
+	// generation counts invalidations, so a grid built from data that
+	// changed while it was being built is not cached
+	generation uint64
+}
+
+func newCollisionCache() *collisionCache {
+	return &collisionCache{grids: make(map[uuid.UUID]*collisionGrid)}
+}
+
+func (c *collisionCache) get(mapID uuid.UUID, build func() (*collisionGrid, error)) (*collisionGrid, error) {
+	c.mu.RLock()
+	grid, ok := c.grids[mapID]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok && time.Since(grid.builtAt) < collisionGridTTL {
+		return grid, nil
+	}
+
+	grid, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.grids[mapID] = grid
+	}
+	c.mu.Unlock()
+	return grid, nil
+}
+
+func (c *collisionCache) invalidate(mapID uuid.UUID) {
+	c.mu.Lock()
+	delete(c.grids, mapID)
+	c.generation++
+	c.mu.Unlock()
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/pathfinding"
+)
+
+func TestCollisionInvalidateReachesTheNextRoute(t *testing.T) {
+	store, repos := newTestStore()
+	service := newTestWorldService(store, repos)
+	user, town := addTestPlayer(t, store, repos, 5, 3, 0, 1)
+
+	route, err := service.FindPlayerPath(user.ID, 4, 1)
+	if err != nil {
+		t.Fatalf("FindPlayerPath: %v", err)
+	}
+	if route.Steps != 4 || route.Path[1] != (pathfinding.Point{X: 2, Y: 1}) {
+		t.Fatalf("route %v, want straight along row 1", route.Path)
+	}
+
+	// Rocks wall off the right half of the map
+	for y := 0; y < 3; y++ {
+		store.AddWorldObject(models.WorldObject{
+			MapID: town.ID, ObjectType: models.ObjectTypeRock, PosX: 2, PosY: y, IsActive: true,
+		})
+	}
+
+	// Until the map is invalidated the cached grid and its remembered
+	// routes are used
+	if cached, err := service.FindPlayerPath(user.ID, 4, 1); err != nil || cached.Steps != 4 {
+		t.Fatalf("FindPlayerPath before invalidating = %v, %v, want the cached route", cached, err)
+	}
+
+	service.collision.invalidate(town.ID)
+	if route, err := service.FindPlayerPath(user.ID, 4, 1); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("FindPlayerPath after invalidating = %v, %v, want ErrNoRoute", route, err)
+	}
+	if _, err := service.FindPlayerPath(user.ID, 2, 1); !errors.Is(err, ErrPositionBlocked) {
+		t.Fatalf("FindPlayerPath onto a rock = %v, want ErrPositionBlocked", err)
+	}
+}
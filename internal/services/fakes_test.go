@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/repositories/memory"
+	"code-valley-api/internal/utils"
+)
+
+var errCommitFailed = errors.New("commit failed")
+
+// failingCommit runs each unit of work in full and then fails it, like a
+// database that rejects the commit, so the writes must be rolled back.
+type failingCommit struct {
+	uow repositories.UnitOfWork
+}
+
+func (f failingCommit) Do(fn func(repos *repositories.Repositories) error) error {
+	return f.uow.Do(func(repos *repositories.Repositories) error {
+		if err := fn(repos); err != nil {
+			return err
+		}
+		return errCommitFailed
+	})
+}
+
+func addTestUser(t *testing.T, repos *repositories.Repositories, name string, coins int) *models.User {
+	t.Helper()
+	user := &models.User{Email: name + "@example.com", Username: name, PasswordHash: "x", Coins: coins}
+	if err := repos.Users.Create(user); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
+
+func newTestStore() (*memory.Store, *repositories.Repositories) {
+	store := memory.NewStore()
+	return store, store.Repositories()
+}
+
+var defaultTestPage = utils.PaginationParams{Page: 1, PerPage: 50}
@@ -13,14 +13,14 @@ import (
 )
 
 type FriendService struct {
-	friendRepo *repositories.FriendRepository
-	userRepo   *repositories.UserRepository
+	friendRepo repositories.FriendRepository
+	userRepo   repositories.UserRepository
 }
 
-func NewFriendService() *FriendService {
+func NewFriendService(friendRepo repositories.FriendRepository, userRepo repositories.UserRepository) *FriendService {
 	return &FriendService{
-		friendRepo: repositories.NewFriendRepository(),
-		userRepo:   repositories.NewUserRepository(),
+		friendRepo: friendRepo,
+		userRepo:   userRepo,
 	}
 }
 
@@ -124,6 +124,11 @@ func (s *FriendService) RemoveFriend(userID uuid.UUID, username string) error {
 		return err
 	}
 
+	// Blocks are lifted with UnblockUser, and only by the blocker
+	if existing, err := s.friendRepo.GetFriendship(userID, friend.ID); err == nil && existing.Status == models.FriendshipStatusBlocked {
+		return errors.New("user is blocked")
+	}
+
 	// Delete friendship
 	if err := s.friendRepo.DeleteFriendship(userID, friend.ID); err != nil {
 		return err
@@ -132,6 +137,76 @@ func (s *FriendService) RemoveFriend(userID uuid.UUID, username string) error {
 	return nil
 }
 
-func (s *FriendService) GetOnlineFriends(userID uuid.UUID) ([]models.User, error) {
-	return s.friendRepo.GetOnlineFriends(userID)
+// BlockUser blocks another user, replacing any friendship or pending
+// request. Blocked users cannot whisper each other or see each other's
+// chat.
+func (s *FriendService) BlockUser(userID uuid.UUID, username string) error {
+	target, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+
+	if userID == target.ID {
+		return errors.New("cannot block yourself")
+	}
+
+	friendship, err := s.friendRepo.GetFriendship(userID, target.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return s.friendRepo.CreateFriendship(&models.Friendship{
+			RequesterID: userID,
+			AddresseeID: target.ID,
+			Status:      models.FriendshipStatusBlocked,
+		})
+	}
+
+	if friendship.Status == models.FriendshipStatusBlocked {
+		return errors.New("user is already blocked")
+	}
+
+	// The requester of a blocked friendship is the blocker
+	friendship.RequesterID = userID
+	friendship.AddresseeID = target.ID
+	friendship.Status = models.FriendshipStatusBlocked
+	return s.friendRepo.UpdateFriendship(friendship)
+}
+
+func (s *FriendService) UnblockUser(userID uuid.UUID, username string) error {
+	target, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+
+	friendship, err := s.friendRepo.GetFriendship(userID, target.ID)
+	if err != nil || friendship.Status != models.FriendshipStatusBlocked || friendship.RequesterID != userID {
+		return errors.New("user is not blocked")
+	}
+
+	return s.friendRepo.DeleteFriendship(userID, target.ID)
+}
+
+// GetOnlineFriends lists online friends with the status each one shows.
+func (s *FriendService) GetOnlineFriends(userID uuid.UUID) ([]models.FriendPresence, error) {
+	online, err := s.friendRepo.GetOnlineFriends(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	friends := make([]models.FriendPresence, 0, len(online))
+	for _, presence := range online {
+		friends = append(friends, models.FriendPresence{
+			User:     presence.User,
+			Status:   presence.VisibleStatus(),
+			LastSeen: presence.LastSeen,
+		})
+	}
+	return friends, nil
 }
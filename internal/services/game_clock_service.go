@@ -10,14 +10,16 @@ import (
 )
 
 type GameClockService struct {
-	worldRepo *repositories.WorldRepository
+	worldRepo repositories.WorldRepository
+	world     *WorldService
 	ticker    *time.Ticker
 	stopChan  chan bool
 }
 
-func NewGameClockService() *GameClockService {
+func NewGameClockService(worldRepo repositories.WorldRepository, world *WorldService) *GameClockService {
 	return &GameClockService{
-		worldRepo: repositories.NewWorldRepository(),
+		worldRepo: worldRepo,
+		world:     world,
 		stopChan:  make(chan bool),
 	}
 }
@@ -70,13 +72,13 @@ func (s *GameClockService) updateGameTime() {
 			clock.GameHour = 0
 			clock.GameDay++
 			
-			// Handle daily events
-			s.handleDailyEvents(clock)
-			
-			if clock.GameDay > 28 {
+			if clock.GameDay > models.DaysPerSeason {
 				clock.GameDay = 1
 				s.advanceSeason(clock)
 			}
+
+			// Handle daily events
+			s.handleDailyEvents(clock)
 		}
 	}
 
@@ -90,15 +92,18 @@ func (s *GameClockService) updateGameTime() {
 	// Broadcast time update to all clients
 	websocket.GlobalHub.SendToAll(websocket.Message{
 		Type: "time_update",
-		Data: map[string]interface{}{
-			"game_year":   clock.GameYear,
-			"game_season": clock.GameSeason,
-			"game_day":    clock.GameDay,
-			"game_hour":   clock.GameHour,
-			"game_minute": clock.GameMinute,
+		Data: TimeUpdate{
+			GameYear:   clock.GameYear,
+			GameSeason: clock.GameSeason,
+			GameDay:    clock.GameDay,
+			GameHour:   clock.GameHour,
+			GameMinute: clock.GameMinute,
 		},
 	})
 
+	// NPCs follow their schedules a few steps every tick
+	s.world.AdvanceNPCs(clock)
+
 	// Handle hourly events
 	if clock.GameMinute == 0 {
 		s.handleHourlyEvents(clock)
@@ -108,18 +113,20 @@ func (s *GameClockService) updateGameTime() {
 func (s *GameClockService) handleDailyEvents(clock *models.GameClock) {
 	log.Printf("New day: Year %d, %s %d", clock.GameYear, clock.GameSeason, clock.GameDay)
 	
+	// Depleted trees and rocks grow back and chests refill
+	s.world.RespawnWorldObjects(clock)
+
 	// Reset daily tasks, update code farms, etc.
 	// This would trigger other services to handle daily resets
 }
 
 func (s *GameClockService) handleHourlyEvents(clock *models.GameClock) {
-	// Handle NPC movement based on schedules
 	// Update world objects
 	// Process code farm growth
 }
 
 func (s *GameClockService) advanceSeason(clock *models.GameClock) {
-	seasons := []string{"spring", "summer", "fall", "winter"}
+	seasons := models.Seasons
 	currentIndex := 0
 	
 	for i, season := range seasons {
@@ -141,9 +148,9 @@ func (s *GameClockService) advanceSeason(clock *models.GameClock) {
 	// Broadcast season change
 	websocket.GlobalHub.SendToAll(websocket.Message{
 		Type: "season_change",
-		Data: map[string]interface{}{
-			"new_season": clock.GameSeason,
-			"game_year":  clock.GameYear,
+		Data: SeasonChange{
+			NewSeason: clock.GameSeason,
+			GameYear:  clock.GameYear,
 		},
 	})
 }
\ No newline at end of file
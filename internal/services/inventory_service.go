@@ -10,14 +10,16 @@ import (
 )
 
 type InventoryService struct {
-	inventoryRepo *repositories.InventoryRepository
-	userRepo      *repositories.UserRepository
+	inventoryRepo repositories.InventoryRepository
+	userRepo      repositories.UserRepository
+	uow           repositories.UnitOfWork
 }
 
-func NewInventoryService() *InventoryService {
+func NewInventoryService(inventoryRepo repositories.InventoryRepository, userRepo repositories.UserRepository, uow repositories.UnitOfWork) *InventoryService {
 	return &InventoryService{
-		inventoryRepo: repositories.NewInventoryRepository(),
-		userRepo:      repositories.NewUserRepository(),
+		inventoryRepo: inventoryRepo,
+		userRepo:      userRepo,
+		uow:           uow,
 	}
 }
 
@@ -30,52 +32,87 @@ func (s *InventoryService) GetUserItem(userID, itemID uuid.UUID) (*models.Invent
 }
 
 func (s *InventoryService) UseItem(userID, itemID uuid.UUID) (map[string]interface{}, error) {
-	item, err := s.inventoryRepo.GetUserItem(userID, itemID)
-	if err != nil {
-		return nil, errors.New("item not found")
-	}
-
-	if item.Quantity <= 0 {
-		return nil, errors.New("no items to use")
-	}
-
-	// Apply item effects based on item type
-	user, err := s.userRepo.GetByID(userID)
+	var (
+		item    *models.Inventory
+		effects map[string]interface{}
+	)
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		user, err := repos.Users.GetByIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
+
+		item, err = repos.Inventory.GetUserItemForUpdate(userID, itemID)
+		if err != nil {
+			return errors.New("item not found")
+		}
+
+		if item.Quantity <= 0 {
+			return errors.New("no items to use")
+		}
+
+		// Apply item effects based on item type
+		effects = s.applyItemEffects(user, item)
+
+		// Reduce item quantity
+		item.Quantity--
+		if item.Quantity <= 0 {
+			err = repos.Inventory.RemoveItem(userID, itemID)
+		} else {
+			err = repos.Inventory.UpdateItem(item)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Update user
+		return repos.Users.Update(user)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	effects := s.applyItemEffects(user, item)
-
-	// Reduce item quantity
-	item.Quantity--
-	if item.Quantity <= 0 {
-		s.inventoryRepo.RemoveItem(userID, itemID)
-	} else {
-		s.inventoryRepo.UpdateItem(item)
-	}
-
-	// Update user
-	s.userRepo.Update(user)
-
 	return map[string]interface{}{
 		"effects": effects,
 		"remaining": item.Quantity,
 	}, nil
 }
 
+// EquipItem equips a tool, unequipping any other. The equipped tool can
+// unlock loot table entries.
 func (s *InventoryService) EquipItem(userID, itemID uuid.UUID) (map[string]interface{}, error) {
-	item, err := s.inventoryRepo.GetUserItem(userID, itemID)
+	var item *models.Inventory
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		item, err = repos.Inventory.GetUserItemForUpdate(userID, itemID)
+		if err != nil {
+			return errors.New("item not found")
+		}
+
+		if item.ItemType != models.ItemTypeTool {
+			return errors.New("only tools can be equipped")
+		}
+
+		items, err := repos.Inventory.GetUserInventory(userID)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			if items[i].Equipped && items[i].ID != item.ID {
+				items[i].Equipped = false
+				if err := repos.Inventory.UpdateItem(&items[i]); err != nil {
+					return err
+				}
+			}
+		}
+
+		item.Equipped = true
+		return repos.Inventory.UpdateItem(item)
+	})
 	if err != nil {
-		return nil, errors.New("item not found")
-	}
-
-	if item.ItemType != models.ItemTypeTool {
-		return nil, errors.New("only tools can be equipped")
+		return nil, err
 	}
 
-	// In a real implementation, you'd have an equipped_items table
-	// For now, we'll just return success
 	return map[string]interface{}{
 		"equipped": true,
 		"item": item,
@@ -83,9 +120,19 @@ func (s *InventoryService) EquipItem(userID, itemID uuid.UUID) (map[string]inter
 }
 
 func (s *InventoryService) UnequipItem(userID, itemID uuid.UUID) (map[string]interface{}, error) {
-	item, err := s.inventoryRepo.GetUserItem(userID, itemID)
+	var item *models.Inventory
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		item, err = repos.Inventory.GetUserItemForUpdate(userID, itemID)
+		if err != nil {
+			return errors.New("item not found")
+		}
+
+		item.Equipped = false
+		return repos.Inventory.UpdateItem(item)
+	})
 	if err != nil {
-		return nil, errors.New("item not found")
+		return nil, err
 	}
 
 	return map[string]interface{}{
@@ -6,12 +6,12 @@ import (
 )
 
 type LeaderboardService struct {
-	leaderboardRepo *repositories.LeaderboardRepository
+	leaderboardRepo repositories.LeaderboardRepository
 }
 
-func NewLeaderboardService() *LeaderboardService {
+func NewLeaderboardService(leaderboardRepo repositories.LeaderboardRepository) *LeaderboardService {
 	return &LeaderboardService{
-		leaderboardRepo: repositories.NewLeaderboardRepository(),
+		leaderboardRepo: leaderboardRepo,
 	}
 }
 
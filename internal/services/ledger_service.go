@@ -0,0 +1,185 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// signupBonus is the balance every new wallet is opened with.
+const signupBonus = 100
+
+// largeCoinMovement is the smallest wallet change, in either direction, that
+// is written to the audit log.
+const largeCoinMovement = 1000
+
+// postCoins moves amount coins between a player's wallet and a system
+// account and updates the cached User.Coins to match. It must run inside a
+// unit of work with the user row locked. Large movements are audited.
+func postCoins(repos *repositories.Repositories, client ClientInfo, user *models.User, amount int, counterparty models.LedgerAccount, reason models.LedgerReason, referenceType string, referenceID uuid.UUID) error {
+	if err := recordCoins(repos, user.ID, amount, counterparty, reason, referenceType, referenceID); err != nil {
+		return err
+	}
+
+	before := user.Coins
+	user.Coins += amount
+	if err := repos.Users.UpdateCoins(user.ID, user.Coins); err != nil {
+		return err
+	}
+
+	if amount >= largeCoinMovement || amount <= -largeCoinMovement {
+		actor := Actor{UserID: user.ID, ClientInfo: client}
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionLargeCoinMove, models.AuditTargetUser, user.ID,
+			map[string]interface{}{"coins": before},
+			map[string]interface{}{"coins": user.Coins, "reason": reason, "reference_type": referenceType, "reference_id": referenceID})
+	}
+	return nil
+}
+
+// recordCoins writes the ledger entries for coins that have already been
+// applied to User.Coins, such as a wallet's opening balance.
+func recordCoins(repos *repositories.Repositories, userID uuid.UUID, amount int, counterparty models.LedgerAccount, reason models.LedgerReason, referenceType string, referenceID uuid.UUID) error {
+	if amount == 0 {
+		return nil
+	}
+	return repos.Ledger.Post(models.NewCoinTransfer(userID, amount, counterparty, reason, referenceType, referenceID))
+}
+
+type ReconciliationReport struct {
+	CheckedAt              time.Time          `json:"checked_at"`
+	Drift                  []models.CoinDrift `json:"drift"`
+	UnbalancedTransactions []uuid.UUID        `json:"unbalanced_transactions"`
+}
+
+type LedgerService struct {
+	ledgerRepo repositories.LedgerRepository
+	ticker     *time.Ticker
+	stopChan   chan bool
+
+	mu         sync.RWMutex
+	lastReport *ReconciliationReport
+}
+
+func NewLedgerService(ledgerRepo repositories.LedgerRepository) *LedgerService {
+	return &LedgerService{
+		ledgerRepo: ledgerRepo,
+		stopChan:   make(chan bool),
+	}
+}
+
+func (s *LedgerService) GetHistory(userID uuid.UUID, pagination utils.PaginationParams) (*models.PaginatedResponse, error) {
+	entries, total, err := s.ledgerRepo.GetUserHistory(userID, pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		data[i] = entry
+	}
+
+	totalPages := int(total) / pagination.PerPage
+	if int(total)%pagination.PerPage > 0 {
+		totalPages++
+	}
+
+	return &models.PaginatedResponse{
+		Data: data,
+		Meta: models.PaginationMeta{
+			CurrentPage: pagination.Page,
+			PerPage:     pagination.PerPage,
+			Total:       int(total),
+			TotalPages:  totalPages,
+		},
+	}, nil
+}
+
+func (s *LedgerService) GetBalance(userID uuid.UUID) (int, error) {
+	return s.ledgerRepo.GetBalance(userID)
+}
+
+// Reconcile checks that every transaction balances and that every cached
+// User.Coins matches the ledger, logging anything that does not.
+func (s *LedgerService) Reconcile() (*ReconciliationReport, error) {
+	drift, err := s.ledgerRepo.GetDrift()
+	if err != nil {
+		return nil, err
+	}
+
+	unbalanced, err := s.ledgerRepo.GetUnbalancedTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{
+		CheckedAt:              time.Now(),
+		Drift:                  drift,
+		UnbalancedTransactions: unbalanced,
+	}
+	if report.Drift == nil {
+		report.Drift = []models.CoinDrift{}
+	}
+	if report.UnbalancedTransactions == nil {
+		report.UnbalancedTransactions = []uuid.UUID{}
+	}
+
+	for _, d := range drift {
+		log.Printf("Coin drift for user %s (%s): cached %d, ledger %d", d.Username, d.UserID, d.CachedBalance, d.LedgerBalance)
+	}
+	for _, id := range unbalanced {
+		log.Printf("Unbalanced coin transaction %s", id)
+	}
+
+	s.mu.Lock()
+	s.lastReport = report
+	s.mu.Unlock()
+
+	return report, nil
+}
+
+// LastReport returns the most recent reconciliation, or nil if none has run.
+func (s *LedgerService) LastReport() *ReconciliationReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastReport
+}
+
+// StartReconciliation runs Reconcile now and then on every interval.
+func (s *LedgerService) StartReconciliation(interval time.Duration) {
+	s.ticker = time.NewTicker(interval)
+
+	go func() {
+		s.reconcile()
+		for {
+			select {
+			case <-s.ticker.C:
+				s.reconcile()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+
+	log.Printf("Coin ledger reconciliation started (every %s)", interval)
+}
+
+func (s *LedgerService) Stop() {
+	if s.ticker == nil {
+		return
+	}
+	s.ticker.Stop()
+	s.stopChan <- true
+	log.Println("Coin ledger reconciliation stopped")
+}
+
+func (s *LedgerService) reconcile() {
+	if _, err := s.Reconcile(); err != nil {
+		log.Printf("Coin ledger reconciliation failed: %v", err)
+	}
+}
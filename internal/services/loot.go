@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LootContext is who a loot table is rolled for, and when, to check its
+// entries' conditions against.
+type LootContext struct {
+	Season string `json:"season"`
+	Level  int    `json:"level"`
+	// Tool is the name of the player's equipped tool, if any
+	Tool string `json:"tool"`
+}
+
+// LootDrop is an item a roll dropped.
+type LootDrop struct {
+	ItemName string            `json:"item_name"`
+	ItemType models.ItemType   `json:"item_type"`
+	Quantity int               `json:"quantity"`
+	Rarity   models.LootRarity `json:"rarity"`
+}
+
+// LootRoller rolls loot tables with a seeded random source, so rollers
+// built with the same seed drop the same items in the same order. It is
+// safe for concurrent use.
+type LootRoller struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func NewLootRoller(seed int64) *LootRoller {
+	return &LootRoller{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Roll draws the table's rolls from the entries whose conditions match,
+// merging drops of the same item. Entries that draw nothing are left out.
+func (r *LootRoller) Roll(table *models.LootTable, ctx LootContext) []LootDrop {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, total := eligibleEntries(table, ctx)
+	var drops []LootDrop
+	for i := 0; i < table.Rolls && total > 0; i++ {
+		entry := drawEntry(r.rng, entries, total)
+		if entry.ItemName == "" {
+			continue
+		}
+		drops = addDrop(drops, entry, drawQuantity(r.rng, entry))
+	}
+	return drops
+}
+
+// eligibleEntries returns the entries that can drop in ctx and their total
+// weight.
+func eligibleEntries(table *models.LootTable, ctx LootContext) ([]models.LootEntry, int) {
+	var entries []models.LootEntry
+	total := 0
+	for _, entry := range table.Entries {
+		if entry.Weight > 0 && entry.Conditions.Matches(ctx.Season, ctx.Level, ctx.Tool) {
+			entries = append(entries, entry)
+			total += entry.Weight
+		}
+	}
+	return entries, total
+}
+
+// drawEntry picks an entry with a chance of its weight over total, which
+// must be the entries' total weight.
+func drawEntry(rng *rand.Rand, entries []models.LootEntry, total int) models.LootEntry {
+	n := rng.Intn(total)
+	for _, entry := range entries {
+		if n < entry.Weight {
+			return entry
+		}
+		n -= entry.Weight
+	}
+	return entries[len(entries)-1]
+}
+
+func drawQuantity(rng *rand.Rand, entry models.LootEntry) int {
+	if entry.MaxQuantity <= entry.MinQuantity {
+		return entry.MinQuantity
+	}
+	return entry.MinQuantity + rng.Intn(entry.MaxQuantity-entry.MinQuantity+1)
+}
+
+func addDrop(drops []LootDrop, entry models.LootEntry, quantity int) []LootDrop {
+	for i := range drops {
+		if drops[i].ItemName == entry.ItemName {
+			drops[i].Quantity += quantity
+			return drops
+		}
+	}
+	return append(drops, LootDrop{
+		ItemName: entry.ItemName,
+		ItemType: entry.ItemType,
+		Quantity: quantity,
+		Rarity:   entry.Rarity,
+	})
+}
+
+// dropLoot rolls an object's loot table for a player and adds the drops to
+// their inventory in the interaction's transaction. Objects use the table
+// named by their "loot_table" state, or the one named after their type; an
+// object whose table is missing drops nothing.
+func (s *WorldService) dropLoot(repos *repositories.Repositories, userID uuid.UUID, obj *models.WorldObject) ([]LootDrop, error) {
+	name := string(obj.ObjectType)
+	if table, ok := obj.State["loot_table"].(string); ok && table != "" {
+		name = table
+	}
+
+	table, err := repos.Loot.GetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Loot table %q not found", name)
+		return []LootDrop{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	drops := s.loot.Roll(table, s.lootContext(repos, userID))
+	for _, drop := range drops {
+		item := &models.Inventory{
+			UserID:   userID,
+			ItemName: drop.ItemName,
+			Quantity: drop.Quantity,
+			ItemType: drop.ItemType,
+		}
+		if err := repos.Inventory.AddItem(item); err != nil {
+			return nil, err
+		}
+	}
+	if drops == nil {
+		drops = []LootDrop{}
+	}
+	return drops, nil
+}
+
+// lootContext is the season, and the player's level and equipped tool.
+func (s *WorldService) lootContext(repos *repositories.Repositories, userID uuid.UUID) LootContext {
+	var ctx LootContext
+	if clock, err := repos.World.GetGameClock(); err == nil {
+		ctx.Season = clock.GameSeason
+	}
+	if user, err := repos.Users.GetByID(userID); err == nil {
+		ctx.Level = user.Level
+	}
+	if items, err := repos.Inventory.GetUserInventory(userID); err == nil {
+		for _, item := range items {
+			// Only tools can be equipped, but rows written before that rule
+			// or edited by hand must not unlock tool drops
+			if item.Equipped && item.ItemType == models.ItemTypeTool {
+				ctx.Tool = item.ItemName
+			}
+		}
+	}
+	return ctx
+}
+
+// itemsGained describes drops as "Code Snippet x2".
+func itemsGained(drops []LootDrop) []string {
+	gained := make([]string, 0, len(drops))
+	for _, drop := range drops {
+		gained = append(gained, fmt.Sprintf("%s x%d", drop.ItemName, drop.Quantity))
+	}
+	return gained
+}
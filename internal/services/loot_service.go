@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+var ErrLootTableNotFound = errors.New("loot table not found")
+
+type LootService struct {
+	lootRepo repositories.LootRepository
+	uow      repositories.UnitOfWork
+	roller   *LootRoller
+}
+
+func NewLootService(cfg *config.Config, lootRepo repositories.LootRepository, uow repositories.UnitOfWork) *LootService {
+	seed := cfg.Loot.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return NewLootServiceWithRoller(lootRepo, uow, NewLootRoller(seed))
+}
+
+// NewLootServiceWithRoller rolls with the given roller, e.g. one with a
+// fixed seed.
+func NewLootServiceWithRoller(lootRepo repositories.LootRepository, uow repositories.UnitOfWork, roller *LootRoller) *LootService {
+	return &LootService{
+		lootRepo: lootRepo,
+		uow:      uow,
+		roller:   roller,
+	}
+}
+
+func (s *LootService) GetLootTables() ([]models.LootTable, error) {
+	return s.lootRepo.GetAll()
+}
+
+func (s *LootService) GetLootTable(name string) (*models.LootTable, error) {
+	table, err := s.lootRepo.GetByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLootTableNotFound
+	}
+	return table, err
+}
+
+// Roll rolls a table with the service's roller.
+func (s *LootService) Roll(table *models.LootTable, ctx LootContext) []LootDrop {
+	return s.roller.Roll(table, ctx)
+}
+
+type LootTableRequest struct {
+	Description string `json:"description"`
+	// Rolls is how many entries each roll draws; 0 means 1
+	Rolls   int                `json:"rolls"`
+	Entries models.LootEntries `json:"entries"`
+}
+
+// SaveLootTable creates the named table or replaces its contents, reporting
+// whether it was created.
+func (s *LootService) SaveLootTable(actor Actor, name string, req LootTableRequest) (*models.LootTable, bool, error) {
+	if req.Rolls == 0 {
+		req.Rolls = 1
+	}
+	if req.Entries == nil {
+		req.Entries = models.LootEntries{}
+	}
+
+	var table *models.LootTable
+	created := false
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		table, err = repos.Loot.GetByName(name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			table = &models.LootTable{Name: name}
+			created = true
+		} else if err != nil {
+			return err
+		}
+		before := *table
+
+		table.Description = req.Description
+		table.Rolls = req.Rolls
+		table.Entries = req.Entries
+		if err := table.Validate(); err != nil {
+			return err
+		}
+
+		if created {
+			if err := repos.Loot.Create(table); err != nil {
+				return err
+			}
+			return recordAudit(repos.AuditLogs, actor, models.AuditActionLootCreate, models.AuditTargetLoot, table.ID, nil, table)
+		}
+		if err := repos.Loot.Update(table); err != nil {
+			return err
+		}
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionLootUpdate, models.AuditTargetLoot, table.ID, before, table)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return table, created, nil
+}
+
+// DeleteLootTable removes the named table; objects using it drop nothing.
+func (s *LootService) DeleteLootTable(actor Actor, name string) error {
+	return s.uow.Do(func(repos *repositories.Repositories) error {
+		table, err := repos.Loot.GetByName(name)
+		if err != nil {
+			return ErrLootTableNotFound
+		}
+
+		if err := repos.Loot.Delete(table.ID); err != nil {
+			return err
+		}
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionLootDelete, models.AuditTargetLoot, table.ID, table, nil)
+	})
+}
+
+type LootSimulationRequest struct {
+	// Rolls is how many times to roll the table, e.g. chests opened
+	Rolls int `json:"rolls" validate:"min=1,max=100000"`
+	// Seed makes the simulation repeatable; without one a seed is picked
+	// and reported
+	Seed   *int64 `json:"seed"`
+	Season string `json:"season"`
+	Level  int    `json:"level" validate:"min=0"`
+	Tool   string `json:"tool"`
+}
+
+// LootSimulation is how often a table's entries dropped over many rolls.
+// Rates are per draw, next to the rate the weights give.
+type LootSimulation struct {
+	Table   string      `json:"table"`
+	Rolls   int         `json:"rolls"`
+	Seed    int64       `json:"seed"`
+	Context LootContext `json:"context"`
+	// Draws is the rolls times the entries each roll draws
+	Draws int `json:"draws"`
+	// Empty is how many draws dropped nothing
+	Empty             int               `json:"empty"`
+	EmptyRate         float64           `json:"empty_rate"`
+	ExpectedEmptyRate float64           `json:"expected_empty_rate"`
+	Items             []LootItemStats   `json:"items"`
+	Rarities          []LootRarityStats `json:"rarities"`
+}
+
+type LootItemStats struct {
+	ItemName        string            `json:"item_name"`
+	Rarity          models.LootRarity `json:"rarity"`
+	Drops           int               `json:"drops"`
+	Rate            float64           `json:"rate"`
+	ExpectedRate    float64           `json:"expected_rate"`
+	TotalQuantity   int               `json:"total_quantity"`
+	AverageQuantity float64           `json:"average_quantity"`
+}
+
+type LootRarityStats struct {
+	Rarity       models.LootRarity `json:"rarity"`
+	Drops        int               `json:"drops"`
+	Rate         float64           `json:"rate"`
+	ExpectedRate float64           `json:"expected_rate"`
+}
+
+// SimulateLootTable rolls the named table req.Rolls times for a player in
+// req's season, level and tool, without dropping anything, and reports the
+// distribution. It uses its own random source, so the same seed always
+// gives the same report.
+func (s *LootService) SimulateLootTable(name string, req LootSimulationRequest) (*LootSimulation, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+	table, err := s.GetLootTable(name)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	ctx := LootContext{Season: req.Season, Level: req.Level, Tool: req.Tool}
+
+	entries, total := eligibleEntries(table, ctx)
+	sim := &LootSimulation{
+		Table:    table.Name,
+		Rolls:    req.Rolls,
+		Seed:     seed,
+		Context:  ctx,
+		Draws:    req.Rolls * table.Rolls,
+		Items:    []LootItemStats{},
+		Rarities: []LootRarityStats{},
+	}
+
+	// Every item and tier that can drop is listed, even if it never did
+	items := make(map[string]*LootItemStats)
+	rarities := make(map[models.LootRarity]*LootRarityStats)
+	for _, entry := range entries {
+		expected := float64(entry.Weight) / float64(total)
+		if entry.ItemName == "" {
+			sim.ExpectedEmptyRate += expected
+			continue
+		}
+		if items[entry.ItemName] == nil {
+			items[entry.ItemName] = &LootItemStats{ItemName: entry.ItemName, Rarity: entry.Rarity}
+		}
+		items[entry.ItemName].ExpectedRate += expected
+		if rarities[entry.Rarity] == nil {
+			rarities[entry.Rarity] = &LootRarityStats{Rarity: entry.Rarity}
+		}
+		rarities[entry.Rarity].ExpectedRate += expected
+	}
+	if total == 0 {
+		sim.ExpectedEmptyRate = 1
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < sim.Draws; i++ {
+		if total == 0 {
+			sim.Empty++
+			continue
+		}
+		entry := drawEntry(rng, entries, total)
+		if entry.ItemName == "" {
+			sim.Empty++
+			continue
+		}
+		item := items[entry.ItemName]
+		item.Drops++
+		item.TotalQuantity += drawQuantity(rng, entry)
+		rarities[entry.Rarity].Drops++
+	}
+
+	rate := func(count int) float64 {
+		if sim.Draws == 0 {
+			return 0
+		}
+		return float64(count) / float64(sim.Draws)
+	}
+	sim.EmptyRate = rate(sim.Empty)
+	for _, item := range items {
+		item.Rate = rate(item.Drops)
+		if item.Drops > 0 {
+			item.AverageQuantity = float64(item.TotalQuantity) / float64(item.Drops)
+		}
+		sim.Items = append(sim.Items, *item)
+	}
+	sort.Slice(sim.Items, func(i, j int) bool {
+		if sim.Items[i].Drops != sim.Items[j].Drops {
+			return sim.Items[i].Drops > sim.Items[j].Drops
+		}
+		return sim.Items[i].ItemName < sim.Items[j].ItemName
+	})
+	for _, rarity := range models.LootRarities {
+		if stats := rarities[rarity]; stats != nil {
+			stats.Rate = rate(stats.Drops)
+			sim.Rarities = append(sim.Rarities, *stats)
+		}
+	}
+
+	return sim, nil
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories/memory"
+)
+
+func lootEntry(name string, weight, minQuantity, maxQuantity int) models.LootEntry {
+	return models.LootEntry{
+		ItemName:    name,
+		ItemType:    models.ItemTypeResource,
+		Weight:      weight,
+		MinQuantity: minQuantity,
+		MaxQuantity: maxQuantity,
+		Rarity:      models.RarityCommon,
+	}
+}
+
+// rollCounts rolls a table n times and counts how often each item dropped.
+func rollCounts(roller *LootRoller, table *models.LootTable, ctx LootContext, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		for _, drop := range roller.Roll(table, ctx) {
+			counts[drop.ItemName]++
+		}
+	}
+	return counts
+}
+
+func TestLootRollerIsRepeatableForASeed(t *testing.T) {
+	table := &models.LootTable{Name: "chest", Rolls: 2, Entries: models.LootEntries{
+		lootEntry("Debug Tool", 5, 1, 3),
+		lootEntry("Refactor Kit", 3, 1, 1),
+		lootEntry("", 2, 0, 0),
+	}}
+
+	a, b := NewLootRoller(7), NewLootRoller(7)
+	for i := 0; i < 100; i++ {
+		if got, want := a.Roll(table, LootContext{}), b.Roll(table, LootContext{}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("roll %d: rollers with the same seed dropped %v and %v", i, got, want)
+		}
+	}
+}
+
+func TestLootRollerDrawsByWeight(t *testing.T) {
+	table := &models.LootTable{Name: "tree", Rolls: 1, Entries: models.LootEntries{
+		lootEntry("Code Snippet", 6, 1, 1),
+		lootEntry("Golden Snippet", 3, 1, 1),
+		lootEntry("", 1, 0, 0),
+	}}
+
+	const rolls = 20000
+	counts := rollCounts(NewLootRoller(42), table, LootContext{}, rolls)
+	for name, want := range map[string]float64{"Code Snippet": 0.6, "Golden Snippet": 0.3} {
+		if got := float64(counts[name]) / rolls; math.Abs(got-want) > 0.02 {
+			t.Errorf("%s dropped %.3f of rolls, want about %.2f", name, got, want)
+		}
+	}
+	if len(counts) != 2 {
+		t.Errorf("dropped %v, want only the two named items", counts)
+	}
+}
+
+func TestLootRollerQuantities(t *testing.T) {
+	table := &models.LootTable{Name: "rock", Rolls: 1, Entries: models.LootEntries{
+		lootEntry("Raw Data", 1, 2, 4),
+	}}
+
+	roller := NewLootRoller(42)
+	seen := make(map[int]bool)
+	for i := 0; i < 500; i++ {
+		drops := roller.Roll(table, LootContext{})
+		if len(drops) != 1 {
+			t.Fatalf("dropped %v, want one drop", drops)
+		}
+		quantity := drops[0].Quantity
+		if quantity < 2 || quantity > 4 {
+			t.Fatalf("dropped %d, want 2 to 4", quantity)
+		}
+		seen[quantity] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("dropped quantities %v, want every quantity from 2 to 4", seen)
+	}
+}
+
+func TestLootRollerMergesDropsOfTheSameItem(t *testing.T) {
+	table := &models.LootTable{Name: "rock", Rolls: 3, Entries: models.LootEntries{
+		lootEntry("Raw Data", 1, 2, 2),
+	}}
+
+	drops := NewLootRoller(42).Roll(table, LootContext{})
+	if len(drops) != 1 || drops[0].Quantity != 6 {
+		t.Fatalf("dropped %v, want one drop of 6 Raw Data", drops)
+	}
+}
+
+func TestLootRollerConditions(t *testing.T) {
+	summer := lootEntry("Sunflower Seed", 1, 1, 1)
+	summer.Conditions.Seasons = []string{"summer"}
+	veteran := lootEntry("Legacy Codex", 1, 1, 1)
+	veteran.Conditions.MinLevel = 10
+	novice := lootEntry("Hello World", 1, 1, 1)
+	novice.Conditions.MaxLevel = 5
+	axe := lootEntry("Hardwood", 1, 1, 1)
+	axe.Conditions.Tool = "Debug Axe"
+
+	table := &models.LootTable{Name: "tree", Rolls: 1, Entries: models.LootEntries{
+		lootEntry("Code Snippet", 1, 1, 1), summer, veteran, novice, axe,
+	}}
+
+	tests := []struct {
+		name string
+		ctx  LootContext
+		want []string
+	}{
+		{"nothing matches", LootContext{Season: "winter", Level: 7}, []string{"Code Snippet"}},
+		{"season", LootContext{Season: "summer", Level: 7}, []string{"Code Snippet", "Sunflower Seed"}},
+		{"min level", LootContext{Season: "winter", Level: 10}, []string{"Code Snippet", "Legacy Codex"}},
+		{"max level", LootContext{Season: "winter", Level: 5}, []string{"Code Snippet", "Hello World"}},
+		{"tool", LootContext{Season: "winter", Level: 7, Tool: "debug axe"}, []string{"Code Snippet", "Hardwood"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := rollCounts(NewLootRoller(42), table, tt.ctx, 500)
+			if len(counts) != len(tt.want) {
+				t.Fatalf("dropped %v, want only %v", counts, tt.want)
+			}
+			for _, name := range tt.want {
+				if counts[name] == 0 {
+					t.Errorf("never dropped %s, want it among %v", name, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLootRollerWithNothingToDraw(t *testing.T) {
+	spring := lootEntry("Blossom", 1, 1, 1)
+	spring.Conditions.Seasons = []string{"spring"}
+
+	tests := []struct {
+		name    string
+		entries models.LootEntries
+	}{
+		{"no entries", nil},
+		{"no matching entries", models.LootEntries{spring}},
+		{"only empty entries", models.LootEntries{lootEntry("", 1, 0, 0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &models.LootTable{Name: "chest", Rolls: 3, Entries: tt.entries}
+			if drops := NewLootRoller(42).Roll(table, LootContext{Season: "winter"}); len(drops) != 0 {
+				t.Fatalf("dropped %v, want nothing", drops)
+			}
+		})
+	}
+}
+
+func newTestLootService(t *testing.T, tables ...models.LootTable) *LootService {
+	t.Helper()
+	store := memory.NewStore()
+	repos := store.Repositories()
+	for i := range tables {
+		if err := repos.Loot.Create(&tables[i]); err != nil {
+			t.Fatalf("creating loot table %s: %v", tables[i].Name, err)
+		}
+	}
+	return NewLootServiceWithRoller(repos.Loot, store.UnitOfWork(), NewLootRoller(1))
+}
+
+func TestSimulateLootTable(t *testing.T) {
+	rare := lootEntry("Golden Snippet", 1, 1, 1)
+	rare.Rarity = models.RarityRare
+	service := newTestLootService(t, models.LootTable{Name: "tree", Rolls: 2, Entries: models.LootEntries{
+		lootEntry("Code Snippet", 7, 1, 3),
+		rare,
+		lootEntry("", 2, 0, 0),
+	}})
+
+	seed := int64(42)
+	req := LootSimulationRequest{Rolls: 5000, Seed: &seed}
+	sim, err := service.SimulateLootTable("tree", req)
+	if err != nil {
+		t.Fatalf("SimulateLootTable: %v", err)
+	}
+
+	if sim.Seed != seed || sim.Draws != 10000 {
+		t.Errorf("seed %d and %d draws, want %d and 10000", sim.Seed, sim.Draws, seed)
+	}
+	if math.Abs(sim.ExpectedEmptyRate-0.2) > 1e-9 || math.Abs(sim.EmptyRate-0.2) > 0.02 {
+		t.Errorf("empty rate %.3f (expected %.3f), want about 0.2", sim.EmptyRate, sim.ExpectedEmptyRate)
+	}
+
+	items := make(map[string]LootItemStats)
+	drops := sim.Empty
+	for _, item := range sim.Items {
+		items[item.ItemName] = item
+		drops += item.Drops
+	}
+	if drops != sim.Draws {
+		t.Errorf("drops and empty draws add up to %d, want %d", drops, sim.Draws)
+	}
+	snippet := items["Code Snippet"]
+	if math.Abs(snippet.ExpectedRate-0.7) > 1e-9 || math.Abs(snippet.Rate-0.7) > 0.02 {
+		t.Errorf("Code Snippet rate %.3f (expected %.3f), want about 0.7", snippet.Rate, snippet.ExpectedRate)
+	}
+	if snippet.AverageQuantity < 1.9 || snippet.AverageQuantity > 2.1 {
+		t.Errorf("Code Snippet average quantity %.2f, want about 2", snippet.AverageQuantity)
+	}
+	if len(sim.Rarities) != 2 || sim.Rarities[0].Rarity != models.RarityCommon || sim.Rarities[1].Rarity != models.RarityRare {
+		t.Errorf("rarities %v, want common then rare", sim.Rarities)
+	}
+
+	again, err := service.SimulateLootTable("tree", req)
+	if err != nil {
+		t.Fatalf("SimulateLootTable: %v", err)
+	}
+	if !reflect.DeepEqual(sim, again) {
+		t.Errorf("simulations with the same seed differ:\n%+v\n%+v", sim, again)
+	}
+}
+
+func TestSimulateLootTableWithNothingEligible(t *testing.T) {
+	spring := lootEntry("Blossom", 1, 1, 1)
+	spring.Conditions.Seasons = []string{"spring"}
+	service := newTestLootService(t,
+		models.LootTable{Name: "empty", Rolls: 1},
+		models.LootTable{Name: "spring", Rolls: 1, Entries: models.LootEntries{spring}},
+	)
+
+	seed := int64(42)
+	for _, name := range []string{"empty", "spring"} {
+		sim, err := service.SimulateLootTable(name, LootSimulationRequest{Rolls: 10, Seed: &seed, Season: "winter"})
+		if err != nil {
+			t.Fatalf("%s: SimulateLootTable: %v", name, err)
+		}
+		if sim.Empty != 10 || sim.EmptyRate != 1 || sim.ExpectedEmptyRate != 1 || len(sim.Items) != 0 {
+			t.Errorf("%s: %+v, want every draw empty", name, sim)
+		}
+	}
+}
+
+func TestSimulateLootTableErrors(t *testing.T) {
+	service := newTestLootService(t, models.LootTable{Name: "tree", Rolls: 1})
+
+	if _, err := service.SimulateLootTable("missing", LootSimulationRequest{Rolls: 1}); !errors.Is(err, ErrLootTableNotFound) {
+		t.Errorf("missing table: got %v, want ErrLootTableNotFound", err)
+	}
+	if _, err := service.SimulateLootTable("tree", LootSimulationRequest{Rolls: 0}); err == nil {
+		t.Error("zero rolls: got no error")
+	}
+}
+
+func TestLootContextOnlyCountsEquippedTools(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []models.Inventory
+		want  string
+	}{
+		{"nothing equipped", []models.Inventory{
+			{ItemName: "Debug Tool", ItemType: models.ItemTypeTool},
+		}, ""},
+		{"an equipped tool", []models.Inventory{
+			{ItemName: "Code Snippet", ItemType: models.ItemTypeSnippet},
+			{ItemName: "Debug Tool", ItemType: models.ItemTypeTool, Equipped: true},
+		}, "Debug Tool"},
+		{"an equipped resource", []models.Inventory{
+			{ItemName: "Raw Data", ItemType: models.ItemTypeResource, Equipped: true},
+		}, ""},
+		{"an equipped snippet beside an equipped tool", []models.Inventory{
+			{ItemName: "Debug Tool", ItemType: models.ItemTypeTool, Equipped: true},
+			{ItemName: "Code Snippet", ItemType: models.ItemTypeSnippet, Equipped: true},
+		}, "Debug Tool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, repos := newTestStore()
+			service := newTestWorldService(store, repos)
+			user := addTestUser(t, repos, "looter", 0)
+			for i := range tt.items {
+				tt.items[i].UserID, tt.items[i].Quantity = user.ID, 1
+				if err := repos.Inventory.AddItem(&tt.items[i]); err != nil {
+					t.Fatalf("AddItem: %v", err)
+				}
+			}
+
+			if ctx := service.lootContext(repos, user.ID); ctx.Tool != tt.want {
+				t.Errorf("tool %q, want %q", ctx.Tool, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/tiled"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidMapFile = errors.New("invalid map file")
+
+type MapImportOptions struct {
+	// Name is the map to import into, overriding the file's name property
+	Name string
+	// DryRun reports what an import would change without saving it
+	DryRun bool
+}
+
+// ObjectChange identifies a world object by what it is and where.
+type ObjectChange struct {
+	ObjectType models.ObjectType `json:"object_type"`
+	PosX       int               `json:"pos_x"`
+	PosY       int               `json:"pos_y"`
+}
+
+type NPCPlacement struct {
+	NPCName string `json:"npc_name"`
+	PosX    int    `json:"pos_x"`
+	PosY    int    `json:"pos_y"`
+}
+
+// MapImportResult is what an import changed, or would change on a dry run.
+type MapImportResult struct {
+	Map           *models.Map    `json:"map"`
+	DryRun        bool           `json:"dry_run"`
+	MapCreated    bool           `json:"map_created"`
+	MapChanged    bool           `json:"map_changed"`
+	Created       []ObjectChange `json:"created"`
+	Updated       []ObjectChange `json:"updated"`
+	Removed       []ObjectChange `json:"removed"`
+	Unchanged     int            `json:"unchanged"`
+	NPCsPlaced    []NPCPlacement `json:"npcs_placed"`
+	NPCsUnchanged int            `json:"npcs_unchanged"`
+	Warnings      []string       `json:"warnings"`
+}
+
+// ImportTiledMap creates or updates a map from a Tiled JSON or TMX export.
+//
+// World objects are matched to the existing ones by type and tile.
+// Matches keep their runtime state, e.g. a chopped tree stays chopped, and
+// only take the file's properties; objects missing from the file are
+// removed. NPCs in the file are placed at their spawn, moving them from
+// wherever they are. Importing the same file twice changes nothing.
+func (s *WorldService) ImportTiledMap(actor Actor, data []byte, opts MapImportOptions) (*MapImportResult, error) {
+	parsed, err := tiled.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMapFile, err)
+	}
+	imported, err := parsed.Convert()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMapFile, err)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = imported.Name
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: no map name, set the map's name property", ErrInvalidMapFile)
+	}
+
+	result := &MapImportResult{
+		DryRun:     opts.DryRun,
+		Created:    []ObjectChange{},
+		Updated:    []ObjectChange{},
+		Removed:    []ObjectChange{},
+		NPCsPlaced: []NPCPlacement{},
+		Warnings:   imported.Warnings,
+	}
+	if result.Warnings == nil {
+		result.Warnings = []string{}
+	}
+
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		mapData, err := repos.World.GetMapByName(name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			mapData = &models.Map{Name: name, IsActive: true}
+			result.MapCreated = true
+		} else if err != nil {
+			return err
+		}
+
+		before := mapFingerprint(mapData)
+		mapData.Width = imported.Width
+		mapData.Height = imported.Height
+		mapData.Layout = imported.Layout
+		if imported.Type != "" {
+			mapData.Type = imported.Type
+		}
+		if imported.Description != "" {
+			mapData.Description = imported.Description
+		}
+		if mapData.Type == "" {
+			return fmt.Errorf("%w: no map type, set the map's type property", ErrInvalidMapFile)
+		}
+		result.MapChanged = !result.MapCreated && before != mapFingerprint(mapData)
+		result.Map = mapData
+
+		var existing []models.WorldObject
+		if !result.MapCreated {
+			if existing, err = repos.World.GetAllWorldObjects(mapData.ID); err != nil {
+				return err
+			}
+		}
+		create, update, remove := diffWorldObjects(existing, imported.Objects, result)
+
+		type placement struct {
+			position *models.NPCPosition
+			isNew    bool
+		}
+		var placements []placement
+		for _, spawn := range imported.NPCs {
+			npc, err := repos.World.GetNPCByName(spawn.NPCName)
+			if err != nil {
+				return fmt.Errorf("%w: unknown NPC %q", ErrInvalidMapFile, spawn.NPCName)
+			}
+
+			position, err := repos.World.GetNPCPosition(npc.ID)
+			isNew := err != nil
+			if isNew {
+				position = &models.NPCPosition{NPCID: npc.ID}
+			} else if position.MapID == mapData.ID && position.PosX == spawn.PosX &&
+				position.PosY == spawn.PosY && position.Direction == spawn.Direction {
+				result.NPCsUnchanged++
+				continue
+			}
+
+			position.PosX, position.PosY, position.Direction = spawn.PosX, spawn.PosY, spawn.Direction
+			placements = append(placements, placement{position, isNew})
+			result.NPCsPlaced = append(result.NPCsPlaced, NPCPlacement{NPCName: spawn.NPCName, PosX: spawn.PosX, PosY: spawn.PosY})
+		}
+
+		if opts.DryRun {
+			return nil
+		}
+
+		if result.MapCreated {
+			err = repos.World.CreateMap(mapData)
+		} else if result.MapChanged {
+			err = repos.World.UpdateMap(mapData)
+		}
+		if err != nil {
+			return err
+		}
+
+		for i := range create {
+			create[i].MapID = mapData.ID
+			if err := repos.World.CreateWorldObject(&create[i]); err != nil {
+				return err
+			}
+		}
+		for i := range update {
+			if err := repos.World.UpdateWorldObject(&update[i]); err != nil {
+				return err
+			}
+		}
+		for _, obj := range remove {
+			if err := repos.World.DeleteWorldObject(obj.ID); err != nil {
+				return err
+			}
+		}
+		for _, p := range placements {
+			p.position.MapID = mapData.ID
+			if p.isNew {
+				err = repos.World.CreateNPCPosition(p.position)
+			} else {
+				err = repos.World.UpdateNPCPosition(p.position)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionMapImport, models.AuditTargetMap, mapData.ID, nil, mapImportSummary{
+			MapCreated: result.MapCreated,
+			MapChanged: result.MapChanged,
+			Created:    len(result.Created),
+			Updated:    len(result.Updated),
+			Removed:    len(result.Removed),
+			NPCsPlaced: len(result.NPCsPlaced),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.DryRun {
+		s.collision.invalidate(result.Map.ID)
+	}
+	return result, nil
+}
+
+type mapImportSummary struct {
+	MapCreated bool `json:"map_created"`
+	MapChanged bool `json:"map_changed"`
+	Created    int  `json:"objects_created"`
+	Updated    int  `json:"objects_updated"`
+	Removed    int  `json:"objects_removed"`
+	NPCsPlaced int  `json:"npcs_placed"`
+}
+
+// diffWorldObjects matches imported objects to existing ones by type and
+// tile, recording the changes in result.
+func diffWorldObjects(existing, imported []models.WorldObject, result *MapImportResult) (create, update, remove []models.WorldObject) {
+	key := func(obj models.WorldObject) ObjectChange {
+		return ObjectChange{ObjectType: obj.ObjectType, PosX: obj.PosX, PosY: obj.PosY}
+	}
+
+	unmatched := make(map[ObjectChange][]models.WorldObject)
+	for _, obj := range existing {
+		unmatched[key(obj)] = append(unmatched[key(obj)], obj)
+	}
+
+	for _, obj := range imported {
+		k := key(obj)
+		candidates := unmatched[k]
+		if len(candidates) == 0 {
+			create = append(create, obj)
+			result.Created = append(result.Created, k)
+			continue
+		}
+
+		match := candidates[0]
+		unmatched[k] = candidates[1:]
+		if match.State == nil {
+			match.State = make(models.ObjectState)
+		}
+
+		changed := false
+		for name, value := range obj.State {
+			if !sameJSON(match.State[name], value) {
+				match.State[name] = value
+				changed = true
+			}
+		}
+		if changed {
+			update = append(update, match)
+			result.Updated = append(result.Updated, k)
+		} else {
+			result.Unchanged++
+		}
+	}
+
+	for _, obj := range existing {
+		// Whatever is still unmatched is no longer in the file
+		if leftover := unmatched[key(obj)]; len(leftover) > 0 && leftover[0].ID == obj.ID {
+			unmatched[key(obj)] = leftover[1:]
+			remove = append(remove, obj)
+			result.Removed = append(result.Removed, key(obj))
+		}
+	}
+	return create, update, remove
+}
+
+// mapFingerprint captures the parts of a map an import sets.
+func mapFingerprint(mapData *models.Map) string {
+	data, _ := json.Marshal(struct {
+		Type        models.MapType
+		Description string
+		Width       int
+		Height      int
+		Layout      models.MapLayout
+	}{mapData.Type, mapData.Description, mapData.Width, mapData.Height, mapData.Layout})
+	return string(data)
+}
+
+// sameJSON compares values the way they are stored, so 3 and 3.0 match.
+func sameJSON(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+func readMapFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../tiled/testdata/" + name)
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	return data
+}
+
+func TestImportTiledMapTwiceChangesNothing(t *testing.T) {
+	store, repos := newTestStore()
+	service := newTestWorldService(store, repos)
+	ada := store.AddNPC(models.NPC{Name: "Ada"})
+	actor := Actor{UserID: uuid.New()}
+
+	first, err := service.ImportTiledMap(actor, readMapFixture(t, "grove.tmj"), MapImportOptions{})
+	if err != nil {
+		t.Fatalf("first import: %v", err)
+	}
+	if !first.MapCreated || len(first.Created) != 2 || len(first.NPCsPlaced) != 1 || len(first.Warnings) != 1 {
+		t.Fatalf("first import %+v, want the map, two objects and Ada created with one warning", first)
+	}
+
+	grove, err := repos.World.GetMapByName("grove")
+	if err != nil {
+		t.Fatalf("GetMapByName: %v", err)
+	}
+	if grove.Type != models.MapTypeVillage || grove.Width != 4 || grove.Layout.Walkable[1] != "...#" {
+		t.Fatalf("imported map %+v, want the grove", grove)
+	}
+	if position, err := repos.World.GetNPCPosition(ada.ID); err != nil || position.MapID != grove.ID || position.PosX != 1 || position.PosY != 2 {
+		t.Fatalf("Ada at %+v, %v, want (1,2) in the grove", position, err)
+	}
+
+	// Runtime state is not in the file, so a re-import leaves it alone
+	objects, err := repos.World.GetAllWorldObjects(grove.ID)
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("GetAllWorldObjects = %+v, %v, want two objects", objects, err)
+	}
+	tree := objects[0]
+	if tree.ObjectType != models.ObjectTypeTree {
+		tree = objects[1]
+	}
+	tree.State["chopped_at"] = "2026-01-01T00:00:00Z"
+	if err := repos.World.UpdateWorldObject(&tree); err != nil {
+		t.Fatalf("UpdateWorldObject: %v", err)
+	}
+
+	for _, name := range []string{"grove.tmj", "grove.tmx"} {
+		t.Run(name, func(t *testing.T) {
+			again, err := service.ImportTiledMap(actor, readMapFixture(t, name), MapImportOptions{})
+			if err != nil {
+				t.Fatalf("re-import: %v", err)
+			}
+			if again.MapCreated || again.MapChanged || len(again.Created) != 0 || len(again.Updated) != 0 ||
+				len(again.Removed) != 0 || again.Unchanged != 2 || len(again.NPCsPlaced) != 0 || again.NPCsUnchanged != 1 {
+				t.Fatalf("re-import %+v, want nothing changed", again)
+			}
+			if again.Map.ID != grove.ID {
+				t.Errorf("re-imported into map %s, want %s", again.Map.ID, grove.ID)
+			}
+		})
+	}
+
+	objects, _ = repos.World.GetAllWorldObjects(grove.ID)
+	for _, obj := range objects {
+		if obj.ID == tree.ID && obj.State["chopped_at"] != "2026-01-01T00:00:00Z" {
+			t.Errorf("tree state %v, want chopped_at kept", obj.State)
+		}
+	}
+
+	logs, total, err := repos.AuditLogs.Search(repositories.AuditLogFilter{Action: models.AuditActionMapImport}, defaultTestPage)
+	if err != nil || total != 3 || logs[0].ActorID == nil || *logs[0].ActorID != actor.UserID {
+		t.Errorf("audit logs %+v, %v, want one per import by the actor", logs, err)
+	}
+}
+
+func TestDiffWorldObjects(t *testing.T) {
+	rock := func(id uuid.UUID, x int, state models.ObjectState) models.WorldObject {
+		return models.WorldObject{ID: id, ObjectType: models.ObjectTypeRock, PosX: x, State: state}
+	}
+	first, second, other := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name               string
+		existing, imported []models.WorldObject
+		created, updated   int
+		removed            []uuid.UUID
+		unchanged          int
+	}{
+		{
+			name:     "two existing objects on one tile",
+			existing: []models.WorldObject{rock(first, 0, nil), rock(second, 0, nil), rock(other, 1, nil)},
+			imported: []models.WorldObject{rock(uuid.Nil, 0, nil), rock(uuid.Nil, 1, nil)},
+			removed:  []uuid.UUID{second}, unchanged: 2,
+		},
+		{
+			name:     "two imported objects on one tile",
+			existing: []models.WorldObject{rock(first, 0, nil)},
+			imported: []models.WorldObject{rock(uuid.Nil, 0, nil), rock(uuid.Nil, 0, nil)},
+			created:  1, unchanged: 1,
+		},
+		{
+			name:     "a changed property",
+			existing: []models.WorldObject{rock(first, 0, models.ObjectState{"hits": 2.0, "cracked": true})},
+			imported: []models.WorldObject{rock(uuid.Nil, 0, models.ObjectState{"hits": 3})},
+			updated:  1,
+		},
+		{
+			name:      "a number read back as a float",
+			existing:  []models.WorldObject{rock(first, 0, models.ObjectState{"hits": 3.0})},
+			imported:  []models.WorldObject{rock(uuid.Nil, 0, models.ObjectState{"hits": 3})},
+			unchanged: 1,
+		},
+		{
+			name:     "moved to another tile",
+			existing: []models.WorldObject{rock(first, 0, nil)},
+			imported: []models.WorldObject{rock(uuid.Nil, 1, nil)},
+			created:  1, removed: []uuid.UUID{first},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &MapImportResult{}
+			create, update, remove := diffWorldObjects(tt.existing, tt.imported, result)
+
+			var removed []uuid.UUID
+			for _, obj := range remove {
+				removed = append(removed, obj.ID)
+			}
+			if len(create) != tt.created || len(update) != tt.updated || !reflect.DeepEqual(removed, tt.removed) {
+				t.Fatalf("created %d, updated %d, removed %v; want %d, %d, %v",
+					len(create), len(update), removed, tt.created, tt.updated, tt.removed)
+			}
+			if len(result.Created) != tt.created || len(result.Updated) != tt.updated ||
+				len(result.Removed) != len(tt.removed) || result.Unchanged != tt.unchanged {
+				t.Errorf("result %+v does not match the changes", result)
+			}
+		})
+	}
+
+	// Updates keep the runtime state alongside the file's properties
+	_, update, _ := diffWorldObjects(
+		[]models.WorldObject{rock(first, 0, models.ObjectState{"hits": 2.0, "cracked": true})},
+		[]models.WorldObject{rock(uuid.Nil, 0, models.ObjectState{"hits": 3})},
+		&MapImportResult{},
+	)
+	if want := (models.ObjectState{"hits": 3, "cracked": true}); !reflect.DeepEqual(update[0].State, want) {
+		t.Errorf("updated state %v, want %v", update[0].State, want)
+	}
+}
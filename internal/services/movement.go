@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOutOfBounds     = errors.New("position out of bounds")
+	ErrPositionBlocked = errors.New("position is not walkable")
+	ErrMoveTooFast     = errors.New("moving too fast")
+	ErrMoveNoPath      = errors.New("no walkable path to position")
+)
+
+// moveRejection explains a refused move. Kind is set when the move is
+// suspicious enough to record for anti-cheat review.
+type moveRejection struct {
+	err     error
+	kind    models.CheatKind
+	details string
+}
+
+func (r *moveRejection) Error() string { return r.err.Error() }
+func (r *moveRejection) Unwrap() error { return r.err }
+
+func reject(err error) *moveRejection {
+	return &moveRejection{err: err}
+}
+
+func suspicious(err error, kind models.CheatKind, format string, args ...interface{}) *moveRejection {
+	return &moveRejection{err: err, kind: kind, details: fmt.Sprintf(format, args...)}
+}
+
+// tileGrid answers whether a tile on a map can be walked on.
+type tileGrid interface {
+	InBounds(x, y int) bool
+	Walkable(x, y int) bool
+}
+
+// movementRules decide whether a player may move to a tile. Players earn
+// movement at maxTilesPerSecond and may save up at most burst of it, so a
+// player who stood still can take a few quick steps but no one can keep
+// moving faster than the limit.
+type movementRules struct {
+	tilesPerSecond float64
+	burst          time.Duration
+	strikes        *strikeCounter
+}
+
+func newMovementRules(cfg config.MovementConfig) *movementRules {
+	return &movementRules{
+		tilesPerSecond: cfg.MaxTilesPerSecond,
+		burst:          time.Duration(cfg.BurstSeconds * float64(time.Second)),
+		strikes:        newStrikeCounter(cfg.SpeedStrikes, time.Duration(cfg.StrikeWindowSeconds)*time.Second),
+	}
+}
+
+// maxStep is the furthest a single move can reach with a full budget.
+func (m *movementRules) maxStep() int {
+	return int(math.Max(1, math.Floor(m.tilesPerSecond*m.burst.Seconds())))
+}
+
+// check validates a move from the player's position to (x, y). On success
+// it returns the new LastMoved: the moment the player's movement budget is
+// spent up to, never later than now.
+func (m *movementRules) check(grid tileGrid, position *models.PlayerPosition, x, y int, now time.Time) (time.Time, *moveRejection) {
+	if !grid.InBounds(x, y) {
+		return time.Time{}, suspicious(ErrOutOfBounds, models.CheatOutOfBounds, "target (%d,%d) is off the map", x, y)
+	}
+	if !grid.Walkable(x, y) {
+		return time.Time{}, reject(ErrPositionBlocked)
+	}
+
+	maxStep := m.maxStep()
+	if distance := chebyshev(position.PosX, position.PosY, x, y); distance > maxStep {
+		return time.Time{}, suspicious(ErrMoveTooFast, models.CheatSpeed,
+			"moved %d tiles in one step, at most %d allowed", distance, maxStep)
+	}
+
+	steps, ok := pathLength(grid, position.PosX, position.PosY, x, y, maxStep)
+	if !ok {
+		return time.Time{}, suspicious(ErrMoveNoPath, models.CheatWallClip,
+			"no walkable path from (%d,%d) within %d steps", position.PosX, position.PosY, maxStep)
+	}
+
+	// Unused movement older than the burst window is forfeited
+	start := position.LastMoved
+	if floor := now.Add(-m.burst); start.Before(floor) {
+		start = floor
+	}
+	next := start.Add(time.Duration(float64(steps) / m.tilesPerSecond * float64(time.Second)))
+	if next.After(now) {
+		return time.Time{}, reject(ErrMoveTooFast)
+	}
+	return next, nil
+}
+
+// chebyshev is the number of 8-directional steps between two tiles on an
+// open floor.
+func chebyshev(x1, y1, x2, y2 int) int {
+	return int(math.Max(math.Abs(float64(x2-x1)), math.Abs(float64(y2-y1))))
+}
+
+var neighbourSteps = [8][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+// pathLength finds the fewest 8-directional steps from one tile to another
+// within maxSteps. A diagonal step needs both tiles it cuts between to be
+// walkable, so players cannot squeeze through the corner of two walls.
+func pathLength(grid tileGrid, fromX, fromY, toX, toY, maxSteps int) (int, bool) {
+	type tile struct{ x, y int }
+	start, goal := tile{fromX, fromY}, tile{toX, toY}
+	if start == goal {
+		return 0, true
+	}
+
+	dist := map[tile]int{start: 0}
+	queue := []tile{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if dist[current] == maxSteps {
+			continue
+		}
+
+		for _, step := range neighbourSteps {
+			next := tile{current.x + step[0], current.y + step[1]}
+			if _, seen := dist[next]; seen || !grid.Walkable(next.x, next.y) {
+				continue
+			}
+			if step[0] != 0 && step[1] != 0 &&
+				(!grid.Walkable(current.x+step[0], current.y) || !grid.Walkable(current.x, current.y+step[1])) {
+				continue
+			}
+
+			dist[next] = dist[current] + 1
+			if next == goal {
+				return dist[next], true
+			}
+			queue = append(queue, next)
+		}
+	}
+	return 0, false
+}
+
+// strikeCounter notices players who keep moving too fast. Single
+// too-fast moves are usually lag; limit of them within window is not.
+type strikeCounter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	strikes map[uuid.UUID][]time.Time
+}
+
+func newStrikeCounter(limit int, window time.Duration) *strikeCounter {
+	return &strikeCounter{
+		limit:   limit,
+		window:  window,
+		strikes: make(map[uuid.UUID][]time.Time),
+	}
+}
+
+// add records a strike and reports whether the user just reached the
+// limit, after which their count starts over.
+func (c *strikeCounter) add(userID uuid.UUID, now time.Time) bool {
+	if c.limit <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	recent := c.strikes[userID][:0]
+	for _, at := range c.strikes[userID] {
+		if now.Sub(at) < c.window {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+
+	if len(recent) >= c.limit {
+		delete(c.strikes, userID)
+		return true
+	}
+	c.strikes[userID] = recent
+	return false
+}
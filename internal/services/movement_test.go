@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// tileRows is a map drawn one row per string, '#' marking a blocked tile.
+type tileRows []string
+
+func (g tileRows) InBounds(x, y int) bool {
+	return y >= 0 && y < len(g) && x >= 0 && x < len(g[y])
+}
+
+func (g tileRows) Walkable(x, y int) bool {
+	return g.InBounds(x, y) && g[y][x] != '#'
+}
+
+// testMovement allows 4 tiles a second, saved up for at most a second.
+func testMovement() *movementRules {
+	return newMovementRules(config.MovementConfig{
+		MaxTilesPerSecond:   4,
+		BurstSeconds:        1,
+		SpeedStrikes:        3,
+		StrikeWindowSeconds: 10,
+	})
+}
+
+func TestMovementCheck(t *testing.T) {
+	open := tileRows{
+		"........",
+		"........",
+		"........",
+	}
+	now := time.Now()
+	idle := now.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		grid      tileRows
+		fromX     int
+		lastMoved time.Time
+		toX, toY  int
+		wantErr   error
+		wantKind  models.CheatKind
+	}{
+		{"one step", open, 0, idle, 1, 0, nil, ""},
+		{"a saved-up burst", open, 0, idle, 4, 0, nil, ""},
+		{"a diagonal step", open, 0, idle, 1, 1, nil, ""},
+		{"past the burst in one step", open, 0, idle, 5, 0, ErrMoveTooFast, models.CheatSpeed},
+		{"no budget left", open, 0, now, 1, 0, ErrMoveTooFast, ""},
+		{"half the budget left", open, 0, now.Add(-500 * time.Millisecond), 3, 0, ErrMoveTooFast, ""},
+		{"off the map", open, 0, idle, -1, 0, ErrOutOfBounds, models.CheatOutOfBounds},
+		{"past the edge", open, 7, idle, 8, 0, ErrOutOfBounds, models.CheatOutOfBounds},
+		{"onto a wall", tileRows{".#"}, 0, idle, 1, 0, ErrPositionBlocked, ""},
+		{"between two diagonal blockers", tileRows{
+			".#",
+			"#.",
+		}, 0, idle, 1, 1, ErrMoveNoPath, models.CheatWallClip},
+		{"past a corner", tileRows{
+			".#",
+			"..",
+		}, 0, idle, 1, 1, nil, ""},
+		{"through a wall", tileRows{
+			"..#..",
+			"..#..",
+		}, 0, idle, 3, 0, ErrMoveNoPath, models.CheatWallClip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position := &models.PlayerPosition{PosX: tt.fromX, LastMoved: tt.lastMoved}
+			lastMoved, rejection := testMovement().check(tt.grid, position, tt.toX, tt.toY, now)
+
+			if tt.wantErr == nil {
+				if rejection != nil {
+					t.Fatalf("rejected with %v, want the move accepted", rejection)
+				}
+				if lastMoved.After(now) || !lastMoved.After(tt.lastMoved) {
+					t.Errorf("LastMoved %v, want after %v and no later than now", lastMoved, tt.lastMoved)
+				}
+				return
+			}
+			if rejection == nil {
+				t.Fatalf("accepted, want %v", tt.wantErr)
+			}
+			if !errors.Is(rejection, tt.wantErr) || rejection.kind != tt.wantKind {
+				t.Errorf("rejected with %v (%q), want %v (%q)", rejection, rejection.kind, tt.wantErr, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestMovementAcceptsAnHonestWalk(t *testing.T) {
+	rules := testMovement()
+	grid := tileRows{"...................................."}
+	now := time.Now()
+	position := &models.PlayerPosition{LastMoved: now.Add(-time.Hour)}
+
+	// Walking at the limit for far longer than the burst never runs out
+	for x := 1; x < len(grid[0]); x++ {
+		now = now.Add(250 * time.Millisecond)
+		lastMoved, rejection := rules.check(grid, position, x, 0, now)
+		if rejection != nil {
+			t.Fatalf("step to %d rejected with %v", x, rejection)
+		}
+		position.PosX, position.LastMoved = x, lastMoved
+	}
+}
+
+func TestMovementSpendsTheBurstOnce(t *testing.T) {
+	rules := testMovement()
+	grid := tileRows{".........."}
+	now := time.Now()
+	position := &models.PlayerPosition{LastMoved: now.Add(-time.Hour)}
+
+	// A rested player may take four quick steps, but not a fifth
+	for x := 1; x <= 5; x++ {
+		lastMoved, rejection := rules.check(grid, position, x, 0, now)
+		if x <= 4 {
+			if rejection != nil {
+				t.Fatalf("step %d rejected with %v", x, rejection)
+			}
+			position.PosX, position.LastMoved = x, lastMoved
+			continue
+		}
+		if !errors.Is(rejection, ErrMoveTooFast) || rejection.kind != "" {
+			t.Fatalf("step %d: got %v, want a plain ErrMoveTooFast", x, rejection)
+		}
+	}
+}
+
+func TestStrikeCounter(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		limit   int
+		strikes []time.Duration // since now
+		want    bool
+	}{
+		{"below the limit", 3, []time.Duration{0, time.Second}, false},
+		{"at the limit", 3, []time.Duration{0, time.Second, 2 * time.Second}, true},
+		{"spread past the window", 3, []time.Duration{0, 6 * time.Second, 12 * time.Second}, false},
+		{"disabled", 0, []time.Duration{0, 0, 0, 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := newStrikeCounter(tt.limit, 10*time.Second)
+			user := uuid.New()
+			got := false
+			for _, at := range tt.strikes {
+				got = counter.add(user, now.Add(at))
+			}
+			if got != tt.want {
+				t.Errorf("last strike reached the limit: %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func antiCheatEvents(t *testing.T, repos *repositories.Repositories) []models.AntiCheatEvent {
+	t.Helper()
+	events, _, err := repos.AntiCheat.Search(repositories.AntiCheatFilter{}, defaultTestPage)
+	if err != nil {
+		t.Fatalf("searching anti-cheat events: %v", err)
+	}
+	return events
+}
+
+func TestMovePlayerRecordsSuspiciousMoves(t *testing.T) {
+	store, repos := newTestStore()
+	service := newTestWorldService(store, repos)
+	service.movement = testMovement()
+	user, _ := addTestPlayer(t, store, repos, 10, 10, 0, 0)
+
+	if err := service.MovePlayer(user.ID, 20, 0, "right"); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("MovePlayer off the map = %v, want ErrOutOfBounds", err)
+	}
+	events := antiCheatEvents(t, repos)
+	if len(events) != 1 || events[0].Kind != models.CheatOutOfBounds || events[0].ToX != 20 {
+		t.Fatalf("events %+v, want one out_of_bounds event", events)
+	}
+
+	// One step spends the budget; the rest are too fast, which is only
+	// recorded once they add up to the strike limit
+	if err := service.MovePlayer(user.ID, 4, 0, "right"); err != nil {
+		t.Fatalf("MovePlayer: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		if err := service.MovePlayer(user.ID, 5, 0, "right"); !errors.Is(err, ErrMoveTooFast) {
+			t.Fatalf("move %d: %v, want ErrMoveTooFast", i, err)
+		}
+		if want := 1 + i/3; len(antiCheatEvents(t, repos)) != want {
+			t.Fatalf("after %d too-fast moves: %d events, want %d", i, len(antiCheatEvents(t, repos)), want)
+		}
+	}
+	speed := repositories.AntiCheatFilter{Kind: models.CheatSpeed}
+	if events, total, err := repos.AntiCheat.Search(speed, defaultTestPage); err != nil || total != 1 || events[0].UserID != user.ID {
+		t.Fatalf("speed events %+v, %v, want one for the player", events, err)
+	}
+}
@@ -10,12 +10,12 @@ import (
 )
 
 type NotificationService struct {
-	notificationRepo *repositories.NotificationRepository
+	notificationRepo repositories.NotificationRepository
 }
 
-func NewNotificationService() *NotificationService {
+func NewNotificationService(notificationRepo repositories.NotificationRepository) *NotificationService {
 	return &NotificationService{
-		notificationRepo: repositories.NewNotificationRepository(),
+		notificationRepo: notificationRepo,
 	}
 }
 
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/pathfinding"
+	"code-valley-api/internal/websocket"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// npcStepsPerTick is how many tiles an NPC walks per game-clock tick, i.e.
+// per ten game minutes.
+const npcStepsPerTick = 6
+
+// daysPerWeek splits a season's days into the weeks NPC schedules repeat
+// over; the first day of a season is day 0 of a week.
+const daysPerWeek = 7
+
+// minuteOfWeek orders a day of week and a time of day (HHMM) within the
+// week.
+func minuteOfWeek(dayOfWeek, timeOfDay int) int {
+	return dayOfWeek*24*60 + (timeOfDay/100)*60 + timeOfDay%100
+}
+
+// currentScheduleEntry is the entry an NPC should be following at a
+// moment in the week: the latest one started at or before it, or the
+// week's last entry when none has started yet.
+func currentScheduleEntry(schedules []models.NPCSchedule, now int) models.NPCSchedule {
+	var current, last *models.NPCSchedule
+	for i := range schedules {
+		entry := &schedules[i]
+		at := minuteOfWeek(entry.DayOfWeek, entry.TimeOfDay)
+		if at <= now && (current == nil || at > minuteOfWeek(current.DayOfWeek, current.TimeOfDay)) {
+			current = entry
+		}
+		if last == nil || at > minuteOfWeek(last.DayOfWeek, last.TimeOfDay) {
+			last = entry
+		}
+	}
+	if current == nil {
+		current = last
+	}
+	return *current
+}
+
+// AdvanceNPCs moves every scheduled NPC one tick towards where its
+// schedule wants it. NPCs walk along walkable paths, crossing to another
+// map through a portal leading there; an NPC with no way to walk to its
+// target is put there directly.
+func (s *WorldService) AdvanceNPCs(clock *models.GameClock) {
+	schedules, err := s.worldRepo.GetActiveNPCSchedules()
+	if err != nil {
+		log.Printf("Failed to load NPC schedules: %v", err)
+		return
+	}
+
+	byNPC := make(map[uuid.UUID][]models.NPCSchedule)
+	for _, schedule := range schedules {
+		byNPC[schedule.NPCID] = append(byNPC[schedule.NPCID], schedule)
+	}
+
+	dayOfWeek := (clock.GameDay - 1) % daysPerWeek
+	now := minuteOfWeek(dayOfWeek, clock.GameHour*100+clock.GameMinute)
+	for npcID, npcSchedules := range byNPC {
+		if err := s.advanceNPC(npcID, currentScheduleEntry(npcSchedules, now)); err != nil {
+			log.Printf("Failed to move NPC %s: %v", npcID, err)
+		}
+	}
+}
+
+func (s *WorldService) advanceNPC(npcID uuid.UUID, target models.NPCSchedule) error {
+	position, err := s.worldRepo.GetNPCPosition(npcID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		position = &models.NPCPosition{NPCID: npcID, MapID: target.MapID, PosX: target.PosX, PosY: target.PosY, Direction: "down"}
+		if err := s.worldRepo.CreateNPCPosition(position); err != nil {
+			return err
+		}
+		s.broadcastNPCPosition(position, target.Action, nil)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if position.MapID == target.MapID && position.PosX == target.PosX && position.PosY == target.PosY {
+		return nil
+	}
+
+	grid, err := s.collisionGrid(position.MapID)
+	if err != nil {
+		return err
+	}
+
+	from := pathfinding.Point{X: position.PosX, Y: position.PosY}
+	goals := []pathfinding.Point{{X: target.PosX, Y: target.PosY}}
+	var portal *models.Portal
+	if position.MapID != target.MapID {
+		targetMap, err := s.worldRepo.GetMapByID(target.MapID)
+		if err != nil {
+			return err
+		}
+		for i, candidate := range grid.layout.Portals {
+			if candidate.TargetMap == targetMap.Name {
+				portal = &grid.layout.Portals[i]
+				break
+			}
+		}
+		if portal == nil {
+			return s.placeNPC(position, target.MapID, target.PosX, target.PosY, target.Action)
+		}
+		goals = portalTiles(*portal)
+	}
+
+	path, ok := grid.paths.FindAny(from, goals)
+	if !ok {
+		log.Printf("NPC %s has no path to (%d,%d), placing it there", npcID, target.PosX, target.PosY)
+		return s.placeNPC(position, target.MapID, target.PosX, target.PosY, target.Action)
+	}
+
+	if len(path) == 0 {
+		// Standing on the portal to the target map
+		targetMap, err := s.worldRepo.GetMapByID(target.MapID)
+		if err != nil {
+			return err
+		}
+		spawn, ok := targetMap.Layout.Spawn(portal.TargetSpawn)
+		if !ok {
+			spawn = defaultSpawn(targetMap)
+		}
+		return s.placeNPC(position, target.MapID, spawn.X, spawn.Y, target.Action)
+	}
+
+	if len(path) > npcStepsPerTick {
+		path = path[:npcStepsPerTick]
+	}
+	if len(path) > 1 {
+		from = path[len(path)-2]
+	}
+	last := path[len(path)-1]
+	position.PosX, position.PosY = last.X, last.Y
+	position.Direction = stepDirection(from, last)
+	if err := s.worldRepo.UpdateNPCPosition(position); err != nil {
+		return err
+	}
+
+	s.broadcastNPCPosition(position, target.Action, path)
+	return nil
+}
+
+// placeNPC puts an NPC on a tile without walking, telling the map it left
+// if it changed maps.
+func (s *WorldService) placeNPC(position *models.NPCPosition, mapID uuid.UUID, x, y int, action string) error {
+	previousMapID := position.MapID
+	position.MapID = mapID
+	position.PosX, position.PosY = x, y
+	if err := s.worldRepo.UpdateNPCPosition(position); err != nil {
+		return err
+	}
+
+	if previousMapID != mapID {
+		websocket.BroadcastToMap(previousMapID, websocket.Message{
+			Type: "npc_left_map",
+			Data: NPCLeftMap{NPCID: position.NPCID, MapID: previousMapID},
+		})
+	}
+	s.broadcastNPCPosition(position, action, nil)
+	return nil
+}
+
+func (s *WorldService) broadcastNPCPosition(position *models.NPCPosition, action string, path []pathfinding.Point) {
+	if path == nil {
+		path = []pathfinding.Point{}
+	}
+	websocket.BroadcastToMap(position.MapID, websocket.Message{
+		Type: "npc_position_update",
+		Data: NPCPositionUpdate{
+			NPCID:     position.NPCID,
+			MapID:     position.MapID,
+			PosX:      position.PosX,
+			PosY:      position.PosY,
+			Direction: position.Direction,
+			Action:    action,
+			Path:      path,
+		},
+	})
+}
+
+// stepDirection is the way an NPC faces after a step, preferring left and
+// right for diagonal steps.
+func stepDirection(from, to pathfinding.Point) string {
+	switch {
+	case to.X < from.X:
+		return "left"
+	case to.X > from.X:
+		return "right"
+	case to.Y < from.Y:
+		return "up"
+	default:
+		return "down"
+	}
+}
+
+func portalTiles(portal models.Portal) []pathfinding.Point {
+	tiles := make([]pathfinding.Point, 0, portal.Width*portal.Height)
+	for y := portal.Y; y < portal.Y+portal.Height; y++ {
+		for x := portal.X; x < portal.X+portal.Width; x++ {
+			tiles = append(tiles, pathfinding.Point{X: x, Y: y})
+		}
+	}
+	return tiles
+}
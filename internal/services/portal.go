@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/websocket"
+
+	"gorm.io/gorm"
+)
+
+// enterPortal takes a player who stepped onto a portal to its target
+// spawn point, or tells them with a portal_locked event why it stays shut.
+// The move onto the portal stands either way.
+func (s *WorldService) enterPortal(position *models.PlayerPosition, portal models.Portal) {
+	reason, err := s.portalLockReason(position, portal.Requirements)
+	if err != nil {
+		log.Printf("Failed to check portal %q for %s: %v", portal.Name, position.UserID, err)
+		return
+	}
+	if reason != "" {
+		s.sendPortalLocked(position, portal, reason)
+		return
+	}
+
+	mapData, err := s.worldRepo.GetMapByName(portal.TargetMap)
+	if err != nil {
+		log.Printf("Portal %q leads to missing map %q", portal.Name, portal.TargetMap)
+		s.sendPortalLocked(position, portal, "the portal leads nowhere")
+		return
+	}
+	spawn, ok := mapData.Layout.Spawn(portal.TargetSpawn)
+	if !ok {
+		if portal.TargetSpawn != "" {
+			log.Printf("Portal %q leads to missing spawn point %q on %q", portal.Name, portal.TargetSpawn, portal.TargetMap)
+			s.sendPortalLocked(position, portal, "the portal leads nowhere")
+			return
+		}
+		spawn = defaultSpawn(mapData)
+	}
+
+	previousMapID := position.MapID
+	position.MapID = mapData.ID
+	position.PosX = spawn.X
+	position.PosY = spawn.Y
+	position.LastMoved = time.Now()
+	if err := s.worldRepo.UpdatePlayerPosition(position); err != nil {
+		log.Printf("Failed to move %s through portal %q: %v", position.UserID, portal.Name, err)
+		return
+	}
+
+	s.announceArrival(position, previousMapID, mapData, portal.Name)
+}
+
+// portalLockReason explains which requirement the player does not meet,
+// or returns "" when they may pass.
+func (s *WorldService) portalLockReason(position *models.PlayerPosition, requirements models.PortalRequirements) (string, error) {
+	if requirements.MinLevel > 0 {
+		user, err := s.userRepo.GetByID(position.UserID)
+		if err != nil {
+			return "", err
+		}
+		if user.Level < requirements.MinLevel {
+			return fmt.Sprintf("requires level %d", requirements.MinLevel), nil
+		}
+	}
+
+	if requirements.QuestID != nil {
+		progress, err := s.questRepo.GetUserProgress(position.UserID, *requirements.QuestID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+		if progress == nil || progress.Status != models.QuestStatusCompleted {
+			quest, err := s.questRepo.GetByID(*requirements.QuestID)
+			if err != nil {
+				return "requires completing a quest", nil
+			}
+			return fmt.Sprintf("requires completing %q", quest.Title), nil
+		}
+	}
+
+	if requirements.OpenFrom != requirements.OpenUntil {
+		clock, err := s.worldRepo.GetGameClock()
+		if err != nil {
+			return "", err
+		}
+		if !requirements.OpenAt(clock.GameHour) {
+			return fmt.Sprintf("open from %02d:00 to %02d:00", requirements.OpenFrom, requirements.OpenUntil), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (s *WorldService) sendPortalLocked(position *models.PlayerPosition, portal models.Portal, reason string) {
+	websocket.SendToUser(position.UserID, websocket.Message{
+		Type: "portal_locked",
+		Data: PortalLocked{Portal: portal.Name, Reason: reason},
+	})
+}
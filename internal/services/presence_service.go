@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/utils"
+	"code-valley-api/internal/websocket"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SetStatusRequest struct {
+	Status models.PresenceStatus `json:"status" validate:"required,oneof=online away busy invisible"`
+}
+
+// PresenceService persists who is online and tells a user's accepted
+// friends when the status they can see changes.
+type PresenceService struct {
+	friendRepo repositories.FriendRepository
+}
+
+func NewPresenceService(friendRepo repositories.FriendRepository) *PresenceService {
+	return &PresenceService{
+		friendRepo: friendRepo,
+	}
+}
+
+// Start reconciles presence left behind by a previous run, then follows
+// connection changes from the WebSocket hub.
+func (s *PresenceService) Start() {
+	if err := s.Reconcile(); err != nil {
+		log.Printf("Presence reconciliation failed: %v", err)
+	}
+	go s.handlePresenceEvents()
+	log.Println("Presence service started")
+}
+
+// Reconcile marks every stored user offline unless they are connected to
+// another node. This node's connections don't survive a restart, so any
+// other row still online was left by a crash.
+func (s *PresenceService) Reconcile() error {
+	reset, err := s.friendRepo.ResetPresence(websocket.OnlineUsers())
+	if err != nil {
+		return err
+	}
+	if reset > 0 {
+		log.Printf("Presence: marked %d stale users offline", reset)
+	}
+	return nil
+}
+
+// SetStatus changes the status a user shows while connected. It is kept
+// across reconnects.
+func (s *PresenceService) SetStatus(userID uuid.UUID, req SetStatusRequest) (*models.OnlineUser, error) {
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	presence, err := s.getPresence(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := presence.VisibleStatus()
+	presence.Status = req.Status
+	if err := s.friendRepo.SavePresence(presence); err != nil {
+		return nil, err
+	}
+
+	s.publish(presence, previous)
+	return presence, nil
+}
+
+// RegisterSocketHandlers adds the presence messages to the WebSocket
+// protocol.
+func (s *PresenceService) RegisterSocketHandlers(r *websocket.Registry) {
+	websocket.Handle(r, "set_status", "Change the status your friends see.",
+		func(ctx *websocket.Context, req SetStatusRequest) (*models.OnlineUser, error) {
+			return s.SetStatus(ctx.UserID, req)
+		})
+}
+
+func (s *PresenceService) handlePresenceEvents() {
+	for event := range websocket.GlobalHub.GetPresenceChannel() {
+		if err := s.setOnline(event.UserID, event.Online); err != nil {
+			log.Printf("Presence update for %s failed: %v", event.UserID, err)
+		}
+	}
+}
+
+func (s *PresenceService) setOnline(userID uuid.UUID, online bool) error {
+	presence, err := s.getPresence(userID)
+	if err != nil {
+		return err
+	}
+
+	previous := presence.VisibleStatus()
+	presence.IsOnline = online
+	presence.LastSeen = time.Now()
+	if err := s.friendRepo.SavePresence(presence); err != nil {
+		return err
+	}
+
+	s.publish(presence, previous)
+	return nil
+}
+
+// getPresence loads a user's presence row, starting a new one offline.
+func (s *PresenceService) getPresence(userID uuid.UUID) (*models.OnlineUser, error) {
+	presence, err := s.friendRepo.GetPresence(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.OnlineUser{
+			UserID:   userID,
+			Status:   models.PresenceOnline,
+			LastSeen: time.Now(),
+		}, nil
+	}
+	return presence, err
+}
+
+// publish pushes the user's visible status to their accepted friends when
+// it differs from what they saw before.
+func (s *PresenceService) publish(presence *models.OnlineUser, previous models.PresenceStatus) {
+	status := presence.VisibleStatus()
+	if status == previous {
+		return
+	}
+
+	friends, err := s.friendRepo.GetUserFriends(presence.UserID)
+	if err != nil {
+		log.Printf("Presence: failed to load friends of %s: %v", presence.UserID, err)
+		return
+	}
+
+	message := websocket.Message{
+		Type: "user_status",
+		Data: UserStatusUpdate{
+			UserID:   presence.UserID,
+			IsOnline: status != models.PresenceOffline,
+			Status:   status,
+			LastSeen: presence.LastSeen,
+		},
+	}
+	for _, friend := range friends {
+		websocket.SendToUser(friend.ID, message)
+	}
+}
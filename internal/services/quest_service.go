@@ -12,15 +12,19 @@ import (
 	// "gorm.io/gorm"
 )
 
+var ErrQuestNotFound = errors.New("quest not found")
+
 type QuestService struct {
-	questRepo *repositories.QuestRepository
-	userRepo  *repositories.UserRepository
+	questRepo repositories.QuestRepository
+	userRepo  repositories.UserRepository
+	uow       repositories.UnitOfWork
 }
 
-func NewQuestService() *QuestService {
+func NewQuestService(questRepo repositories.QuestRepository, userRepo repositories.UserRepository, uow repositories.UnitOfWork) *QuestService {
 	return &QuestService{
-		questRepo: repositories.NewQuestRepository(),
-		userRepo:  repositories.NewUserRepository(),
+		questRepo: questRepo,
+		userRepo:  userRepo,
+		uow:       uow,
 	}
 }
 
@@ -98,22 +102,13 @@ type CompleteQuestRequest struct {
 	SubmittedItems map[string]int `json:"submitted_items"`
 }
 
-func (s *QuestService) CompleteQuest(userID, questID uuid.UUID, req CompleteQuestRequest) (*models.UserQuestProgress, error) {
+func (s *QuestService) CompleteQuest(userID, questID uuid.UUID, req CompleteQuestRequest, client ClientInfo) (*models.UserQuestProgress, error) {
 	// Get quest and progress
 	quest, err := s.questRepo.GetByID(questID)
 	if err != nil {
 		return nil, err
 	}
 
-	progress, err := s.questRepo.GetUserProgress(userID, questID)
-	if err != nil {
-		return nil, errors.New("quest not started")
-	}
-
-	if progress.Status == models.QuestStatusCompleted {
-		return nil, errors.New("quest already completed")
-	}
-
 	// Validate required items (simplified validation)
 	for item, required := range quest.RequiredItems {
 		if submitted, ok := req.SubmittedItems[item]; !ok || submitted < required {
@@ -121,30 +116,47 @@ func (s *QuestService) CompleteQuest(userID, questID uuid.UUID, req CompleteQues
 		}
 	}
 
-	// Update progress
-	now := time.Now()
-	progress.Status = models.QuestStatusCompleted
-	progress.CompletedAt = &now
+	var progress *models.UserQuestProgress
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		user, err := repos.Users.GetByIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
 
-	if err := s.questRepo.UpdateProgress(progress); err != nil {
-		return nil, err
-	}
+		// Locking the progress row stops two concurrent completions from
+		// paying out the reward twice
+		progress, err = repos.Quests.GetUserProgressForUpdate(userID, questID)
+		if err != nil {
+			return errors.New("quest not started")
+		}
 
-	// Reward user
-	user, err := s.userRepo.GetByID(userID)
-	if err != nil {
-		return nil, err
-	}
+		if progress.Status == models.QuestStatusCompleted {
+			return errors.New("quest already completed")
+		}
 
-	user.Coins += quest.RewardCoins
-	user.EXP += quest.RewardEXP
+		// Update progress
+		now := time.Now()
+		progress.Status = models.QuestStatusCompleted
+		progress.CompletedAt = &now
 
-	// Simple level calculation
-	if user.EXP >= user.Level*100 {
-		user.Level++
-	}
+		if err := repos.Quests.UpdateProgress(progress); err != nil {
+			return err
+		}
 
-	if err := s.userRepo.Update(user); err != nil {
+		// Reward user
+		if err := postCoins(repos, client, user, quest.RewardCoins, models.LedgerAccountQuestRewards, models.LedgerReasonQuestReward, models.LedgerRefQuest, questID); err != nil {
+			return err
+		}
+		user.EXP += quest.RewardEXP
+
+		// Simple level calculation
+		if user.EXP >= user.Level*100 {
+			user.Level++
+		}
+
+		return repos.Users.Update(user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -165,7 +177,7 @@ type CreateQuestRequest struct {
 	IsActive      bool                 `json:"is_active"`
 }
 
-func (s *QuestService) CreateQuest(req CreateQuestRequest) (*models.Quest, error) {
+func (s *QuestService) CreateQuest(actor Actor, req CreateQuestRequest) (*models.Quest, error) {
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, err
 	}
@@ -180,38 +192,63 @@ func (s *QuestService) CreateQuest(req CreateQuestRequest) (*models.Quest, error
 		IsActive:      req.IsActive,
 	}
 
-	if err := s.questRepo.Create(quest); err != nil {
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		if err := repos.Quests.Create(quest); err != nil {
+			return err
+		}
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionQuestCreate, models.AuditTargetQuest, quest.ID, nil, quest)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return quest, nil
 }
 
-func (s *QuestService) UpdateQuest(id uuid.UUID, req CreateQuestRequest) (*models.Quest, error) {
+func (s *QuestService) UpdateQuest(actor Actor, id uuid.UUID, req CreateQuestRequest) (*models.Quest, error) {
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, err
 	}
 
-	quest, err := s.questRepo.GetByID(id)
+	var quest *models.Quest
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		quest, err = repos.Quests.GetByID(id)
+		if err != nil {
+			return err
+		}
+		before := *quest
+
+		quest.Title = req.Title
+		quest.Description = req.Description
+		quest.RewardCoins = req.RewardCoins
+		quest.RewardEXP = req.RewardEXP
+		quest.RequiredItems = req.RequiredItems
+		quest.IsRepeatable = req.IsRepeatable
+		quest.IsActive = req.IsActive
+
+		if err := repos.Quests.Update(quest); err != nil {
+			return err
+		}
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionQuestUpdate, models.AuditTargetQuest, quest.ID, before, quest)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	quest.Title = req.Title
-	quest.Description = req.Description
-	quest.RewardCoins = req.RewardCoins
-	quest.RewardEXP = req.RewardEXP
-	quest.RequiredItems = req.RequiredItems
-	quest.IsRepeatable = req.IsRepeatable
-	quest.IsActive = req.IsActive
-
-	if err := s.questRepo.Update(quest); err != nil {
-		return nil, err
-	}
-
 	return quest, nil
 }
 
-func (s *QuestService) DeleteQuest(id uuid.UUID) error {
-	return s.questRepo.Delete(id)
+func (s *QuestService) DeleteQuest(actor Actor, id uuid.UUID) error {
+	return s.uow.Do(func(repos *repositories.Repositories) error {
+		quest, err := repos.Quests.GetByID(id)
+		if err != nil {
+			return ErrQuestNotFound
+		}
+
+		if err := repos.Quests.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionQuestDelete, models.AuditTargetQuest, id, quest, nil)
+	})
 }
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+)
+
+func addTestQuest(t *testing.T, repos *repositories.Repositories, quest models.Quest) *models.Quest {
+	t.Helper()
+	quest.IsActive = true
+	if err := repos.Quests.Create(&quest); err != nil {
+		t.Fatalf("creating quest: %v", err)
+	}
+	return &quest
+}
+
+func TestCompleteQuest(t *testing.T) {
+	store, repos := newTestStore()
+	player := addTestUser(t, repos, "player", 100)
+	quest := addTestQuest(t, repos, models.Quest{
+		Title: "Fix the build", RewardCoins: 50, RewardEXP: 120,
+		RequiredItems: models.RequiredItems{"Code Snippet": 2},
+	})
+	service := NewQuestService(repos.Quests, repos.Users, store.UnitOfWork())
+
+	if _, err := service.StartQuest(player.ID, quest.ID); err != nil {
+		t.Fatalf("StartQuest: %v", err)
+	}
+	req := CompleteQuestRequest{SubmittedItems: map[string]int{"Code Snippet": 2}}
+	progress, err := service.CompleteQuest(player.ID, quest.ID, req, ClientInfo{})
+	if err != nil {
+		t.Fatalf("CompleteQuest: %v", err)
+	}
+	if progress.Status != models.QuestStatusCompleted || progress.CompletedAt == nil {
+		t.Errorf("progress %+v, want it completed", progress)
+	}
+
+	user, _ := repos.Users.GetByID(player.ID)
+	if user.Coins != 150 || user.EXP != 120 || user.Level != 2 {
+		t.Errorf("player has %d coins, %d EXP and level %d, want 150, 120 and 2", user.Coins, user.EXP, user.Level)
+	}
+	if balance, _ := repos.Ledger.GetBalance(player.ID); balance != 50 {
+		t.Errorf("ledger balance %d, want the 50 rewarded", balance)
+	}
+
+	// Completing again must not pay out twice
+	if _, err := service.CompleteQuest(player.ID, quest.ID, req, ClientInfo{}); err == nil {
+		t.Fatal("completing twice: got no error")
+	}
+	user, _ = repos.Users.GetByID(player.ID)
+	if user.Coins != 150 {
+		t.Errorf("player has %d coins after completing twice, want 150", user.Coins)
+	}
+}
+
+func TestCompleteQuestRefusals(t *testing.T) {
+	store, repos := newTestStore()
+	player := addTestUser(t, repos, "player", 100)
+	quest := addTestQuest(t, repos, models.Quest{
+		Title: "Fix the build", RewardCoins: 50,
+		RequiredItems: models.RequiredItems{"Code Snippet": 2},
+	})
+	service := NewQuestService(repos.Quests, repos.Users, store.UnitOfWork())
+	enough := CompleteQuestRequest{SubmittedItems: map[string]int{"Code Snippet": 2}}
+
+	if _, err := service.CompleteQuest(player.ID, quest.ID, enough, ClientInfo{}); err == nil || err.Error() != "quest not started" {
+		t.Errorf("before starting: got %v, want quest not started", err)
+	}
+	if _, err := service.StartQuest(player.ID, quest.ID); err != nil {
+		t.Fatalf("StartQuest: %v", err)
+	}
+	short := CompleteQuestRequest{SubmittedItems: map[string]int{"Code Snippet": 1}}
+	if _, err := service.CompleteQuest(player.ID, quest.ID, short, ClientInfo{}); err == nil {
+		t.Error("missing items: got no error")
+	}
+
+	user, _ := repos.Users.GetByID(player.ID)
+	if user.Coins != 100 {
+		t.Errorf("player has %d coins, want 100", user.Coins)
+	}
+}
+
+func TestCompleteQuestRollsBackWhenCommitFails(t *testing.T) {
+	store, repos := newTestStore()
+	player := addTestUser(t, repos, "player", 100)
+	quest := addTestQuest(t, repos, models.Quest{Title: "Fix the build", RewardCoins: 50, RewardEXP: 120})
+	if _, err := NewQuestService(repos.Quests, repos.Users, store.UnitOfWork()).StartQuest(player.ID, quest.ID); err != nil {
+		t.Fatalf("StartQuest: %v", err)
+	}
+	service := NewQuestService(repos.Quests, repos.Users, failingCommit{store.UnitOfWork()})
+
+	if _, err := service.CompleteQuest(player.ID, quest.ID, CompleteQuestRequest{}, ClientInfo{}); !errors.Is(err, errCommitFailed) {
+		t.Fatalf("CompleteQuest: got %v, want the commit error", err)
+	}
+
+	user, _ := repos.Users.GetByID(player.ID)
+	if user.Coins != 100 || user.EXP != 0 || user.Level != 1 {
+		t.Errorf("player has %d coins, %d EXP and level %d, want 100, 0 and 1", user.Coins, user.EXP, user.Level)
+	}
+	if balance, _ := repos.Ledger.GetBalance(player.ID); balance != 0 {
+		t.Errorf("ledger balance %d, want nothing posted", balance)
+	}
+	progress, _ := repos.Quests.GetUserProgress(player.ID, quest.ID)
+	if progress.Status != models.QuestStatusInProgress {
+		t.Errorf("progress %s, want it still in progress", progress.Status)
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"log"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+// respawnRule says when a depleted object of a type comes back.
+type respawnRule struct {
+	// Days is how many game days after being depleted it comes back
+	Days int
+	// Seasons it can come back in; empty means any
+	Seasons []string
+}
+
+// respawnRules are the object types that come back after being chopped,
+// mined or looted. An object's "respawn_days" state overrides the rule's
+// days, a negative value meaning it never comes back.
+var respawnRules = map[models.ObjectType]respawnRule{
+	models.ObjectTypeTree:  {Days: 3, Seasons: []string{"spring", "summer", "fall"}},
+	models.ObjectTypeRock:  {Days: 5},
+	models.ObjectTypeChest: {Days: 7},
+}
+
+func (r respawnRule) allows(season string) bool {
+	if len(r.Seasons) == 0 {
+		return true
+	}
+	for _, allowed := range r.Seasons {
+		if allowed == season {
+			return true
+		}
+	}
+	return false
+}
+
+// depletedKey is the state recording the day an object was used up:
+// chests stay on the map when looted, trees and rocks disappear.
+func depletedKey(obj *models.WorldObject) string {
+	if obj.ObjectType == models.ObjectTypeChest {
+		return "looted_day"
+	}
+	return "depleted_day"
+}
+
+func isDepleted(obj *models.WorldObject) bool {
+	if obj.ObjectType == models.ObjectTypeChest {
+		return obj.State["is_looted"] == true
+	}
+	return !obj.IsActive
+}
+
+// stateInt reads a number from object state, which holds float64s when
+// loaded from JSON and ints when set in code.
+func stateInt(state models.ObjectState, key string) (int, bool) {
+	switch value := state[key].(type) {
+	case int:
+		return value, true
+	case float64:
+		return int(value), true
+	}
+	return 0, false
+}
+
+// stampDepleted records today as the day an object was used up, for
+// RespawnWorldObjects to count from. If the clock can't be read the next
+// daily pass records it instead.
+func stampDepleted(repos *repositories.Repositories, obj *models.WorldObject) {
+	clock, err := repos.World.GetGameClock()
+	if err != nil {
+		return
+	}
+	obj.State[depletedKey(obj)] = clock.DayNumber()
+}
+
+// RespawnWorldObjects brings back trees and rocks and refills chests whose
+// respawn rule's days have passed since they were used up, telling each
+// map what came back. Trees and rocks wait while a player stands on their
+// tile.
+func (s *WorldService) RespawnWorldObjects(clock *models.GameClock) {
+	types := make([]models.ObjectType, 0, len(respawnRules))
+	for objectType := range respawnRules {
+		types = append(types, objectType)
+	}
+	objects, err := s.worldRepo.GetWorldObjectsByType(types)
+	if err != nil {
+		log.Printf("Failed to load world objects to respawn: %v", err)
+		return
+	}
+
+	today := clock.DayNumber()
+	occupied := make(map[uuid.UUID]map[[2]int]bool)
+	for i := range objects {
+		obj := &objects[i]
+		if obj.State == nil {
+			obj.State = make(models.ObjectState)
+		}
+		if !isDepleted(obj) {
+			continue
+		}
+
+		key := depletedKey(obj)
+		since, ok := stateInt(obj.State, key)
+		if !ok {
+			// Used up before respawns were tracked, so start counting today
+			obj.State[key] = today
+			if err := s.worldRepo.UpdateWorldObject(obj); err != nil {
+				log.Printf("Failed to update world object %s: %v", obj.ID, err)
+			}
+			continue
+		}
+
+		rule := respawnRules[obj.ObjectType]
+		if days, ok := stateInt(obj.State, "respawn_days"); ok {
+			rule.Days = days
+		}
+		if rule.Days < 0 || today-since < rule.Days || !rule.allows(clock.GameSeason) {
+			continue
+		}
+
+		if obj.ObjectType != models.ObjectTypeChest {
+			if occupied[obj.MapID] == nil {
+				occupied[obj.MapID] = s.occupiedTiles(obj.MapID)
+			}
+			if occupied[obj.MapID][[2]int{obj.PosX, obj.PosY}] {
+				continue
+			}
+		}
+
+		respawn(obj)
+		if err := s.worldRepo.UpdateWorldObject(obj); err != nil {
+			log.Printf("Failed to respawn world object %s: %v", obj.ID, err)
+			continue
+		}
+		if obj.ObjectType != models.ObjectTypeChest {
+			s.collision.invalidate(obj.MapID)
+		}
+		broadcastObjectUpdate(obj)
+	}
+}
+
+// respawn puts an object back as it was before it was used up.
+func respawn(obj *models.WorldObject) {
+	delete(obj.State, depletedKey(obj))
+	if obj.ObjectType == models.ObjectTypeChest {
+		obj.State["is_looted"] = false
+		return
+	}
+
+	obj.IsActive = true
+	if maxHP, ok := stateInt(obj.State, "max_hp"); ok {
+		obj.State["hp"] = maxHP
+	} else {
+		delete(obj.State, "hp")
+	}
+}
+
+func (s *WorldService) occupiedTiles(mapID uuid.UUID) map[[2]int]bool {
+	tiles := make(map[[2]int]bool)
+	positions, err := s.worldRepo.GetPlayersInMap(mapID)
+	if err != nil {
+		log.Printf("Failed to load players on map %s: %v", mapID, err)
+		return tiles
+	}
+	for _, position := range positions {
+		tiles[[2]int{position.PosX, position.PosY}] = true
+	}
+	return tiles
+}
+
+func broadcastObjectUpdate(obj *models.WorldObject) {
+	websocket.BroadcastToMap(obj.MapID, websocket.Message{
+		Type: "world_object_update",
+		Data: WorldObjectUpdate{
+			ObjectID: obj.ID,
+			PosX:     obj.PosX,
+			PosY:     obj.PosY,
+			IsActive: obj.IsActive,
+			State:    obj.State,
+		},
+	})
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+
+	"code-valley-api/internal/pathfinding"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoRoute      = errors.New("no walkable route to that tile")
+	ErrRouteTooLong = errors.New("that tile is too far away")
+)
+
+type PathRequest struct {
+	PosX int `json:"pos_x" validate:"min=0"`
+	PosY int `json:"pos_y" validate:"min=0"`
+}
+
+// PathResponse is a route for click-to-move. Every step is one tile, so
+// the client can send each as a player_move.
+type PathResponse struct {
+	MapID uuid.UUID           `json:"map_id"`
+	Path  []pathfinding.Point `json:"path"`
+	Steps int                 `json:"steps"`
+}
+
+// FindPlayerPath plans a route from where the player stands to a tile on
+// their map, following the same rules MovePlayer checks moves against. The
+// route is at most the configured maximum length.
+func (s *WorldService) FindPlayerPath(userID uuid.UUID, posX, posY int) (*PathResponse, error) {
+	position, err := s.worldRepo.GetPlayerPosition(userID)
+	if err != nil {
+		return nil, errors.New("player position not found")
+	}
+
+	grid, err := s.collisionGrid(position.MapID)
+	if err != nil {
+		return nil, err
+	}
+	if !grid.InBounds(posX, posY) {
+		return nil, ErrOutOfBounds
+	}
+	if !grid.Walkable(posX, posY) {
+		return nil, ErrPositionBlocked
+	}
+	if chebyshev(position.PosX, position.PosY, posX, posY) > s.maxRoute {
+		return nil, ErrRouteTooLong
+	}
+
+	from := pathfinding.Point{X: position.PosX, Y: position.PosY}
+	path, ok := grid.paths.Find(from, pathfinding.Point{X: posX, Y: posY})
+	if !ok {
+		return nil, ErrNoRoute
+	}
+	if len(path) > s.maxRoute {
+		return nil, ErrRouteTooLong
+	}
+	if path == nil {
+		path = []pathfinding.Point{}
+	}
+
+	return &PathResponse{MapID: position.MapID, Path: path, Steps: len(path)}, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/websocket"
+)
+
+// Services holds every service, wired once at startup.
+type Services struct {
+	Auth         *AuthService
+	Quest        *QuestService
+	Friend       *FriendService
+	Presence     *PresenceService
+	Chat         *ChatService
+	Leaderboard  *LeaderboardService
+	Shop         *ShopService
+	Notification *NotificationService
+	Inventory    *InventoryService
+	Admin        *AdminService
+	World        *WorldService
+	GameClock    *GameClockService
+	Ledger       *LedgerService
+	Loot         *LootService
+}
+
+// New wires all services on top of the given repositories. Multi-step
+// economy operations run through uow so they commit or roll back as a whole.
+func New(cfg *config.Config, repos *repositories.Repositories, uow repositories.UnitOfWork) *Services {
+	lootService := NewLootService(cfg, repos.Loot, uow)
+	worldService := NewWorldService(cfg, repos.World, repos.Users, repos.Inventory, repos.Quests, repos.AntiCheat, uow, lootService)
+	chatService := NewChatService(cfg, repos.Chat, repos.Friends, repos.Users, worldService, NewWordListFilter(cfg.Chat.BlockedWords), uow)
+
+	return &Services{
+		Auth:         NewAuthService(cfg, repos.Users, repos.RefreshTokens, repos.Sessions, repos.Bans, repos.Stats, uow),
+		Quest:        NewQuestService(repos.Quests, repos.Users, uow),
+		Friend:       NewFriendService(repos.Friends, repos.Users),
+		Presence:     NewPresenceService(repos.Friends),
+		Chat:         chatService,
+		Leaderboard:  NewLeaderboardService(repos.Leaderboard),
+		Shop:         NewShopService(repos.Shop, repos.Users, repos.Inventory, uow),
+		Notification: NewNotificationService(repos.Notifications),
+		Inventory:    NewInventoryService(repos.Inventory, repos.Users, uow),
+		Admin:        NewAdminService(cfg, repos.Users, repos.Bans, repos.AuditLogs, repos.Stats, repos.AntiCheat, uow),
+		World:        worldService,
+		GameClock:    NewGameClockService(repos.World, worldService),
+		Ledger:       NewLedgerService(repos.Ledger),
+		Loot:         lootService,
+	}
+}
+
+// RegisterSocketHandlers adds every service's messages and pushed events to
+// the WebSocket protocol.
+func (s *Services) RegisterSocketHandlers(r *websocket.Registry) {
+	s.World.RegisterSocketHandlers(r)
+	s.Chat.RegisterSocketHandlers(r)
+	s.Presence.RegisterSocketHandlers(r)
+	registerSocketEvents(r)
+}
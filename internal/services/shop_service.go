@@ -12,16 +12,18 @@ import (
 )
 
 type ShopService struct {
-	shopRepo      *repositories.ShopRepository
-	userRepo      *repositories.UserRepository
-	inventoryRepo *repositories.InventoryRepository
+	shopRepo      repositories.ShopRepository
+	userRepo      repositories.UserRepository
+	inventoryRepo repositories.InventoryRepository
+	uow           repositories.UnitOfWork
 }
 
-func NewShopService() *ShopService {
+func NewShopService(shopRepo repositories.ShopRepository, userRepo repositories.UserRepository, inventoryRepo repositories.InventoryRepository, uow repositories.UnitOfWork) *ShopService {
 	return &ShopService{
-		shopRepo:      repositories.NewShopRepository(),
-		userRepo:      repositories.NewUserRepository(),
-		inventoryRepo: repositories.NewInventoryRepository(),
+		shopRepo:      shopRepo,
+		userRepo:      userRepo,
+		inventoryRepo: inventoryRepo,
+		uow:           uow,
 	}
 }
 
@@ -56,70 +58,74 @@ type BuyItemRequest struct {
 	Quantity int `json:"quantity" validate:"min=1"`
 }
 
-func (s *ShopService) BuyItem(userID, itemID uuid.UUID, req BuyItemRequest) (*models.UserPurchase, error) {
+func (s *ShopService) BuyItem(userID, itemID uuid.UUID, req BuyItemRequest, client ClientInfo) (*models.UserPurchase, error) {
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, err
 	}
 
-	// Get item
-	item, err := s.shopRepo.GetItemByID(itemID)
-	if err != nil {
-		return nil, errors.New("item not found")
-	}
+	var purchase *models.UserPurchase
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		// Lock the buyer first, then the item, so concurrent purchases
+		// always acquire rows in the same order
+		user, err := repos.Users.GetByIDForUpdate(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
 
-	// Check stock
-	if item.Stock != -1 && item.Stock < req.Quantity {
-		return nil, errors.New("insufficient stock")
-	}
+		item, err := repos.Shop.GetItemByIDForUpdate(itemID)
+		if err != nil {
+			return errors.New("item not found")
+		}
 
-	// Get user
-	user, err := s.userRepo.GetByID(userID)
-	if err != nil {
-		return nil, errors.New("user not found")
-	}
+		// Check stock
+		if item.Stock != -1 && item.Stock < req.Quantity {
+			return errors.New("insufficient stock")
+		}
 
-	totalPrice := item.Price * req.Quantity
+		totalPrice := item.Price * req.Quantity
 
-	// Check if user has enough coins
-	if user.Coins < totalPrice {
-		return nil, errors.New("insufficient coins")
-	}
+		// Check if user has enough coins
+		if user.Coins < totalPrice {
+			return errors.New("insufficient coins")
+		}
 
-	// Create purchase
-	purchase := &models.UserPurchase{
-		UserID:     userID,
-		ShopItemID: itemID,
-		Quantity:   req.Quantity,
-		TotalPrice: totalPrice,
-		PurchasedAt: time.Now(),
-	}
+		// Create purchase
+		purchase = &models.UserPurchase{
+			UserID:      userID,
+			ShopItemID:  itemID,
+			Quantity:    req.Quantity,
+			TotalPrice:  totalPrice,
+			PurchasedAt: time.Now(),
+		}
 
-	if err := s.shopRepo.CreatePurchase(purchase); err != nil {
-		return nil, err
-	}
+		if err := repos.Shop.CreatePurchase(purchase); err != nil {
+			return err
+		}
 
-	// Update user coins
-	user.Coins -= totalPrice
-	if err := s.userRepo.Update(user); err != nil {
-		return nil, err
-	}
+		// Pay for the purchase
+		if err := postCoins(repos, client, user, -totalPrice, models.LedgerAccountShop, models.LedgerReasonPurchase, models.LedgerRefPurchase, purchase.ID); err != nil {
+			return err
+		}
 
-	// Update item stock
-	if item.Stock != -1 {
-		newStock := item.Stock - req.Quantity
-		if err := s.shopRepo.UpdateItemStock(itemID, newStock); err != nil {
-			return nil, err
+		// Update item stock
+		if item.Stock != -1 {
+			newStock := item.Stock - req.Quantity
+			if err := repos.Shop.UpdateItemStock(itemID, newStock); err != nil {
+				return err
+			}
 		}
-	}
 
-	// Add item to inventory
-	inventory := &models.Inventory{
-		UserID:   userID,
-		ItemName: item.Name,
-		Quantity: req.Quantity,
-		ItemType: models.ItemType(item.ItemType),
+		// Add item to inventory
+		return repos.Inventory.AddItem(&models.Inventory{
+			UserID:   userID,
+			ItemName: item.Name,
+			Quantity: req.Quantity,
+			ItemType: models.ItemType(item.ItemType),
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
-	s.inventoryRepo.AddItem(inventory)
 
 	return purchase, nil
 }
@@ -128,48 +134,47 @@ type SellItemRequest struct {
 	Quantity int `json:"quantity" validate:"min=1"`
 }
 
-func (s *ShopService) SellItem(userID, itemID uuid.UUID, req SellItemRequest) (map[string]interface{}, error) {
+func (s *ShopService) SellItem(userID, itemID uuid.UUID, req SellItemRequest, client ClientInfo) (map[string]interface{}, error) {
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, err
 	}
 
-	// Get item from inventory
-	inventoryItem, err := s.inventoryRepo.GetUserItem(userID, itemID)
-	if err != nil {
-		return nil, errors.New("item not found in inventory")
-	}
-
-	if inventoryItem.Quantity < req.Quantity {
-		return nil, errors.New("insufficient quantity")
-	}
-
 	// Calculate sell price (50% of original price)
 	sellPrice := 50 * req.Quantity // Base sell price
 
-	// Get user
-	user, err := s.userRepo.GetByID(userID)
-	if err != nil {
-		return nil, errors.New("user not found")
-	}
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		user, err := repos.Users.GetByIDForUpdate(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
 
-	// Update user coins
-	user.Coins += sellPrice
-	if err := s.userRepo.Update(user); err != nil {
-		return nil, err
-	}
+		// Get item from inventory
+		inventoryItem, err := repos.Inventory.GetUserItemForUpdate(userID, itemID)
+		if err != nil {
+			return errors.New("item not found in inventory")
+		}
+
+		if inventoryItem.Quantity < req.Quantity {
+			return errors.New("insufficient quantity")
+		}
+
+		// Pay the seller
+		if err := postCoins(repos, client, user, sellPrice, models.LedgerAccountShop, models.LedgerReasonSale, models.LedgerRefInventoryItem, itemID); err != nil {
+			return err
+		}
 
-	// Update inventory
-	if inventoryItem.Quantity == req.Quantity {
-		// Remove item completely
-		if err := s.inventoryRepo.RemoveItem(userID, itemID); err != nil {
-			return nil, err
+		// Update inventory
+		if inventoryItem.Quantity == req.Quantity {
+			// Remove item completely
+			return repos.Inventory.RemoveItem(userID, itemID)
 		}
-	} else {
+
 		// Reduce quantity
 		inventoryItem.Quantity -= req.Quantity
-		if err := s.inventoryRepo.UpdateItem(inventoryItem); err != nil {
-			return nil, err
-		}
+		return repos.Inventory.UpdateItem(inventoryItem)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return map[string]interface{}{
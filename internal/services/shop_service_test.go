@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"code-valley-api/internal/models"
+)
+
+func TestBuyItem(t *testing.T) {
+	store, repos := newTestStore()
+	buyer := addTestUser(t, repos, "buyer", 500)
+	item := store.AddShopItem(models.ShopItem{Name: "Debug Tool", Price: 120, ItemType: models.ShopItemTypeTool, IsAvailable: true, Stock: 5})
+	service := NewShopService(repos.Shop, repos.Users, repos.Inventory, store.UnitOfWork())
+
+	purchase, err := service.BuyItem(buyer.ID, item.ID, BuyItemRequest{Quantity: 2}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("BuyItem: %v", err)
+	}
+	if purchase.TotalPrice != 240 || purchase.Quantity != 2 {
+		t.Errorf("purchase %+v, want 2 for 240 coins", purchase)
+	}
+
+	user, _ := repos.Users.GetByID(buyer.ID)
+	if user.Coins != 260 {
+		t.Errorf("buyer has %d coins, want 260", user.Coins)
+	}
+	if balance, _ := repos.Ledger.GetBalance(buyer.ID); balance != -240 {
+		t.Errorf("ledger balance %d, want the -240 paid", balance)
+	}
+	stocked, _ := repos.Shop.GetItemByID(item.ID)
+	if stocked.Stock != 3 {
+		t.Errorf("stock %d, want 3", stocked.Stock)
+	}
+	inventory, _ := repos.Inventory.GetUserInventory(buyer.ID)
+	if len(inventory) != 1 || inventory[0].ItemName != "Debug Tool" || inventory[0].Quantity != 2 {
+		t.Errorf("inventory %+v, want 2 Debug Tools", inventory)
+	}
+}
+
+func TestBuyItemRefusals(t *testing.T) {
+	store, repos := newTestStore()
+	buyer := addTestUser(t, repos, "buyer", 100)
+	cheap := store.AddShopItem(models.ShopItem{Name: "Coffee", Price: 10, ItemType: models.ShopItemTypeResource, IsAvailable: true, Stock: 1})
+	dear := store.AddShopItem(models.ShopItem{Name: "Server Rack", Price: 500, ItemType: models.ShopItemTypeUpgrade, IsAvailable: true, Stock: -1})
+	service := NewShopService(repos.Shop, repos.Users, repos.Inventory, store.UnitOfWork())
+
+	tests := []struct {
+		name     string
+		item     models.ShopItem
+		quantity int
+		want     string
+	}{
+		{"out of stock", cheap, 2, "insufficient stock"},
+		{"too expensive", dear, 1, "insufficient coins"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.BuyItem(buyer.ID, tt.item.ID, BuyItemRequest{Quantity: tt.quantity}, ClientInfo{})
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	user, _ := repos.Users.GetByID(buyer.ID)
+	if user.Coins != 100 {
+		t.Errorf("buyer has %d coins after refused purchases, want 100", user.Coins)
+	}
+}
+
+func TestBuyItemRollsBackWhenCommitFails(t *testing.T) {
+	store, repos := newTestStore()
+	buyer := addTestUser(t, repos, "buyer", 500)
+	item := store.AddShopItem(models.ShopItem{Name: "Debug Tool", Price: 120, ItemType: models.ShopItemTypeTool, IsAvailable: true, Stock: 5})
+	service := NewShopService(repos.Shop, repos.Users, repos.Inventory, failingCommit{store.UnitOfWork()})
+
+	if _, err := service.BuyItem(buyer.ID, item.ID, BuyItemRequest{Quantity: 2}, ClientInfo{}); !errors.Is(err, errCommitFailed) {
+		t.Fatalf("BuyItem: got %v, want the commit error", err)
+	}
+
+	user, _ := repos.Users.GetByID(buyer.ID)
+	if user.Coins != 500 {
+		t.Errorf("buyer has %d coins, want the 500 they started with", user.Coins)
+	}
+	if history, total, _ := repos.Ledger.GetUserHistory(buyer.ID, defaultTestPage); total != 0 {
+		t.Errorf("ledger has %v, want no entries", history)
+	}
+	stocked, _ := repos.Shop.GetItemByID(item.ID)
+	if stocked.Stock != 5 {
+		t.Errorf("stock %d, want 5", stocked.Stock)
+	}
+	if purchases, _ := repos.Shop.GetUserPurchases(buyer.ID); len(purchases) != 0 {
+		t.Errorf("purchases %+v, want none", purchases)
+	}
+	if inventory, _ := repos.Inventory.GetUserInventory(buyer.ID); len(inventory) != 0 {
+		t.Errorf("inventory %+v, want it empty", inventory)
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/pathfinding"
+	"code-valley-api/internal/websocket"
+
+	"github.com/google/uuid"
+)
+
+// Payloads of the events services push over the WebSocket.
+
+type PlayerPositionUpdate struct {
+	UserID    uuid.UUID `json:"user_id"`
+	MapID     uuid.UUID `json:"map_id"`
+	PosX      int       `json:"pos_x"`
+	PosY      int       `json:"pos_y"`
+	Direction string    `json:"direction"`
+}
+
+// MovementCorrection puts a player back where the server has them after a
+// rejected move.
+type MovementCorrection struct {
+	MapID     uuid.UUID `json:"map_id"`
+	PosX      int       `json:"pos_x"`
+	PosY      int       `json:"pos_y"`
+	Direction string    `json:"direction"`
+	Reason    string    `json:"reason"`
+}
+
+type PlayerLeftMap struct {
+	UserID uuid.UUID `json:"user_id"`
+	MapID  uuid.UUID `json:"map_id"`
+}
+
+// MapChanged tells a player they are now on another map, after a portal
+// or a teleport. Portal is empty for teleports.
+type MapChanged struct {
+	FromMapID uuid.UUID `json:"from_map_id"`
+	MapID     uuid.UUID `json:"map_id"`
+	MapName   string    `json:"map_name"`
+	PosX      int       `json:"pos_x"`
+	PosY      int       `json:"pos_y"`
+	Direction string    `json:"direction"`
+	Portal    string    `json:"portal,omitempty"`
+}
+
+// PortalLocked tells a player why a portal they stepped on did not take
+// them anywhere.
+type PortalLocked struct {
+	Portal string `json:"portal"`
+	Reason string `json:"reason"`
+}
+
+// NPCPositionUpdate is where an NPC is now, with the tiles it walked to
+// get there since the last update so clients can animate the walk.
+type NPCPositionUpdate struct {
+	NPCID     uuid.UUID           `json:"npc_id"`
+	MapID     uuid.UUID           `json:"map_id"`
+	PosX      int                 `json:"pos_x"`
+	PosY      int                 `json:"pos_y"`
+	Direction string              `json:"direction"`
+	Action    string              `json:"action"`
+	Path      []pathfinding.Point `json:"path"`
+}
+
+type NPCLeftMap struct {
+	NPCID uuid.UUID `json:"npc_id"`
+	MapID uuid.UUID `json:"map_id"`
+}
+
+type WorldObjectUpdate struct {
+	ObjectID uuid.UUID          `json:"object_id"`
+	PosX     int                `json:"pos_x"`
+	PosY     int                `json:"pos_y"`
+	IsActive bool               `json:"is_active"`
+	State    models.ObjectState `json:"state"`
+}
+
+type TimeUpdate struct {
+	GameYear   int    `json:"game_year"`
+	GameSeason string `json:"game_season"`
+	GameDay    int    `json:"game_day"`
+	GameHour   int    `json:"game_hour"`
+	GameMinute int    `json:"game_minute"`
+}
+
+type SeasonChange struct {
+	NewSeason string `json:"new_season"`
+	GameYear  int    `json:"game_year"`
+}
+
+type UserStatusUpdate struct {
+	UserID   uuid.UUID             `json:"user_id"`
+	IsOnline bool                  `json:"is_online"`
+	Status   models.PresenceStatus `json:"status"`
+	LastSeen time.Time             `json:"last_seen"`
+}
+
+type ChatMessageDeleted struct {
+	ID      uuid.UUID          `json:"id"`
+	Channel models.ChatChannel `json:"channel"`
+	ScopeID *uuid.UUID         `json:"scope_id"`
+}
+
+// socketErrorCodes gives service errors the protocol error code clients
+// can act on.
+var socketErrorCodes = map[error]websocket.ErrorCode{
+	ErrChatSlowMode:        websocket.ErrCodeRateLimited,
+	ErrChatBlocked:         websocket.ErrCodeForbidden,
+	ErrNotGuildMember:      websocket.ErrCodeForbidden,
+	ErrChatMessageNotFound: websocket.ErrCodeNotFound,
+	ErrMoveTooFast:         websocket.ErrCodeRateLimited,
+}
+
+// socketError maps a service error for a WebSocket reply. Other errors are
+// passed through and reported as validation failures or rejections.
+func socketError(err error) error {
+	for known, code := range socketErrorCodes {
+		if errors.Is(err, known) {
+			return websocket.NewError(code, err.Error())
+		}
+	}
+	return err
+}
+
+// registerSocketEvents documents the events services push.
+func registerSocketEvents(r *websocket.Registry) {
+	r.Event("player_position_update", "A player on your map moved.", PlayerPositionUpdate{})
+	r.Event("movement_correction", "Your last move was rejected; snap back to this position.", MovementCorrection{})
+	r.Event("player_left_map", "A player left your map.", PlayerLeftMap{})
+	r.Event("map_changed", "You are on another map now, after a portal or teleport.", MapChanged{})
+	r.Event("portal_locked", "The portal you stepped on did not let you through.", PortalLocked{})
+	r.Event("npc_position_update", "A scheduled NPC on your map moved, with the tiles it walked.", NPCPositionUpdate{})
+	r.Event("npc_left_map", "An NPC left your map.", NPCLeftMap{})
+	r.Event("world_object_update", "A world object on your map changed state.", WorldObjectUpdate{})
+	r.Event("time_update", "The game clock advanced.", TimeUpdate{})
+	r.Event("season_change", "A new season started.", SeasonChange{})
+	r.Event("user_status", "A friend's visible presence changed.", UserStatusUpdate{})
+	r.Event("chat_message", "A chat message you can see was posted.", models.ChatMessageResponse{})
+	r.Event("chat_message_deleted", "An admin removed a chat message.", ChatMessageDeleted{})
+	r.Event("account_banned", "Your account was banned; the connection closes next.", nil)
+	r.Event("quest_update", "Your quest progress changed.", nil)
+	r.Event("friend_request", "A friend request was sent to you or accepted.", nil)
+	r.Event("achievement_unlocked", "You earned an achievement.", nil)
+	r.Event("level_up", "You reached a new level.", nil)
+	r.Event("event_broadcast", "A global announcement.", nil)
+}
@@ -2,9 +2,12 @@ package services
 
 import (
 	"errors"
+	"log"
 	"time"
 
+	"code-valley-api/internal/config"
 	"code-valley-api/internal/models"
+	"code-valley-api/internal/pathfinding"
 	"code-valley-api/internal/repositories"
 	"code-valley-api/internal/utils"
 	"code-valley-api/internal/websocket"
@@ -13,17 +16,93 @@ import (
 )
 
 type WorldService struct {
-	worldRepo     *repositories.WorldRepository
-	userRepo      *repositories.UserRepository
-	inventoryRepo *repositories.InventoryRepository
+	worldRepo     repositories.WorldRepository
+	userRepo      repositories.UserRepository
+	inventoryRepo repositories.InventoryRepository
+	questRepo     repositories.QuestRepository
+	antiCheatRepo repositories.AntiCheatRepository
+	uow           repositories.UnitOfWork
+	loot          *LootService
+	movement      *movementRules
+	collision     *collisionCache
+	pathOptions   pathfinding.Options
+	maxRoute      int
 }
 
-func NewWorldService() *WorldService {
+func NewWorldService(cfg *config.Config, worldRepo repositories.WorldRepository, userRepo repositories.UserRepository, inventoryRepo repositories.InventoryRepository, questRepo repositories.QuestRepository, antiCheatRepo repositories.AntiCheatRepository, uow repositories.UnitOfWork, loot *LootService) *WorldService {
+	heuristic, err := pathfinding.HeuristicByName(cfg.Pathfinding.Heuristic)
+	if err != nil {
+		log.Printf("PATH_HEURISTIC: %v; using chebyshev", err)
+		heuristic = pathfinding.Chebyshev
+	}
+
 	return &WorldService{
-		worldRepo:     repositories.NewWorldRepository(),
-		userRepo:      repositories.NewUserRepository(),
-		inventoryRepo: repositories.NewInventoryRepository(),
+		worldRepo:     worldRepo,
+		userRepo:      userRepo,
+		inventoryRepo: inventoryRepo,
+		questRepo:     questRepo,
+		antiCheatRepo: antiCheatRepo,
+		uow:           uow,
+		loot:          loot,
+		movement:      newMovementRules(cfg.Movement),
+		collision:     newCollisionCache(),
+		pathOptions: pathfinding.Options{
+			Heuristic:    heuristic,
+			DiagonalCost: cfg.Pathfinding.DiagonalCost,
+			MaxNodes:     cfg.Pathfinding.MaxNodes,
+		},
+		maxRoute: cfg.Pathfinding.MaxRouteLength,
+	}
+}
+
+// UpdateMapLayout replaces a map's layout. The layout must fit the map;
+// the map's collision grid is rebuilt on next use.
+func (s *WorldService) UpdateMapLayout(actor Actor, mapID uuid.UUID, layout models.MapLayout) (*models.Map, error) {
+	var updated *models.Map
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		mapData, err := repos.World.GetMapByID(mapID)
+		if err != nil {
+			return errors.New("map not found")
+		}
+		before := mapData.Layout
+
+		mapData.Layout = layout
+		if err := repos.World.UpdateMap(mapData); err != nil {
+			return err
+		}
+
+		updated = mapData
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionMapLayout, models.AuditTargetMap, mapData.ID, before, layout)
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	s.collision.invalidate(mapID)
+	return updated, nil
+}
+
+// farmMapName is the map code farm plots are laid out on.
+const farmMapName = "data_farm"
+
+var (
+	ErrNotFarmland  = errors.New("plot is not on farmland")
+	ErrUnknownSpawn = errors.New("spawn point not found")
+)
+
+// collisionGrid returns the cached walkability of a map.
+func (s *WorldService) collisionGrid(mapID uuid.UUID) (*collisionGrid, error) {
+	return s.collision.get(mapID, func() (*collisionGrid, error) {
+		mapData, err := s.worldRepo.GetMapByID(mapID)
+		if err != nil {
+			return nil, errors.New("map not found")
+		}
+		objects, err := s.worldRepo.GetWorldObjects(mapID)
+		if err != nil {
+			return nil, err
+		}
+		return newCollisionGrid(mapData, objects, s.pathOptions), nil
+	})
 }
 
 type MapStateResponse struct {
@@ -64,15 +143,18 @@ func (s *WorldService) GetMapState(mapName string, userID uuid.UUID) (*MapStateR
 	_, err = s.worldRepo.GetPlayerPosition(userID)
 	if err != nil {
 		// Create default position for new player
+		spawn := defaultSpawn(mapData)
 		defaultPosition := &models.PlayerPosition{
 			UserID:    userID,
 			MapID:     mapData.ID,
-			PosX:      mapData.Width / 2,
-			PosY:      mapData.Height / 2,
+			PosX:      spawn.X,
+			PosY:      spawn.Y,
 			Direction: "down",
 			LastMoved: time.Now(),
 		}
-		s.worldRepo.CreatePlayerPosition(defaultPosition)
+		if err := s.worldRepo.CreatePlayerPosition(defaultPosition); err == nil {
+			websocket.MoveUserToMap(userID, mapData.ID)
+		}
 	}
 
 	return &MapStateResponse{
@@ -88,13 +170,36 @@ func (s *WorldService) GetPlayerPosition(userID uuid.UUID) (*models.PlayerPositi
 	return s.worldRepo.GetPlayerPosition(userID)
 }
 
+// CurrentMapID implements websocket.MapLocator.
+func (s *WorldService) CurrentMapID(userID uuid.UUID) (uuid.UUID, error) {
+	position, err := s.worldRepo.GetPlayerPosition(userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return position.MapID, nil
+}
+
 type TeleportRequest struct {
 	MapName string `json:"map_name" validate:"required"`
 	PosX    int    `json:"pos_x" validate:"min=0"`
 	PosY    int    `json:"pos_y" validate:"min=0"`
+	// Spawn names a spawn point of the map to arrive at instead of
+	// PosX/PosY
+	Spawn string `json:"spawn" validate:"omitempty,max=64"`
 }
 
-func (s *WorldService) TeleportPlayer(userID uuid.UUID, req TeleportRequest) (*models.PlayerPosition, error) {
+// defaultSpawn is where players arrive on a map when no position is given:
+// its default spawn point, or its centre.
+func defaultSpawn(mapData *models.Map) models.SpawnPoint {
+	if spawn, ok := mapData.Layout.Spawn(""); ok {
+		return spawn
+	}
+	return models.SpawnPoint{Name: models.DefaultSpawn, X: mapData.Width / 2, Y: mapData.Height / 2}
+}
+
+// TeleportPlayer places the acting admin on a map, at a spawn point or a
+// free tile, and audits the jump.
+func (s *WorldService) TeleportPlayer(actor Actor, req TeleportRequest) (*models.PlayerPosition, error) {
 	if err := utils.ValidateStruct(req); err != nil {
 		return nil, err
 	}
@@ -104,101 +209,244 @@ func (s *WorldService) TeleportPlayer(userID uuid.UUID, req TeleportRequest) (*m
 		return nil, errors.New("map not found")
 	}
 
-	if req.PosX >= mapData.Width || req.PosY >= mapData.Height {
-		return nil, errors.New("position out of bounds")
+	if req.Spawn != "" {
+		spawn, ok := mapData.Layout.Spawn(req.Spawn)
+		if !ok {
+			return nil, ErrUnknownSpawn
+		}
+		req.PosX, req.PosY = spawn.X, spawn.Y
 	}
 
-	position, err := s.worldRepo.GetPlayerPosition(userID)
+	grid, err := s.collisionGrid(mapData.ID)
 	if err != nil {
-		// Create new position
-		position = &models.PlayerPosition{
-			UserID:    userID,
-			MapID:     mapData.ID,
-			PosX:      req.PosX,
-			PosY:      req.PosY,
-			Direction: "down",
-			LastMoved: time.Now(),
-		}
-		err = s.worldRepo.CreatePlayerPosition(position)
-	} else {
-		// Update existing position
-		position.MapID = mapData.ID
-		position.PosX = req.PosX
-		position.PosY = req.PosY
-		position.LastMoved = time.Now()
-		err = s.worldRepo.UpdatePlayerPosition(position)
+		return nil, err
 	}
-
+	if !grid.InBounds(req.PosX, req.PosY) {
+		return nil, ErrOutOfBounds
+	}
+	if !grid.Walkable(req.PosX, req.PosY) {
+		return nil, ErrPositionBlocked
+	}
+
+	var previousMapID uuid.UUID
+	var position *models.PlayerPosition
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		var before *models.PlayerPosition
+		existing, err := repos.World.GetPlayerPositionForUpdate(actor.UserID)
+		if err != nil {
+			// Create new position
+			position = &models.PlayerPosition{
+				UserID:    actor.UserID,
+				MapID:     mapData.ID,
+				PosX:      req.PosX,
+				PosY:      req.PosY,
+				Direction: "down",
+				LastMoved: time.Now(),
+			}
+			err = repos.World.CreatePlayerPosition(position)
+		} else {
+			// Update existing position
+			snapshot := *existing
+			before, position = &snapshot, existing
+			previousMapID = position.MapID
+			position.MapID = mapData.ID
+			position.PosX = req.PosX
+			position.PosY = req.PosY
+			position.LastMoved = time.Now()
+			err = repos.World.UpdatePlayerPosition(position)
+		}
+		if err != nil {
+			return err
+		}
+		return recordAudit(repos.AuditLogs, actor, models.AuditActionTeleport, models.AuditTargetUser, actor.UserID, before, position)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Broadcast position update
+	s.announceArrival(position, previousMapID, mapData, "")
+	return position, nil
+}
+
+// announceArrival tells everyone concerned that a player was placed on a
+// map by a teleport or a portal: a player who changed maps switches rooms
+// and gets map_changed, the map they left sees them go and the map they
+// are on sees where they are.
+func (s *WorldService) announceArrival(position *models.PlayerPosition, previousMapID uuid.UUID, mapData *models.Map, portal string) {
+	if previousMapID != mapData.ID {
+		websocket.MoveUserToMap(position.UserID, mapData.ID)
+		if previousMapID != uuid.Nil {
+			websocket.BroadcastToMap(previousMapID, websocket.Message{
+				Type: "player_left_map",
+				Data: PlayerLeftMap{UserID: position.UserID, MapID: previousMapID},
+			})
+		}
+		websocket.SendToUser(position.UserID, websocket.Message{
+			Type: "map_changed",
+			Data: MapChanged{
+				FromMapID: previousMapID,
+				MapID:     mapData.ID,
+				MapName:   mapData.Name,
+				PosX:      position.PosX,
+				PosY:      position.PosY,
+				Direction: position.Direction,
+				Portal:    portal,
+			},
+		})
+	}
+
 	websocket.BroadcastToMap(mapData.ID, websocket.Message{
 		Type: "player_position_update",
-		Data: map[string]interface{}{
-			"user_id":   userID,
-			"map_id":    mapData.ID,
-			"pos_x":     req.PosX,
-			"pos_y":     req.PosY,
-			"direction": position.Direction,
+		Data: PlayerPositionUpdate{
+			UserID:    position.UserID,
+			MapID:     mapData.ID,
+			PosX:      position.PosX,
+			PosY:      position.PosY,
+			Direction: position.Direction,
 		},
 	})
+}
 
-	return position, nil
+type MoveRequest struct {
+	PosX      int    `json:"pos_x" validate:"min=0"`
+	PosY      int    `json:"pos_y" validate:"min=0"`
+	Direction string `json:"direction" validate:"required,oneof=up down left right"`
+}
+
+type InteractRequest struct {
+	TargetX int `json:"target_x"`
+	TargetY int `json:"target_y"`
+}
+
+// RegisterSocketHandlers adds movement and interaction to the WebSocket
+// protocol.
+func (s *WorldService) RegisterSocketHandlers(r *websocket.Registry) {
+	websocket.Handle(r, "player_move", "Move to a tile on your current map.",
+		func(ctx *websocket.Context, req MoveRequest) (websocket.Empty, error) {
+			return websocket.Empty{}, socketError(s.MovePlayer(ctx.UserID, req.PosX, req.PosY, req.Direction))
+		})
+	websocket.Handle(r, "player_path_request", "Plan a walkable route to a tile on your current map, for click-to-move.",
+		func(ctx *websocket.Context, req PathRequest) (*PathResponse, error) {
+			route, err := s.FindPlayerPath(ctx.UserID, req.PosX, req.PosY)
+			return route, socketError(err)
+		})
+	websocket.Handle(r, "player_interact", "Use the object on an adjacent tile.",
+		func(ctx *websocket.Context, req InteractRequest) (map[string]interface{}, error) {
+			result, err := s.InteractWithObject(ctx.UserID, req.TargetX, req.TargetY)
+			return result, socketError(err)
+		})
 }
 
+// MovePlayer moves a player to a tile the server agrees they can reach: on
+// the map, not blocked, connected by a walkable path and within their
+// movement budget. A rejected move snaps the client back with a
+// movement_correction event, and moves no honest client would send are
+// logged for admins. Stepping onto a portal takes the player through it.
 func (s *WorldService) MovePlayer(userID uuid.UUID, posX, posY int, direction string) error {
 	position, err := s.worldRepo.GetPlayerPosition(userID)
 	if err != nil {
 		return errors.New("player position not found")
 	}
 
-	// Validate movement (basic bounds checking)
-	mapData, err := s.worldRepo.GetMapByID(position.MapID)
+	mapID := position.MapID
+	grid, err := s.collisionGrid(mapID)
 	if err != nil {
-		return errors.New("map not found")
-	}
-
-	if posX < 0 || posX >= mapData.Width || posY < 0 || posY >= mapData.Height {
-		return errors.New("position out of bounds")
+		return err
 	}
 
-	// Check for collisions with world objects
-	objects, err := s.worldRepo.GetWorldObjectsAt(position.MapID, posX, posY)
-	if err == nil && len(objects) > 0 {
-		for _, obj := range objects {
-			if obj.ObjectType == models.ObjectTypeRock || obj.ObjectType == models.ObjectTypeTree {
-				return errors.New("position blocked by object")
-			}
+	// Lock the position so two moves sent at once, over different
+	// connections or the HTTP route, can't both spend the same budget
+	var now time.Time
+	var rejection *moveRejection
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		position, err = repos.World.GetPlayerPositionForUpdate(userID)
+		if err != nil {
+			return errors.New("player position not found")
+		}
+		if position.MapID != mapID {
+			return errors.New("player changed maps")
 		}
-	}
 
-	// Update position
-	position.PosX = posX
-	position.PosY = posY
-	position.Direction = direction
-	position.LastMoved = time.Now()
+		now = time.Now()
+		var lastMoved time.Time
+		lastMoved, rejection = s.movement.check(grid, position, posX, posY, now)
+		if rejection != nil {
+			return nil
+		}
 
-	if err := s.worldRepo.UpdatePlayerPosition(position); err != nil {
+		// Update position
+		position.PosX = posX
+		position.PosY = posY
+		position.Direction = direction
+		position.LastMoved = lastMoved
+		return repos.World.UpdatePlayerPosition(position)
+	})
+	if err != nil {
 		return err
 	}
+	if rejection != nil {
+		s.rejectMove(position, posX, posY, now, rejection)
+		return rejection
+	}
 
 	// Broadcast movement
 	websocket.BroadcastToMap(position.MapID, websocket.Message{
 		Type: "player_position_update",
-		Data: map[string]interface{}{
-			"user_id":   userID,
-			"map_id":    position.MapID,
-			"pos_x":     posX,
-			"pos_y":     posY,
-			"direction": direction,
+		Data: PlayerPositionUpdate{
+			UserID:    userID,
+			MapID:     position.MapID,
+			PosX:      posX,
+			PosY:      posY,
+			Direction: direction,
 		},
 	})
 
+	if portal, ok := grid.layout.PortalAt(posX, posY); ok {
+		s.enterPortal(position, portal)
+	}
 	return nil
 }
 
+// rejectMove corrects the client and records the move if it looks like
+// cheating. Repeated too-fast moves are recorded once they add up to a
+// strike limit.
+func (s *WorldService) rejectMove(position *models.PlayerPosition, posX, posY int, now time.Time, rejection *moveRejection) {
+	websocket.SendToUser(position.UserID, websocket.Message{
+		Type: "movement_correction",
+		Data: MovementCorrection{
+			MapID:     position.MapID,
+			PosX:      position.PosX,
+			PosY:      position.PosY,
+			Direction: position.Direction,
+			Reason:    rejection.Error(),
+		},
+	})
+
+	if rejection.kind == "" && errors.Is(rejection, ErrMoveTooFast) && s.movement.strikes.add(position.UserID, now) {
+		rejection.kind = models.CheatSpeed
+		rejection.details = "repeatedly moved faster than allowed"
+	}
+	if rejection.kind == "" {
+		return
+	}
+
+	event := &models.AntiCheatEvent{
+		UserID:    position.UserID,
+		MapID:     position.MapID,
+		Kind:      rejection.kind,
+		FromX:     position.PosX,
+		FromY:     position.PosY,
+		ToX:       posX,
+		ToY:       posY,
+		ElapsedMs: now.Sub(position.LastMoved).Milliseconds(),
+		Details:   rejection.details,
+	}
+	if err := s.antiCheatRepo.Create(event); err != nil {
+		log.Printf("Failed to record anti-cheat event for %s: %v", position.UserID, err)
+	}
+}
+
 func (s *WorldService) InteractWithObject(userID uuid.UUID, targetX, targetY int) (map[string]interface{}, error) {
 	position, err := s.worldRepo.GetPlayerPosition(userID)
 	if err != nil {
@@ -216,35 +464,49 @@ func (s *WorldService) InteractWithObject(userID uuid.UUID, targetX, targetY int
 		return nil, errors.New("no interactable object found")
 	}
 
-	obj := objects[0]
-	result := make(map[string]interface{})
+	// Lock the object so two players can't both loot it, and roll the
+	// drops back if its new state can't be saved
+	var obj *models.WorldObject
+	var result map[string]interface{}
+	err = s.uow.Do(func(repos *repositories.Repositories) error {
+		var err error
+		obj, err = repos.World.GetWorldObjectForUpdate(objects[0].ID)
+		if err != nil || !obj.IsActive {
+			return errors.New("no interactable object found")
+		}
+		if obj.State == nil {
+			// Stored as JSON null, which the handlers below can't write to
+			obj.State = make(models.ObjectState)
+		}
+
+		switch obj.ObjectType {
+		case models.ObjectTypeTree:
+			result, err = s.chopTree(repos, userID, obj)
+		case models.ObjectTypeRock:
+			result, err = s.mineRock(repos, userID, obj)
+		case models.ObjectTypeChest:
+			result, err = s.openChest(repos, userID, obj)
+		case models.ObjectTypeServer:
+			result = s.accessServer(userID, obj)
+		default:
+			return errors.New("object not interactable")
+		}
+		if err != nil {
+			return err
+		}
 
-	switch obj.ObjectType {
-	case models.ObjectTypeTree:
-		result = s.chopTree(userID, &obj)
-	case models.ObjectTypeRock:
-		result = s.mineRock(userID, &obj)
-	case models.ObjectTypeChest:
-		result = s.openChest(userID, &obj)
-	case models.ObjectTypeServer:
-		result = s.accessServer(userID, &obj)
-	default:
-		return nil, errors.New("object not interactable")
+		return repos.World.UpdateWorldObject(obj)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Update object state
-	s.worldRepo.UpdateWorldObject(&obj)
+	if !obj.IsActive {
+		s.collision.invalidate(position.MapID)
+	}
 
 	// Broadcast object update
-	websocket.BroadcastToMap(position.MapID, websocket.Message{
-		Type: "world_object_update",
-		Data: map[string]interface{}{
-			"object_id": obj.ID,
-			"pos_x":     obj.PosX,
-			"pos_y":     obj.PosY,
-			"state":     obj.State,
-		},
-	})
+	broadcastObjectUpdate(obj)
 
 	return result, nil
 }
@@ -269,6 +531,10 @@ func (s *WorldService) PlantCode(userID uuid.UUID, req PlantCodeRequest) (*model
 		return nil, err
 	}
 
+	if err := s.checkFarmPlot(req.PlotX, req.PlotY); err != nil {
+		return nil, err
+	}
+
 	// Check if plot is already occupied
 	existing, err := s.worldRepo.GetCodeFarmAt(userID, req.PlotX, req.PlotY)
 	if err == nil && existing != nil {
@@ -297,6 +563,30 @@ func (s *WorldService) PlantCode(userID uuid.UUID, req PlantCodeRequest) (*model
 	return farm, nil
 }
 
+// checkFarmPlot makes sure a plot is a free tile of the farm map, on its
+// farmland when the layout marks any.
+func (s *WorldService) checkFarmPlot(x, y int) error {
+	farm, err := s.worldRepo.GetMapByName(farmMapName)
+	if err != nil {
+		return errors.New("farm map not found")
+	}
+
+	grid, err := s.collisionGrid(farm.ID)
+	if err != nil {
+		return err
+	}
+	if !grid.InBounds(x, y) {
+		return ErrOutOfBounds
+	}
+	if !grid.Walkable(x, y) {
+		return ErrPositionBlocked
+	}
+	if len(farm.Layout.ZonesOf(models.ZoneFarmland)) > 0 && !grid.InZone(x, y, models.ZoneFarmland) {
+		return ErrNotFarmland
+	}
+	return nil
+}
+
 func (s *WorldService) WaterCode(userID uuid.UUID, farmID uuid.UUID) (*models.CodeFarm, error) {
 	farm, err := s.worldRepo.GetCodeFarm(farmID)
 	if err != nil {
@@ -324,56 +614,75 @@ func (s *WorldService) WaterCode(userID uuid.UUID, farmID uuid.UUID) (*models.Co
 	return farm, nil
 }
 
-func (s *WorldService) HarvestCode(userID uuid.UUID, farmID uuid.UUID) (map[string]interface{}, error) {
-	farm, err := s.worldRepo.GetCodeFarm(farmID)
-	if err != nil {
-		return nil, errors.New("code farm not found")
-	}
+func (s *WorldService) HarvestCode(userID uuid.UUID, farmID uuid.UUID, client ClientInfo) (map[string]interface{}, error) {
+	var (
+		farm  *models.CodeFarm
+		item  *models.Inventory
+		coins int
+		exp   int
+	)
+	err := s.uow.Do(func(repos *repositories.Repositories) error {
+		user, err := repos.Users.GetByIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
 
-	if farm.UserID != userID {
-		return nil, errors.New("not your code farm")
-	}
+		// The farm row stays locked until it is deleted below, so a
+		// second harvest of the same farm waits and then finds nothing
+		farm, err = repos.World.GetCodeFarmForUpdate(farmID)
+		if err != nil {
+			return errors.New("code farm not found")
+		}
 
-	if farm.HarvestAt == nil || time.Now().Before(*farm.HarvestAt) {
-		return nil, errors.New("code not ready for harvest")
-	}
+		if farm.UserID != userID {
+			return errors.New("not your code farm")
+		}
 
-	// Calculate harvest rewards based on quality and growth stage
-	baseReward := 50
-	qualityMultiplier := 1.0
-	switch farm.Quality {
-	case "silver":
-		qualityMultiplier = 1.25
-	case "gold":
-		qualityMultiplier = 1.5
-	case "iridium":
-		qualityMultiplier = 2.0
-	}
+		if farm.HarvestAt == nil || time.Now().Before(*farm.HarvestAt) {
+			return errors.New("code not ready for harvest")
+		}
 
-	coins := int(float64(baseReward) * qualityMultiplier * float64(farm.GrowthStage))
-	exp := coins / 2
+		// Calculate harvest rewards based on quality and growth stage
+		baseReward := 50
+		qualityMultiplier := 1.0
+		switch farm.Quality {
+		case "silver":
+			qualityMultiplier = 1.25
+		case "gold":
+			qualityMultiplier = 1.5
+		case "iridium":
+			qualityMultiplier = 2.0
+		}
 
-	// Add rewards to user
-	user, err := s.userRepo.GetByID(userID)
-	if err != nil {
-		return nil, err
-	}
+		coins = int(float64(baseReward) * qualityMultiplier * float64(farm.GrowthStage))
+		exp = coins / 2
 
-	user.Coins += coins
-	user.EXP += exp
-	s.userRepo.Update(user)
+		// Add rewards to user
+		if err := postCoins(repos, client, user, coins, models.LedgerAccountHarvest, models.LedgerReasonHarvest, models.LedgerRefCodeFarm, farmID); err != nil {
+			return err
+		}
+		user.EXP += exp
+		if err := repos.Users.Update(user); err != nil {
+			return err
+		}
 
-	// Add harvested item to inventory
-	item := &models.Inventory{
-		UserID:   userID,
-		ItemName: farm.CodeType + " Library",
-		Quantity: 1,
-		ItemType: models.ItemTypeCode,
-	}
-	s.inventoryRepo.AddItem(item)
+		// Add harvested item to inventory
+		item = &models.Inventory{
+			UserID:   userID,
+			ItemName: farm.CodeType + " Library",
+			Quantity: 1,
+			ItemType: models.ItemTypeCode,
+		}
+		if err := repos.Inventory.AddItem(item); err != nil {
+			return err
+		}
 
-	// Remove the farm
-	s.worldRepo.DeleteCodeFarm(farmID)
+		// Remove the farm
+		return repos.World.DeleteCodeFarm(farmID)
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	return map[string]interface{}{
 		"coins_earned": coins,
@@ -384,10 +693,13 @@ func (s *WorldService) HarvestCode(userID uuid.UUID, farmID uuid.UUID) (map[stri
 }
 
 // Helper functions for object interactions
-func (s *WorldService) chopTree(userID uuid.UUID, obj *models.WorldObject) map[string]interface{} {
-	hp := 3
-	if obj.State["hp"] != nil {
-		hp = int(obj.State["hp"].(float64))
+func (s *WorldService) chopTree(repos *repositories.Repositories, userID uuid.UUID, obj *models.WorldObject) (map[string]interface{}, error) {
+	hp, ok := stateInt(obj.State, "hp")
+	if !ok {
+		hp = 3
+	}
+	if _, ok := obj.State["max_hp"]; !ok {
+		obj.State["max_hp"] = hp
 	}
 
 	hp--
@@ -395,31 +707,32 @@ func (s *WorldService) chopTree(userID uuid.UUID, obj *models.WorldObject) map[s
 
 	if hp <= 0 {
 		obj.IsActive = false
-		// Add wood to inventory
-		item := &models.Inventory{
-			UserID:   userID,
-			ItemName: "Code Snippet",
-			Quantity: 2,
-			ItemType: models.ItemTypeResource,
+		stampDepleted(repos, obj)
+		drops, err := s.dropLoot(repos, userID, obj)
+		if err != nil {
+			return nil, err
 		}
-		s.inventoryRepo.AddItem(item)
 
 		return map[string]interface{}{
 			"action":       "tree_chopped",
-			"items_gained": []string{"Code Snippet x2"},
-		}
+			"items_gained": itemsGained(drops),
+			"drops":        drops,
+		}, nil
 	}
 
 	return map[string]interface{}{
 		"action":        "tree_damaged",
 		"remaining_hp":  hp,
-	}
+	}, nil
 }
 
-func (s *WorldService) mineRock(userID uuid.UUID, obj *models.WorldObject) map[string]interface{} {
-	hp := 2
-	if obj.State["hp"] != nil {
-		hp = int(obj.State["hp"].(float64))
+func (s *WorldService) mineRock(repos *repositories.Repositories, userID uuid.UUID, obj *models.WorldObject) (map[string]interface{}, error) {
+	hp, ok := stateInt(obj.State, "hp")
+	if !ok {
+		hp = 2
+	}
+	if _, ok := obj.State["max_hp"]; !ok {
+		obj.State["max_hp"] = hp
 	}
 
 	hp--
@@ -427,53 +740,45 @@ func (s *WorldService) mineRock(userID uuid.UUID, obj *models.WorldObject) map[s
 
 	if hp <= 0 {
 		obj.IsActive = false
-		// Add ore to inventory
-		item := &models.Inventory{
-			UserID:   userID,
-			ItemName: "Raw Data",
-			Quantity: 1,
-			ItemType: models.ItemTypeResource,
+		stampDepleted(repos, obj)
+		drops, err := s.dropLoot(repos, userID, obj)
+		if err != nil {
+			return nil, err
 		}
-		s.inventoryRepo.AddItem(item)
 
 		return map[string]interface{}{
 			"action":       "rock_mined",
-			"items_gained": []string{"Raw Data x1"},
-		}
+			"items_gained": itemsGained(drops),
+			"drops":        drops,
+		}, nil
 	}
 
 	return map[string]interface{}{
 		"action":       "rock_damaged",
 		"remaining_hp": hp,
-	}
+	}, nil
 }
 
-func (s *WorldService) openChest(userID uuid.UUID, obj *models.WorldObject) map[string]interface{} {
+func (s *WorldService) openChest(repos *repositories.Repositories, userID uuid.UUID, obj *models.WorldObject) (map[string]interface{}, error) {
 	if obj.State["is_looted"] == true {
 		return map[string]interface{}{
 			"action":  "chest_empty",
 			"message": "This chest has already been looted",
-		}
+		}, nil
 	}
 
 	obj.State["is_looted"] = true
-
-	// Add random item to inventory
-	items := []string{"Debug Tool", "Refactor Kit", "Unit Test Template"}
-	randomItem := items[time.Now().Unix()%int64(len(items))]
-
-	item := &models.Inventory{
-		UserID:   userID,
-		ItemName: randomItem,
-		Quantity: 1,
-		ItemType: models.ItemTypeTool,
+	stampDepleted(repos, obj)
+	drops, err := s.dropLoot(repos, userID, obj)
+	if err != nil {
+		return nil, err
 	}
-	s.inventoryRepo.AddItem(item)
 
 	return map[string]interface{}{
 		"action":       "chest_opened",
-		"items_gained": []string{randomItem + " x1"},
-	}
+		"items_gained": itemsGained(drops),
+		"drops":        drops,
+	}, nil
 }
 
 func (s *WorldService) accessServer(userID uuid.UUID, obj *models.WorldObject) map[string]interface{} {
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"code-valley-api/internal/config"
+	"code-valley-api/internal/models"
+	"code-valley-api/internal/repositories"
+	"code-valley-api/internal/repositories/memory"
+
+	"github.com/google/uuid"
+)
+
+// slowPositions delays returning positions read outside a unit of work, so
+// moves sent at once all read the position before any of them saves it.
+type slowPositions struct {
+	repositories.WorldRepository
+}
+
+func (r slowPositions) GetPlayerPosition(userID uuid.UUID) (*models.PlayerPosition, error) {
+	position, err := r.WorldRepository.GetPlayerPosition(userID)
+	time.Sleep(20 * time.Millisecond)
+	return position, err
+}
+
+func newTestWorldService(store *memory.Store, repos *repositories.Repositories) *WorldService {
+	cfg := &config.Config{
+		Movement:    config.MovementConfig{MaxTilesPerSecond: 4, BurstSeconds: 1},
+		Pathfinding: config.PathfindingConfig{Heuristic: "chebyshev", DiagonalCost: 1, MaxNodes: 1000, MaxRouteLength: 50},
+	}
+	return NewWorldService(cfg, repos.World, repos.Users, repos.Inventory, repos.Quests, repos.AntiCheat, store.UnitOfWork(), nil)
+}
+
+// addTestPlayer puts a new user at (x, y) on an open map of the given size.
+func addTestPlayer(t *testing.T, store *memory.Store, repos *repositories.Repositories, width, height, x, y int) (*models.User, models.Map) {
+	t.Helper()
+	town := store.AddMap(models.Map{Name: "town", Width: width, Height: height, IsActive: true})
+	user := addTestUser(t, repos, "walker", 0)
+	position := &models.PlayerPosition{UserID: user.ID, MapID: town.ID, PosX: x, PosY: y, Direction: "down"}
+	if err := repos.World.CreatePlayerPosition(position); err != nil {
+		t.Fatalf("creating position: %v", err)
+	}
+	return user, town
+}
+
+func TestMovePlayerSpendsTheBudgetOnceForConcurrentMoves(t *testing.T) {
+	store, repos := newTestStore()
+	service := newTestWorldService(store, repos)
+	service.worldRepo = slowPositions{repos.World}
+	user, _ := addTestPlayer(t, store, repos, 10, 10, 0, 0)
+
+	// Every target is a full budget of 4 tiles from the start and from
+	// each other, so only one of the moves can be afforded
+	targets := [][2]int{{4, 0}, {0, 4}, {4, 4}}
+	start := make(chan struct{})
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i, x, y int) {
+			defer wg.Done()
+			<-start
+			errs[i] = service.MovePlayer(user.ID, x, y, "right")
+		}(i, target[0], target[1])
+	}
+	close(start)
+	wg.Wait()
+
+	accepted := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			accepted++
+		case !errors.Is(err, ErrMoveTooFast):
+			t.Errorf("MovePlayer: %v, want ErrMoveTooFast", err)
+		}
+	}
+	if accepted != 1 {
+		t.Fatalf("accepted %d concurrent full-budget moves, want 1", accepted)
+	}
+}
+
+func TestTeleportPlayerIsAudited(t *testing.T) {
+	store, repos := newTestStore()
+	service := newTestWorldService(store, repos)
+	user, town := addTestPlayer(t, store, repos, 10, 10, 0, 0)
+	actor := Actor{UserID: user.ID, ClientInfo: ClientInfo{IP: "10.0.0.1"}}
+
+	position, err := service.TeleportPlayer(actor, TeleportRequest{MapName: town.Name, PosX: 5, PosY: 6})
+	if err != nil {
+		t.Fatalf("TeleportPlayer: %v", err)
+	}
+	if position.PosX != 5 || position.PosY != 6 {
+		t.Fatalf("teleported to (%d,%d), want (5,6)", position.PosX, position.PosY)
+	}
+
+	filter := repositories.AuditLogFilter{Action: models.AuditActionTeleport}
+	logs, total, err := repos.AuditLogs.Search(filter, defaultTestPage)
+	if err != nil || total != 1 {
+		t.Fatalf("teleport audit logs %+v, %v, want one", logs, err)
+	}
+	entry := logs[0]
+	if entry.ActorID == nil || *entry.ActorID != user.ID || entry.IPAddress != "10.0.0.1" {
+		t.Errorf("audit entry %+v, want the actor and their IP", entry)
+	}
+	if change := entry.Changes["pos_x"]; change.From != 0.0 || change.To != 5.0 {
+		t.Errorf("pos_x change %+v, want 0 to 5", change)
+	}
+
+	if _, err := service.TeleportPlayer(actor, TeleportRequest{MapName: town.Name, PosX: 50, PosY: 0}); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("TeleportPlayer off the map = %v, want ErrOutOfBounds", err)
+	}
+	if _, total, _ := repos.AuditLogs.Search(filter, defaultTestPage); total != 1 {
+		t.Errorf("%d teleport audit logs after a refused teleport, want 1", total)
+	}
+}
@@ -0,0 +1,269 @@
+package tiled
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"code-valley-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// How a Tiled map describes Code Valley data:
+//
+//   - Map properties name, type and description fill in the map; any other
+//     map property ends up in the layout's properties.
+//   - A tile layer named "collision", or with a bool property collision,
+//     becomes the walkable mask: every non-empty tile is blocked. Other tile
+//     layers are kept as layout layers.
+//   - Objects are told apart by their class (type in older Tiled versions):
+//     "spawn" is a spawn point named after the object, "zone" a zone whose
+//     type is its zone property, "npc" places the NPC named by its npc
+//     property or its name, "portal" a portal to the map and spawn point in
+//     its target_map and target_spawn properties, and a world object type
+//     such as "tree" or "chest" creates that object with the object's
+//     properties as state.
+//   - Portal requirements are the portal's min_level, quest_id, open_from
+//     and open_until properties.
+const (
+	CollisionLayer = "collision"
+	ClassSpawn     = "spawn"
+	ClassZone      = "zone"
+	ClassNPC       = "npc"
+	ClassPortal    = "portal"
+)
+
+// Import is a Tiled map converted to Code Valley data. Positions are in
+// tiles.
+type Import struct {
+	Name        string
+	Type        models.MapType
+	Description string
+	Width       int
+	Height      int
+	Layout      models.MapLayout
+	Objects     []models.WorldObject
+	NPCs        []NPCSpawn
+	// Warnings lists things in the file that were skipped
+	Warnings []string
+}
+
+// NPCSpawn places an NPC, identified by name, on the map.
+type NPCSpawn struct {
+	NPCName   string
+	PosX      int
+	PosY      int
+	Direction string
+}
+
+// Convert turns the map into Code Valley data, validating the layout.
+func (m *Map) Convert() (*Import, error) {
+	result := &Import{
+		Name:        m.Properties.String("name"),
+		Type:        models.MapType(m.Properties.String("type")),
+		Description: m.Properties.String("description"),
+		Width:       m.Width,
+		Height:      m.Height,
+	}
+	for name, value := range m.Properties {
+		if name != "name" && name != "type" && name != "description" {
+			if result.Layout.Properties == nil {
+				result.Layout.Properties = make(map[string]interface{})
+			}
+			result.Layout.Properties[name] = value
+		}
+	}
+
+	objectTypes := make(map[string]bool, len(models.ObjectTypes))
+	for _, objectType := range models.ObjectTypes {
+		objectTypes[string(objectType)] = true
+	}
+	occupied := make(map[string]bool)
+
+	for _, layer := range m.Layers {
+		switch layer.Type {
+		case TileLayer:
+			if strings.EqualFold(layer.Name, CollisionLayer) || layer.Properties.Bool(CollisionLayer) {
+				if result.Layout.Walkable != nil {
+					return nil, fmt.Errorf("more than one collision layer, found %q", layer.Name)
+				}
+				result.Layout.Walkable = m.walkableMask(layer.Tiles)
+				continue
+			}
+			result.Layout.Layers = append(result.Layout.Layers, models.TileLayer{Name: layer.Name, Tiles: tileIDs(layer.Tiles)})
+
+		case ObjectLayer:
+			for _, obj := range layer.Objects {
+				x, y := m.tileOf(obj)
+				if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+					return nil, fmt.Errorf("object %d (%s) is off the map", obj.ID, obj.Class)
+				}
+
+				switch {
+				case obj.Class == ClassSpawn:
+					name := obj.Name
+					if name == "" {
+						name = models.DefaultSpawn
+					}
+					result.Layout.SpawnPoints = append(result.Layout.SpawnPoints, models.SpawnPoint{Name: name, X: x, Y: y})
+
+				case obj.Class == ClassZone:
+					zone := m.zoneOf(obj)
+					if zone.Type == "" {
+						return nil, fmt.Errorf("zone object %d needs a zone property", obj.ID)
+					}
+					result.Layout.Zones = append(result.Layout.Zones, zone)
+
+				case obj.Class == ClassPortal:
+					portal, err := m.portalOf(obj)
+					if err != nil {
+						return nil, err
+					}
+					result.Layout.Portals = append(result.Layout.Portals, portal)
+
+				case obj.Class == ClassNPC:
+					name := obj.Properties.String("npc")
+					if name == "" {
+						name = obj.Name
+					}
+					if name == "" {
+						return nil, fmt.Errorf("npc object %d needs a name", obj.ID)
+					}
+					direction := obj.Properties.String("direction")
+					if direction == "" {
+						direction = "down"
+					}
+					result.NPCs = append(result.NPCs, NPCSpawn{NPCName: name, PosX: x, PosY: y, Direction: direction})
+
+				case objectTypes[obj.Class]:
+					key := fmt.Sprintf("%s@%d,%d", obj.Class, x, y)
+					if occupied[key] {
+						return nil, fmt.Errorf("two %s objects on tile (%d,%d)", obj.Class, x, y)
+					}
+					occupied[key] = true
+
+					state := make(models.ObjectState, len(obj.Properties))
+					for name, value := range obj.Properties {
+						state[name] = value
+					}
+					result.Objects = append(result.Objects, models.WorldObject{
+						ObjectType: models.ObjectType(obj.Class),
+						PosX:       x,
+						PosY:       y,
+						State:      state,
+						IsActive:   true,
+					})
+
+				default:
+					result.Warnings = append(result.Warnings,
+						fmt.Sprintf("skipped object %d %q with unknown class %q in layer %q", obj.ID, obj.Name, obj.Class, layer.Name))
+				}
+			}
+		}
+	}
+
+	if err := result.Layout.Validate(m.Width, m.Height); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Tiled keeps flip and rotation flags in the top bits of tile IDs.
+const tileIDMask = 0x0FFFFFFF
+
+func tileIDs(gids []uint32) []int {
+	tiles := make([]int, len(gids))
+	for i, gid := range gids {
+		tiles[i] = int(gid & tileIDMask)
+	}
+	return tiles
+}
+
+func (m *Map) walkableMask(gids []uint32) []string {
+	rows := make([]string, m.Height)
+	for y := range rows {
+		var row strings.Builder
+		for x := 0; x < m.Width; x++ {
+			if gids[y*m.Width+x]&tileIDMask != 0 {
+				row.WriteByte(models.TileBlocked)
+			} else {
+				row.WriteByte(models.TileOpen)
+			}
+		}
+		rows[y] = row.String()
+	}
+	return rows
+}
+
+// tileOf is the tile under an object's centre. Tile objects are anchored
+// at their bottom left corner, everything else at the top left.
+func (m *Map) tileOf(obj Object) (int, int) {
+	centreX := obj.X + obj.Width/2
+	centreY := obj.Y + obj.Height/2
+	if obj.GID != 0 {
+		centreY = obj.Y - obj.Height/2
+	}
+	return int(math.Floor(centreX / float64(m.TileWidth))), int(math.Floor(centreY / float64(m.TileHeight)))
+}
+
+// areaOf is every tile a rectangle object touches.
+func (m *Map) areaOf(obj Object) (x, y, width, height int) {
+	tileWidth, tileHeight := float64(m.TileWidth), float64(m.TileHeight)
+	x = int(math.Floor(obj.X / tileWidth))
+	y = int(math.Floor(obj.Y / tileHeight))
+	width = int(math.Max(1, math.Ceil((obj.X+obj.Width)/tileWidth)-float64(x)))
+	height = int(math.Max(1, math.Ceil((obj.Y+obj.Height)/tileHeight)-float64(y)))
+	return x, y, width, height
+}
+
+func (m *Map) zoneOf(obj Object) models.MapZone {
+	x, y, width, height := m.areaOf(obj)
+	zone := models.MapZone{
+		Name:   obj.Name,
+		Type:   models.ZoneType(obj.Properties.String("zone")),
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+	}
+	for name, value := range obj.Properties {
+		if name != "zone" {
+			if zone.Properties == nil {
+				zone.Properties = make(map[string]interface{})
+			}
+			zone.Properties[name] = value
+		}
+	}
+	return zone
+}
+
+func (m *Map) portalOf(obj Object) (models.Portal, error) {
+	x, y, width, height := m.areaOf(obj)
+	portal := models.Portal{
+		Name:        obj.Name,
+		X:           x,
+		Y:           y,
+		Width:       width,
+		Height:      height,
+		TargetMap:   obj.Properties.String("target_map"),
+		TargetSpawn: obj.Properties.String("target_spawn"),
+		Requirements: models.PortalRequirements{
+			MinLevel:  obj.Properties.Int("min_level"),
+			OpenFrom:  obj.Properties.Int("open_from"),
+			OpenUntil: obj.Properties.Int("open_until"),
+		},
+	}
+	if portal.Name == "" {
+		portal.Name = fmt.Sprintf("portal_%d", obj.ID)
+	}
+
+	if questID := obj.Properties.String("quest_id"); questID != "" {
+		id, err := uuid.Parse(questID)
+		if err != nil {
+			return portal, fmt.Errorf("portal object %d has an invalid quest_id", obj.ID)
+		}
+		portal.Requirements.QuestID = &id
+	}
+	return portal, nil
+}
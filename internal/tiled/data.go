@@ -0,0 +1,92 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// decodeTiles reads encoded tile layer data: csv, or base64 with optional
+// gzip or zlib compression.
+func decodeTiles(encoding, compression, payload string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		if compression != "" {
+			return nil, fmt.Errorf("%w: compressed csv", ErrUnsupported)
+		}
+		var tiles []uint32
+		for _, field := range strings.Split(payload, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tile %q", field)
+			}
+			tiles = append(tiles, uint32(gid))
+		}
+		return tiles, nil
+
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 tile data: %w", err)
+		}
+		if raw, err = decompress(compression, raw); err != nil {
+			return nil, err
+		}
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("tile data is %d bytes, not a multiple of 4", len(raw))
+		}
+		tiles := make([]uint32, len(raw)/4)
+		for i := range tiles {
+			tiles[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return tiles, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %q tile encoding", ErrUnsupported, encoding)
+	}
+}
+
+func decompress(compression string, raw []byte) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch compression {
+	case "":
+		return raw, nil
+	case "gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(raw))
+	case "zlib":
+		reader, err = zlib.NewReader(bytes.NewReader(raw))
+	default:
+		return nil, fmt.Errorf("%w: %s compression", ErrUnsupported, compression)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s tile data: %w", compression, err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// propertyValue converts a TMX property, which is always text, to its
+// declared type.
+func propertyValue(propertyType, value string) (interface{}, error) {
+	switch propertyType {
+	case "int":
+		return strconv.Atoi(value)
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,137 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type jsonMap struct {
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Orientation string         `json:"orientation"`
+	Infinite    bool           `json:"infinite"`
+	Properties  []jsonProperty `json:"properties"`
+	Layers      []jsonLayer    `json:"layers"`
+}
+
+type jsonLayer struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+	Properties  []jsonProperty  `json:"properties"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	GID        uint32         `json:"gid"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+func parseJSON(data []byte) (*Map, error) {
+	var raw jsonMap
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid Tiled JSON: %w", err)
+	}
+
+	m := &Map{
+		Width:       raw.Width,
+		Height:      raw.Height,
+		TileWidth:   raw.TileWidth,
+		TileHeight:  raw.TileHeight,
+		Orientation: raw.Orientation,
+		Infinite:    raw.Infinite,
+		Properties:  jsonProperties(raw.Properties),
+	}
+	if err := m.addJSONLayers("", raw.Layers); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Map) addJSONLayers(group string, layers []jsonLayer) error {
+	for _, raw := range layers {
+		name := joinName(group, raw.Name)
+		switch LayerType(raw.Type) {
+		case TileLayer:
+			tiles, err := jsonTiles(raw)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", name, err)
+			}
+			m.Layers = append(m.Layers, Layer{Name: name, Type: TileLayer, Tiles: tiles, Properties: jsonProperties(raw.Properties)})
+
+		case ObjectLayer:
+			layer := Layer{Name: name, Type: ObjectLayer, Properties: jsonProperties(raw.Properties)}
+			for _, obj := range raw.Objects {
+				class := obj.Class
+				if class == "" {
+					class = obj.Type
+				}
+				layer.Objects = append(layer.Objects, Object{
+					ID: obj.ID, Name: obj.Name, Class: class,
+					X: obj.X, Y: obj.Y, Width: obj.Width, Height: obj.Height,
+					GID: obj.GID, Properties: jsonProperties(obj.Properties),
+				})
+			}
+			m.Layers = append(m.Layers, layer)
+
+		case "group":
+			if err := m.addJSONLayers(name, raw.Layers); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonTiles reads layer data, which is an array of tile IDs unless the
+// layer is base64 encoded.
+func jsonTiles(layer jsonLayer) ([]uint32, error) {
+	if layer.Data == nil {
+		return nil, fmt.Errorf("%w: layer without data, e.g. chunks", ErrUnsupported)
+	}
+	if layer.Encoding == "base64" {
+		var payload string
+		if err := json.Unmarshal(layer.Data, &payload); err != nil {
+			return nil, fmt.Errorf("invalid base64 data: %w", err)
+		}
+		return decodeTiles(layer.Encoding, layer.Compression, payload)
+	}
+
+	var tiles []uint32
+	if err := json.Unmarshal(layer.Data, &tiles); err != nil {
+		return nil, fmt.Errorf("invalid tile data: %w", err)
+	}
+	return tiles, nil
+}
+
+func jsonProperties(raw []jsonProperty) Properties {
+	properties := make(Properties, len(raw))
+	for _, property := range raw {
+		value := property.Value
+		// JSON numbers decode as float64
+		if number, ok := value.(float64); ok && property.Type == "int" {
+			value = int(number)
+		}
+		properties[property.Name] = value
+	}
+	return properties
+}
@@ -0,0 +1,127 @@
+// Package tiled reads maps exported from the Tiled editor
+// (https://www.mapeditor.org) as JSON (.tmj/.json) or TMX and converts
+// them into Code Valley map layouts, world objects and NPC spawns.
+package tiled
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var ErrUnsupported = errors.New("unsupported tiled map")
+
+// Map is a Tiled map as read from either format.
+type Map struct {
+	Width       int
+	Height      int
+	TileWidth   int
+	TileHeight  int
+	Orientation string
+	Infinite    bool
+	Properties  Properties
+	Layers      []Layer
+}
+
+type LayerType string
+
+const (
+	TileLayer   LayerType = "tilelayer"
+	ObjectLayer LayerType = "objectgroup"
+)
+
+// Layer is a tile or object layer. Layers inside groups are flattened and
+// named group/layer.
+type Layer struct {
+	Name       string
+	Type       LayerType
+	Tiles      []uint32 // global tile IDs, row-major, including flip flags
+	Objects    []Object
+	Properties Properties
+}
+
+type Object struct {
+	ID     int
+	Name   string
+	Class  string // "type" before Tiled 1.9
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	// GID is set for tile objects, whose Y is their bottom edge
+	GID        uint32
+	Properties Properties
+}
+
+// Properties are custom properties with string, bool, int or float values.
+type Properties map[string]interface{}
+
+func (p Properties) String(name string) string {
+	value, _ := p[name].(string)
+	return value
+}
+
+func (p Properties) Bool(name string) bool {
+	value, _ := p[name].(bool)
+	return value
+}
+
+// Int reads a whole number property, which may have been stored as an int
+// or a float.
+func (p Properties) Int(name string) int {
+	switch value := p[name].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	default:
+		return 0
+	}
+}
+
+// Parse reads a map in either format, telling them apart by their first
+// character.
+func Parse(data []byte) (*Map, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("empty map file")
+	}
+
+	var m *Map
+	var err error
+	switch trimmed[0] {
+	case '{':
+		m, err = parseJSON(trimmed)
+	case '<':
+		m, err = parseTMX(trimmed)
+	default:
+		return nil, errors.New("not a Tiled JSON or TMX file")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Infinite {
+		return nil, fmt.Errorf("%w: infinite maps are not supported", ErrUnsupported)
+	}
+	if m.Orientation != "" && m.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("%w: %s orientation", ErrUnsupported, m.Orientation)
+	}
+	if m.Width < 1 || m.Height < 1 || m.TileWidth < 1 || m.TileHeight < 1 {
+		return nil, errors.New("map and tile sizes must be positive")
+	}
+	for _, layer := range m.Layers {
+		if layer.Type == TileLayer && len(layer.Tiles) != m.Width*m.Height {
+			return nil, fmt.Errorf("layer %q has %d tiles, expected %d", layer.Name, len(layer.Tiles), m.Width*m.Height)
+		}
+	}
+	return m, nil
+}
+
+// joinName names a layer inside a group.
+func joinName(group, name string) string {
+	if group == "" {
+		return name
+	}
+	return group + "/" + name
+}
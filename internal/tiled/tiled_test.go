@@ -0,0 +1,178 @@
+package tiled
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"code-valley-api/internal/models"
+)
+
+// groveImport is what both testdata/grove files convert to.
+var groveImport = &Import{
+	Name:        "grove",
+	Type:        models.MapTypeVillage,
+	Description: "A quiet grove",
+	Width:       4,
+	Height:      3,
+	Layout: models.MapLayout{
+		Layers:      []models.TileLayer{{Name: "ground", Tiles: []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}}},
+		Walkable:    []string{"...#", "...#", "...."},
+		SpawnPoints: []models.SpawnPoint{{Name: "default", X: 0, Y: 2}},
+		Zones:       []models.MapZone{{Name: "shop", Type: models.ZoneBuilding, X: 1, Y: 0, Width: 2, Height: 1}},
+		Portals: []models.Portal{{
+			Name: "exit", X: 3, Y: 2, Width: 1, Height: 1,
+			TargetMap: "village", TargetSpawn: "from_grove",
+			Requirements: models.PortalRequirements{MinLevel: 2},
+		}},
+		Properties: map[string]interface{}{"music": "birdsong"},
+	},
+	Objects: []models.WorldObject{
+		{ObjectType: models.ObjectTypeTree, PosX: 2, PosY: 1, IsActive: true,
+			State: models.ObjectState{"growth_stage": 3}},
+		{ObjectType: models.ObjectTypeChest, PosX: 0, PosY: 1, IsActive: true,
+			State: models.ObjectState{"contents": "Debug Tool", "is_looted": false}},
+	},
+	NPCs: []NPCSpawn{{NPCName: "Ada", PosX: 1, PosY: 2, Direction: "left"}},
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	return data
+}
+
+func TestParseAndConvertFixtures(t *testing.T) {
+	for _, name := range []string{"grove.tmj", "grove.tmx"} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := Parse(readFixture(t, name))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(parsed.Layers) != 3 || parsed.Layers[1].Name != "walls/blocked" {
+				t.Errorf("layers %+v, want ground, walls/blocked and objects", parsed.Layers)
+			}
+
+			imported, err := parsed.Convert()
+			if err != nil {
+				t.Fatalf("Convert: %v", err)
+			}
+			if len(imported.Warnings) != 1 || !strings.Contains(imported.Warnings[0], `"lamp"`) {
+				t.Errorf("warnings %q, want one about the lamp", imported.Warnings)
+			}
+			imported.Warnings = nil
+			if !reflect.DeepEqual(imported, groveImport) {
+				t.Errorf("converted to\n%+v\nwant\n%+v", imported, groveImport)
+			}
+		})
+	}
+}
+
+// testMap builds a small JSON map around the given layers.
+func testMap(extra, layers string) []byte {
+	return []byte(`{"width": 2, "height": 1, "tilewidth": 16, "tileheight": 16` + extra + `, "layers": [` + layers + `]}`)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", []byte("  "), nil},
+		{"not a map", []byte("tiles"), nil},
+		{"broken JSON", []byte(`{"width": `), nil},
+		{"broken TMX", []byte(`<map width="2"`), nil},
+		{"infinite", testMap(`, "infinite": true`, ""), ErrUnsupported},
+		{"isometric", testMap(`, "orientation": "isometric"`, ""), ErrUnsupported},
+		{"no size", []byte(`{"tilewidth": 16, "tileheight": 16}`), nil},
+		{"short layer", testMap("", `{"name": "ground", "type": "tilelayer", "data": [1]}`), nil},
+		{"chunks", testMap("", `{"name": "ground", "type": "tilelayer"}`), ErrUnsupported},
+		{"unknown encoding", []byte(`<map width="1" height="1" tilewidth="16" tileheight="16">` +
+			`<layer name="ground"><data encoding="hex">01</data></layer></map>`), ErrUnsupported},
+		{"bad property", []byte(`<map width="1" height="1" tilewidth="16" tileheight="16">` +
+			`<properties><property name="size" type="int" value="big"/></properties></map>`), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.data)
+			if err == nil {
+				t.Fatalf("Parse = %+v, want an error", m)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Parse error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers string
+	}{
+		{"two objects on a tile", `{"name": "objects", "type": "objectgroup", "objects": [
+			{"id": 1, "class": "rock", "x": 0, "y": 0, "width": 16, "height": 16},
+			{"id": 2, "class": "rock", "x": 2, "y": 2, "width": 8, "height": 8}]}`},
+		{"object off the map", `{"name": "objects", "type": "objectgroup", "objects": [
+			{"id": 1, "class": "rock", "x": 40, "y": 0, "width": 16, "height": 16}]}`},
+		{"zone without a type", `{"name": "objects", "type": "objectgroup", "objects": [
+			{"id": 1, "name": "field", "class": "zone", "x": 0, "y": 0, "width": 16, "height": 16}]}`},
+		{"portal with a bad quest", `{"name": "objects", "type": "objectgroup", "objects": [
+			{"id": 1, "class": "portal", "x": 0, "y": 0, "width": 16, "height": 16, "properties": [
+				{"name": "target_map", "type": "string", "value": "village"},
+				{"name": "quest_id", "type": "string", "value": "not-a-uuid"}]}]}`},
+		{"two collision layers", `{"name": "collision", "type": "tilelayer", "data": [0, 1]},
+			{"name": "walls", "type": "tilelayer", "data": [0, 1],
+			 "properties": [{"name": "collision", "type": "bool", "value": true}]}`},
+		{"spawn on a wall", `{"name": "collision", "type": "tilelayer", "data": [1, 0]},
+			{"name": "objects", "type": "objectgroup", "objects": [
+			{"id": 1, "class": "spawn", "x": 0, "y": 0, "width": 16, "height": 16}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := Parse(testMap("", tt.layers))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if imported, err := parsed.Convert(); err == nil {
+				t.Fatalf("Convert = %+v, want an error", imported)
+			}
+		})
+	}
+}
+
+func TestDecodeTiles(t *testing.T) {
+	want := []uint32{0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0, 0}
+
+	tests := []struct {
+		name                  string
+		encoding, compression string
+		payload               string
+	}{
+		{"csv", "csv", "", "0,0,0,2,\n0,0,0,2,\n0,0,0,0\n"},
+		{"base64", "base64", "", "AAAAAAAAAAAAAAAAAgAAAAAAAAAAAAAAAAAAAAIAAAAAAAAAAAAAAAAAAAAAAAAA"},
+		{"zlib", "base64", "zlib", " eJxjYEAAJgZUgM4HAQAAoAAF\n"},
+		{"gzip", "base64", "gzip", "H4sIAAAAAAAC/2NgQAAmBlSAzgcBAHJq1skwAAAA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiles, err := decodeTiles(tt.encoding, tt.compression, tt.payload)
+			if err != nil {
+				t.Fatalf("decodeTiles: %v", err)
+			}
+			if !reflect.DeepEqual(tiles, want) {
+				t.Errorf("tiles %v, want %v", tiles, want)
+			}
+		})
+	}
+
+	if _, err := decodeTiles("base64", "zstd", "AAAA"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("zstd: got %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,160 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+type tmxMap struct {
+	Width       int           `xml:"width,attr"`
+	Height      int           `xml:"height,attr"`
+	TileWidth   int           `xml:"tilewidth,attr"`
+	TileHeight  int           `xml:"tileheight,attr"`
+	Orientation string        `xml:"orientation,attr"`
+	Infinite    int           `xml:"infinite,attr"`
+	Properties  []tmxProperty `xml:"properties>property"`
+	// Layers, object groups and groups are interleaved and their order
+	// matters, so every child element is collected here
+	Children []tmxLayer `xml:",any"`
+}
+
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Data       tmxData       `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Children   []tmxLayer    `xml:",any"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+	Chunks []struct{} `xml:"chunk"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+	// Multi-line strings are stored as text instead of a value
+	Text string `xml:",chardata"`
+}
+
+func parseTMX(data []byte) (*Map, error) {
+	var raw tmxMap
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid TMX: %w", err)
+	}
+
+	properties, err := tmxProperties(raw.Properties)
+	if err != nil {
+		return nil, err
+	}
+	m := &Map{
+		Width:       raw.Width,
+		Height:      raw.Height,
+		TileWidth:   raw.TileWidth,
+		TileHeight:  raw.TileHeight,
+		Orientation: raw.Orientation,
+		Infinite:    raw.Infinite == 1,
+		Properties:  properties,
+	}
+	if err := m.addTMXLayers("", raw.Children); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Map) addTMXLayers(group string, children []tmxLayer) error {
+	for _, raw := range children {
+		name := joinName(group, raw.Name)
+		properties, err := tmxProperties(raw.Properties)
+		if err != nil {
+			return fmt.Errorf("layer %q: %w", name, err)
+		}
+
+		switch raw.XMLName.Local {
+		case "layer":
+			tiles, err := tmxTiles(raw.Data)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", name, err)
+			}
+			m.Layers = append(m.Layers, Layer{Name: name, Type: TileLayer, Tiles: tiles, Properties: properties})
+
+		case "objectgroup":
+			layer := Layer{Name: name, Type: ObjectLayer, Properties: properties}
+			for _, obj := range raw.Objects {
+				objProperties, err := tmxProperties(obj.Properties)
+				if err != nil {
+					return fmt.Errorf("object %d: %w", obj.ID, err)
+				}
+				class := obj.Class
+				if class == "" {
+					class = obj.Type
+				}
+				layer.Objects = append(layer.Objects, Object{
+					ID: obj.ID, Name: obj.Name, Class: class,
+					X: obj.X, Y: obj.Y, Width: obj.Width, Height: obj.Height,
+					GID: obj.GID, Properties: objProperties,
+				})
+			}
+			m.Layers = append(m.Layers, layer)
+
+		case "group":
+			if err := m.addTMXLayers(name, raw.Children); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func tmxTiles(data tmxData) ([]uint32, error) {
+	if len(data.Chunks) > 0 {
+		return nil, fmt.Errorf("%w: chunked layer data", ErrUnsupported)
+	}
+	if data.Encoding != "" {
+		return decodeTiles(data.Encoding, data.Compression, data.Text)
+	}
+
+	// Unencoded data lists every tile as an element
+	tiles := make([]uint32, len(data.Tiles))
+	for i, tile := range data.Tiles {
+		tiles[i] = tile.GID
+	}
+	return tiles, nil
+}
+
+func tmxProperties(raw []tmxProperty) (Properties, error) {
+	properties := make(Properties, len(raw))
+	for _, property := range raw {
+		text := property.Value
+		if text == "" {
+			text = property.Text
+		}
+		value, err := propertyValue(property.Type, text)
+		if err != nil {
+			return nil, fmt.Errorf("property %q is not a valid %s", property.Name, property.Type)
+		}
+		properties[property.Name] = value
+	}
+	return properties, nil
+}
@@ -0,0 +1,150 @@
+package websocket
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Backplane carries hub traffic between API nodes, so players connected to
+// different instances behind a load balancer still see each other. Every
+// node publishes deliveries and applies the ones it receives, its own
+// included, to its local connections.
+type Backplane interface {
+	// Publish sends a delivery to every node.
+	Publish(delivery Delivery) error
+	// Subscribe hands every published delivery to receive until Close.
+	Subscribe(receive func(Delivery)) error
+	// Presence tracks which users are connected anywhere in the cluster.
+	Presence() PresenceRegistry
+	Close() error
+}
+
+// PresenceRegistry tracks the users connected to any node and the map they
+// are on. A node joins a user when their first local connection opens and
+// leaves them when the last one closes.
+type PresenceRegistry interface {
+	// Join records the user on this node and reports whether no other
+	// node has them, i.e. they just came online.
+	Join(userID, mapID uuid.UUID) (bool, error)
+	// Leave removes the user from this node and reports whether no other
+	// node has them, i.e. they just went offline.
+	Leave(userID uuid.UUID) (bool, error)
+	// SetMap records the map a user's connections on this node moved to.
+	SetMap(userID, mapID uuid.UUID) error
+	OnlineUsers() ([]uuid.UUID, error)
+	MapUsers(mapID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type DeliveryKind string
+
+const (
+	DeliveryUser              DeliveryKind = "user"
+	DeliveryMap               DeliveryKind = "map"
+	DeliveryAll               DeliveryKind = "all"
+	DeliveryMoveUser          DeliveryKind = "move_user"
+	DeliveryDisconnectUser    DeliveryKind = "disconnect_user"
+	DeliveryDisconnectSession DeliveryKind = "disconnect_session"
+)
+
+// Delivery is one hub operation to apply on every node. Target is the
+// user, map or login session it applies to; MapID is the destination of
+// DeliveryMoveUser. Message data is already encoded, see prepare.
+type Delivery struct {
+	Kind    DeliveryKind `json:"kind"`
+	Target  uuid.UUID    `json:"target,omitempty"`
+	MapID   uuid.UUID    `json:"map_id,omitempty"`
+	Message Message      `json:"message"`
+}
+
+// localBackplane is the single-node backplane: deliveries are applied
+// directly and presence is kept in memory.
+type localBackplane struct {
+	mu       sync.RWMutex
+	receive  func(Delivery)
+	presence *localPresence
+}
+
+func NewLocalBackplane() Backplane {
+	return &localBackplane{presence: &localPresence{maps: make(map[uuid.UUID]uuid.UUID)}}
+}
+
+func (b *localBackplane) Publish(delivery Delivery) error {
+	b.mu.RLock()
+	receive := b.receive
+	b.mu.RUnlock()
+
+	if receive != nil {
+		receive(delivery)
+	}
+	return nil
+}
+
+func (b *localBackplane) Subscribe(receive func(Delivery)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.receive = receive
+	return nil
+}
+
+func (b *localBackplane) Presence() PresenceRegistry {
+	return b.presence
+}
+
+func (b *localBackplane) Close() error {
+	return nil
+}
+
+// localPresence maps each connected user to their map. With a single node
+// every join is a first and every leave a last.
+type localPresence struct {
+	mu   sync.RWMutex
+	maps map[uuid.UUID]uuid.UUID
+}
+
+func (p *localPresence) Join(userID, mapID uuid.UUID) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.maps[userID] = mapID
+	return true, nil
+}
+
+func (p *localPresence) Leave(userID uuid.UUID) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.maps, userID)
+	return true, nil
+}
+
+func (p *localPresence) SetMap(userID, mapID uuid.UUID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.maps[userID]; ok {
+		p.maps[userID] = mapID
+	}
+	return nil
+}
+
+func (p *localPresence) OnlineUsers() ([]uuid.UUID, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	users := make([]uuid.UUID, 0, len(p.maps))
+	for userID := range p.maps {
+		users = append(users, userID)
+	}
+	return users, nil
+}
+
+func (p *localPresence) MapUsers(mapID uuid.UUID) ([]uuid.UUID, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var users []uuid.UUID
+	for userID, userMap := range p.maps {
+		if userMap == mapID {
+			users = append(users, userID)
+		}
+	}
+	return users, nil
+}
@@ -15,22 +15,40 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 4096 // room for a full-length chat message
+
+	// sendHeadroom is send buffer space beyond a full replay, so a resumed
+	// connection can take new events while its backlog is written
+	sendHeadroom = 64
 )
 
 type Client struct {
-	hub    *Hub
-	conn   *websocket.Conn
-	send   chan []byte
-	UserID uuid.UUID
+	hub       *Hub
+	conn      *websocket.Conn
+	send      chan []byte
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+
+	// mapID is the map the connection starts on; the session tracks the
+	// room afterwards
+	mapID uuid.UUID
+
+	// resumeID and lastSeq name the stream a reconnecting client asks to
+	// resume and the last event it received
+	resumeID uuid.UUID
+	lastSeq  uint64
+
+	// session is the client's event stream; guarded by hub.mutex
+	session *session
 }
 
-func NewClient(hub *Hub, conn *websocket.Conn, userID uuid.UUID) *Client {
+func NewClient(hub *Hub, conn *websocket.Conn, userID, sessionID uuid.UUID) *Client {
 	return &Client{
-		hub:    hub,
-		conn:   conn,
-		send:   make(chan []byte, 256),
-		UserID: userID,
+		hub:       hub,
+		conn:      conn,
+		send:      make(chan []byte, hub.replaySize+sendHeadroom),
+		UserID:    userID,
+		SessionID: sessionID,
 	}
 }
 
@@ -48,8 +66,7 @@ func (c *Client) ReadPump() {
 	})
 
 	for {
-		var msg Message
-		err := c.conn.ReadJSON(&msg)
+		_, raw, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
@@ -58,7 +75,7 @@ func (c *Client) ReadPump() {
 		}
 
 		// Handle incoming messages
-		c.handleMessage(msg)
+		c.handleMessage(raw)
 	}
 }
 
@@ -78,16 +95,22 @@ func (c *Client) WritePump() {
 				return
 			}
 
+			// Events lost to a failed write are replayed when the client
+			// resumes, so a write error just ends the connection
 			w, err := c.conn.NextWriter(websocket.TextMessage)
 			if err != nil {
 				return
 			}
-			w.Write(message)
+			if _, err := w.Write(message); err != nil {
+				return
+			}
 
 			n := len(c.send)
 			for i := 0; i < n; i++ {
 				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
+				if _, err := w.Write(<-c.send); err != nil {
+					return
+				}
 			}
 
 			if err := w.Close(); err != nil {
@@ -103,52 +126,26 @@ func (c *Client) WritePump() {
 	}
 }
 
-func (c *Client) handleMessage(msg Message) {
-	switch msg.Type {
-	case "ping":
-		response := Message{
-			Type: "pong",
-			Data: map[string]interface{}{
-				"timestamp": time.Now().Unix(),
-			},
-		}
-		data, _ := json.Marshal(response)
-		select {
-		case c.send <- data:
-		default:
-			close(c.send)
-		}
-
-	case "player_move":
-		// Handle player movement
-		if moveData, ok := msg.Data.(map[string]interface{}); ok {
-			posX := int(moveData["pos_x"].(float64))
-			posY := int(moveData["pos_y"].(float64))
-			direction := moveData["direction"].(string)
-			
-			// Broadcast movement to hub for processing
-			c.hub.HandlePlayerMove(c.UserID, posX, posY, direction)
-		}
-
-	case "player_interact":
-		// Handle player interaction
-		if interactData, ok := msg.Data.(map[string]interface{}); ok {
-			targetX := int(interactData["target_x"].(float64))
-			targetY := int(interactData["target_y"].(float64))
-			
-			// Broadcast interaction to hub for processing
-			c.hub.HandlePlayerInteract(c.UserID, targetX, targetY)
-		}
-
-	case "chat":
-		// Handle chat messages
-		log.Printf("Chat message from %s: %v", c.UserID, msg.Data)
-
-	case "quest_update":
-		// Handle quest updates
-		log.Printf("Quest update from %s: %v", c.UserID, msg.Data)
+// handleMessage decodes a client frame and runs its registered handler,
+// replying with an ack or a structured error.
+func (c *Client) handleMessage(raw []byte) {
+	var env Envelope
+	if err := json.Unmarshal(raw, &env); err != nil || env.Type == "" {
+		c.hub.sendTo(c, *errorReply(env.RequestID, NewError(ErrCodeBadRequest, "malformed message")))
+		return
+	}
+	if len(env.RequestID) > maxRequestIDLength {
+		c.hub.sendTo(c, *errorReply("", NewError(ErrCodeBadRequest, "request_id is too long")))
+		return
+	}
 
-	default:
-		log.Printf("Unknown message type: %s", msg.Type)
+	ctx := &Context{
+		UserID:    c.UserID,
+		SessionID: c.SessionID,
+		RequestID: env.RequestID,
+		client:    c,
+	}
+	if reply := c.hub.handlers.dispatch(ctx, env); reply != nil {
+		c.hub.sendTo(c, *reply)
 	}
 }
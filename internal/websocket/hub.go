@@ -1,273 +1,701 @@
 package websocket
 
 import (
-	"encoding/json"
 	"log"
 	"sync"
+	"time"
+
+	"code-valley-api/internal/config"
 
 	"github.com/google/uuid"
 )
 
+// sweepInterval is how often detached sessions past their grace window
+// are dropped.
+const sweepInterval = 10 * time.Second
+
 type Hub struct {
 	clients    map[*Client]bool
-	userClients map[uuid.UUID]*Client
-	broadcast  chan []byte
+	userClients map[uuid.UUID]map[*Client]bool // every open connection per user
+	broadcast  chan Message
 	register   chan *Client
 	unregister chan *Client
 	mutex      sync.RWMutex
-	
-	// Channels for handling game events
-	playerMoveChannel chan PlayerMoveEvent
-	playerInteractChannel chan PlayerInteractEvent
-}
 
-type PlayerMoveEvent struct {
-	UserID    uuid.UUID
-	PosX      int
-	PosY      int
-	Direction string
-}
+	// sessions are the event streams of live connections and of dropped
+	// ones still within their grace window, guarded by mutex like clients
+	sessions     map[uuid.UUID]*session
+	userSessions map[uuid.UUID]map[*session]bool
+	mapRooms     map[uuid.UUID]map[*session]bool
 
-type PlayerInteractEvent struct {
-	UserID  uuid.UUID
-	TargetX int
-	TargetY int
-}
+	replaySize  int
+	resumeGrace time.Duration
+
+	// backplane shares deliveries and presence with other nodes
+	backplane Backplane
 
-type MapClients struct {
-	clients map[uuid.UUID]map[*Client]bool
-	mutex   sync.RWMutex
+	// handlers dispatches client messages by type
+	handlers *Registry
+
+	// presenceChannel reports users coming online and going offline
+	presenceChannel chan PresenceEvent
+
+	// joined holds the users this node has joined in the presence
+	// registry, guarded by mutex. Registry calls for a user are made under
+	// their presenceLocks entry, see syncPresence.
+	joined        map[uuid.UUID]bool
+	presenceLocks *userLocks
 }
 
-var mapClients = &MapClients{
-	clients: make(map[uuid.UUID]map[*Client]bool),
+// PresenceEvent reports a user's first connection opening or last
+// connection closing.
+type PresenceEvent struct {
+	UserID uuid.UUID
+	Online bool
 }
 
+// Message is a frame sent by the server: a pushed event, or an ack or
+// error reply carrying the client's request ID.
 type Message struct {
-	Type    string      `json:"type"`
-	UserID  uuid.UUID   `json:"user_id,omitempty"`
-	Data    interface{} `json:"data"`
-	Target  string      `json:"target,omitempty"` // "all", "user", "friends"
+	V         int         `json:"v"`
+	Seq       uint64      `json:"seq,omitempty"` // set on events, see session
+	Type      string      `json:"type"`
+	RequestID string      `json:"request_id,omitempty"`
+	UserID    uuid.UUID   `json:"user_id,omitempty"`
+	Data      interface{} `json:"data"`
+	Error     *Error      `json:"error,omitempty"`
+	Target    string      `json:"target,omitempty"` // "all", "user", "friends"
 }
 
-type PlayerMoveMessage struct {
-	Type      string `json:"type"`
-	PosX      int    `json:"pos_x"`
-	PosY      int    `json:"pos_y"`
-	Direction string `json:"direction"`
-}
+func NewHub(cfg config.WebSocketConfig, backplane Backplane) *Hub {
+	if cfg.ReplayBuffer < 0 {
+		cfg.ReplayBuffer = 0
+	}
+
+	h := &Hub{
+		clients:         make(map[*Client]bool),
+		userClients:     make(map[uuid.UUID]map[*Client]bool),
+		broadcast:       make(chan Message, 256),
+		register:        make(chan *Client),
+		unregister:      make(chan *Client),
+		sessions:        make(map[uuid.UUID]*session),
+		userSessions:    make(map[uuid.UUID]map[*session]bool),
+		mapRooms:        make(map[uuid.UUID]map[*session]bool),
+		replaySize:      cfg.ReplayBuffer,
+		resumeGrace:     time.Duration(cfg.ResumeGraceSeconds) * time.Second,
+		backplane:       backplane,
+		handlers:        NewRegistry(),
+		presenceChannel: make(chan PresenceEvent, 256),
+		joined:          make(map[uuid.UUID]bool),
+		presenceLocks:   newUserLocks(),
+	}
 
-type PlayerInteractMessage struct {
-	Type    string    `json:"type"`
-	TargetX int       `json:"target_x"`
-	TargetY int       `json:"target_y"`
-	TargetID *uuid.UUID `json:"target_id,omitempty"`
+	Handle(h.handlers, "ping", "Keep the connection alive.", func(ctx *Context, req Empty) (Pong, error) {
+		return Pong{Timestamp: time.Now().Unix()}, nil
+	})
+	Handle(h.handlers, "session_ack", "Acknowledge events up to seq so they are no longer kept for resuming.",
+		func(ctx *Context, req SessionAck) (Empty, error) {
+			h.mutex.Lock()
+			if sess := ctx.client.session; sess != nil {
+				sess.ack(req.Seq)
+			}
+			h.mutex.Unlock()
+			return Empty{}, nil
+		})
+	h.handlers.Event("session", "Sent first on every connection; tells whether the stream was resumed.", SessionInfo{})
+	return h
 }
 
-func NewHub() *Hub {
-	return &Hub{
-		clients:               make(map[*Client]bool),
-		userClients:           make(map[uuid.UUID]*Client),
-		broadcast:             make(chan []byte, 256),
-		register:              make(chan *Client),
-		unregister:            make(chan *Client),
-		playerMoveChannel:     make(chan PlayerMoveEvent, 256),
-		playerInteractChannel: make(chan PlayerInteractEvent, 256),
-	}
+// Handlers returns the registry services add their message handlers to.
+func (h *Hub) Handlers() *Registry {
+	return h.handlers
 }
 
 func (h *Hub) Run() {
+	sweep := time.NewTicker(sweepInterval)
+	defer sweep.Stop()
+
 	for {
 		select {
 		case client := <-h.register:
 			h.mutex.Lock()
-			h.clients[client] = true
-			h.userClients[client.UserID] = client
+			first := h.addClient(client)
+			resumed := h.attach(client)
 			h.mutex.Unlock()
 			
-			log.Printf("Client connected: %s", client.UserID)
+			if resumed {
+				log.Printf("Client resumed: %s", client.UserID)
+			} else {
+				log.Printf("Client connected: %s", client.UserID)
+			}
 			
-			// Notify friends that user is online
-			h.notifyFriendsOnlineStatus(client.UserID, true)
+			// Only the first connection anywhere brings the user online
+			if first {
+				h.syncPresence(client.UserID)
+			}
 
 		case client := <-h.unregister:
 			h.mutex.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				delete(h.userClients, client.UserID)
-				close(client.send)
-			}
+			last := h.removeClient(client)
 			h.mutex.Unlock()
 			
 			log.Printf("Client disconnected: %s", client.UserID)
 			
-			// Notify friends that user is offline
-			h.notifyFriendsOnlineStatus(client.UserID, false)
+			if last {
+				h.syncPresence(client.UserID)
+			}
 
 		case message := <-h.broadcast:
-			h.mutex.RLock()
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-					delete(h.userClients, client.UserID)
+			var offline []uuid.UUID
+			h.mutex.Lock()
+			for _, sess := range h.sessions {
+				if h.deliver(sess, message) {
+					offline = append(offline, sess.userID)
 				}
 			}
-			h.mutex.RUnlock()
+			h.mutex.Unlock()
+			h.syncPresence(offline...)
+
+		case now := <-sweep.C:
+			h.mutex.Lock()
+			for _, sess := range h.sessions {
+				if sess.expired(now, h.resumeGrace) {
+					h.dropSession(sess)
+				}
+			}
+			h.mutex.Unlock()
 		}
 	}
 }
 
-func (h *Hub) SendToUser(userID uuid.UUID, message Message) {
-	h.mutex.RLock()
-	client, exists := h.userClients[userID]
-	h.mutex.RUnlock()
-	
-	if exists {
-		data, err := json.Marshal(message)
-		if err != nil {
-			log.Printf("Error marshaling message: %v", err)
-			return
+// attach binds a new connection to the session it asked to resume, or to
+// a fresh one, and queues the session info followed by any missed events.
+// It reports whether the session was resumed. The caller must hold
+// h.mutex.
+func (h *Hub) attach(client *Client) bool {
+	sess, replay := h.resumable(client)
+	resumed := sess != nil
+	if !resumed {
+		sess = newSession(client.UserID, client.SessionID, h.replaySize)
+		h.sessions[sess.id] = sess
+		if h.userSessions[sess.userID] == nil {
+			h.userSessions[sess.userID] = make(map[*session]bool)
 		}
-		
+		h.userSessions[sess.userID][sess] = true
+	}
+
+	// A half-open connection on the same stream is replaced
+	if sess.client != nil {
+		h.removeClient(sess.client)
+	}
+	sess.client = client
+	client.session = sess
+	if client.mapID != uuid.Nil {
+		h.joinRoom(sess, client.mapID)
+	}
+
+	info, err := encode(Message{Type: "session", UserID: client.UserID, Data: SessionInfo{
+		SessionID:          sess.id,
+		Resumed:            resumed,
+		Seq:                sess.seq,
+		ResumeGraceSeconds: int(h.resumeGrace / time.Second),
+	}})
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return resumed
+	}
+	// The send buffer is sized to hold a full replay
+	client.send <- info
+	for _, data := range replay {
+		client.send <- data
+	}
+	return resumed
+}
+
+// resumable finds the session a connection asked to resume and the frames
+// it missed. It returns nil when there is nothing to resume: the session
+// is unknown, expired, belongs to another login, or has already evicted
+// frames the client never received. The caller must hold h.mutex.
+func (h *Hub) resumable(client *Client) (*session, [][]byte) {
+	if client.resumeID == uuid.Nil {
+		return nil, nil
+	}
+	sess := h.sessions[client.resumeID]
+	if sess == nil || sess.userID != client.UserID || sess.authSessionID != client.SessionID ||
+		sess.expired(time.Now(), h.resumeGrace) {
+		return nil, nil
+	}
+
+	replay, ok := sess.since(client.lastSeq)
+	if !ok {
+		log.Printf("Client %s cannot resume session %s from seq %d", client.UserID, sess.id, client.lastSeq)
+		h.dropSession(sess)
+		return nil, nil
+	}
+	return sess, replay
+}
+
+// deliver sequences an event on a session and writes it to the session's
+// connection, if any. A connection that cannot keep up is closed rather
+// than losing the event; the client can resume and get it from the replay
+// buffer. It reports whether that was the user's last connection. The
+// caller must hold h.mutex.
+func (h *Hub) deliver(sess *session, message Message) bool {
+	data, err := sess.push(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return false
+	}
+	if sess.client == nil {
+		return false
+	}
+
+	select {
+	case sess.client.send <- data:
+		return false
+	default:
+		log.Printf("Client %s is too slow, closing connection for resume", sess.userID)
+		return h.removeClient(sess.client)
+	}
+}
+
+// sendTo delivers a message to one connection, dropping the connection if
+// it cannot keep up.
+func (h *Hub) sendTo(client *Client, message Message) {
+	data, err := encode(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	var offline []uuid.UUID
+	h.mutex.Lock()
+	// A removed client's send channel is already closed
+	if h.clients[client] {
 		select {
 		case client.send <- data:
 		default:
-			h.mutex.Lock()
-			close(client.send)
-			delete(h.clients, client)
-			delete(h.userClients, userID)
-			h.mutex.Unlock()
+			if h.removeClient(client) {
+				offline = append(offline, client.UserID)
+			}
 		}
 	}
+	h.mutex.Unlock()
+	h.syncPresence(offline...)
+}
+
+// SendToUser delivers a message to every session of the user on every
+// node, including dropped ones that can still be resumed.
+func (h *Hub) SendToUser(userID uuid.UUID, message Message) {
+	h.publish(Delivery{Kind: DeliveryUser, Target: userID, Message: message})
 }
 
 func (h *Hub) SendToAll(message Message) {
-	data, err := json.Marshal(message)
+	h.publish(Delivery{Kind: DeliveryAll, Message: message})
+}
+
+// BroadcastToMap sends a message to every session in the map's room on
+// every node.
+func (h *Hub) BroadcastToMap(mapID uuid.UUID, message Message) {
+	h.publish(Delivery{Kind: DeliveryMap, Target: mapID, Message: message})
+}
+
+// MoveUserToMap switches every session of the user to the map's room,
+// e.g. after a teleport.
+func (h *Hub) MoveUserToMap(userID, mapID uuid.UUID) {
+	h.publish(Delivery{Kind: DeliveryMoveUser, Target: userID, MapID: mapID})
+}
+
+// DisconnectSession closes every connection opened with the given login
+// session and forgets their streams so they cannot be resumed.
+func (h *Hub) DisconnectSession(sessionID uuid.UUID) {
+	h.publish(Delivery{Kind: DeliveryDisconnectSession, Target: sessionID})
+}
+
+// DisconnectUser sends notice to every connection of the user and then
+// closes them, e.g. when the account is banned.
+func (h *Hub) DisconnectUser(userID uuid.UUID, notice Message) {
+	h.publish(Delivery{Kind: DeliveryDisconnectUser, Target: userID, Message: notice})
+}
+
+// publish encodes a delivery's message data once and hands it to the
+// backplane, which brings it back to receive on every node.
+func (h *Hub) publish(delivery Delivery) {
+	message, err := prepare(delivery.Message)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
-	
-	select {
-	case h.broadcast <- data:
+	delivery.Message = message
+
+	if err := h.backplane.Publish(delivery); err != nil {
+		log.Printf("Backplane publish of %s failed: %v", delivery.Kind, err)
+	}
+}
+
+// receive applies a delivery from any node to the local connections.
+func (h *Hub) receive(delivery Delivery) {
+	switch delivery.Kind {
+	case DeliveryUser:
+		h.sendToUser(delivery.Target, delivery.Message)
+	case DeliveryAll:
+		select {
+		case h.broadcast <- delivery.Message:
+		default:
+			log.Println("Broadcast channel is full")
+		}
+	case DeliveryMap:
+		h.broadcastToMap(delivery.Target, delivery.Message)
+	case DeliveryMoveUser:
+		h.moveUserToMap(delivery.Target, delivery.MapID)
+	case DeliveryDisconnectSession:
+		h.disconnectSession(delivery.Target)
+	case DeliveryDisconnectUser:
+		h.disconnectUser(delivery.Target, delivery.Message)
 	default:
-		log.Println("Broadcast channel is full")
+		log.Printf("Backplane: unknown delivery kind %q", delivery.Kind)
+	}
+}
+
+func (h *Hub) sendToUser(userID uuid.UUID, message Message) {
+	var offline []uuid.UUID
+	h.mutex.Lock()
+	for sess := range h.userSessions[userID] {
+		if h.deliver(sess, message) {
+			offline = append(offline, userID)
+		}
 	}
+	h.mutex.Unlock()
+	h.syncPresence(offline...)
 }
 
+// disconnectSession drops the local streams of a login session. The read
+// pumps then unregister the clients as usual.
+func (h *Hub) disconnectSession(sessionID uuid.UUID) {
+	var offline []uuid.UUID
+	h.mutex.Lock()
+	for _, sess := range h.sessions {
+		if sess.authSessionID == sessionID {
+			if h.dropSession(sess) {
+				offline = append(offline, sess.userID)
+			}
+			log.Printf("Client %s dropped: session %s revoked", sess.userID, sessionID)
+		}
+	}
+	h.mutex.Unlock()
+	h.syncPresence(offline...)
+}
+
+func (h *Hub) disconnectUser(userID uuid.UUID, notice Message) {
+	data, err := encode(notice)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	var offline []uuid.UUID
+	h.mutex.Lock()
+	for sess := range h.userSessions[userID] {
+		// Queued messages are still written before the pump sees the close.
+		if sess.client != nil {
+			select {
+			case sess.client.send <- data:
+			default:
+			}
+		}
+		if h.dropSession(sess) {
+			offline = append(offline, userID)
+		}
+		log.Printf("Client %s dropped: %s", userID, notice.Type)
+	}
+	h.mutex.Unlock()
+	h.syncPresence(offline...)
+}
+
+// GetOnlineUsers lists users connected to any node. If the presence
+// registry is unreachable only this node's users are listed.
 func (h *Hub) GetOnlineUsers() []uuid.UUID {
+	users, err := h.backplane.Presence().OnlineUsers()
+	if err == nil {
+		return users
+	}
+	log.Printf("Presence registry unavailable, listing local users: %v", err)
+
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
 	
-	users := make([]uuid.UUID, 0, len(h.userClients))
+	users = make([]uuid.UUID, 0, len(h.userClients))
 	for userID := range h.userClients {
 		users = append(users, userID)
 	}
 	return users
 }
 
-func (h *Hub) IsUserOnline(userID uuid.UUID) bool {
+// MapUsers lists users connected on the map on any node, falling back to
+// this node's room if the presence registry is unreachable.
+func (h *Hub) MapUsers(mapID uuid.UUID) []uuid.UUID {
+	users, err := h.backplane.Presence().MapUsers(mapID)
+	if err == nil {
+		return users
+	}
+	log.Printf("Presence registry unavailable, listing local map users: %v", err)
+
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
-	
-	_, exists := h.userClients[userID]
-	return exists
+
+	seen := make(map[uuid.UUID]bool)
+	users = make([]uuid.UUID, 0, len(h.mapRooms[mapID]))
+	for sess := range h.mapRooms[mapID] {
+		if sess.client != nil && !seen[sess.userID] {
+			seen[sess.userID] = true
+			users = append(users, sess.userID)
+		}
+	}
+	return users
 }
 
-func (h *Hub) AddClientToMap(client *Client, mapID uuid.UUID) {
-	mapClients.mutex.Lock()
-	defer mapClients.mutex.Unlock()
-	
-	if mapClients.clients[mapID] == nil {
-		mapClients.clients[mapID] = make(map[*Client]bool)
+func (h *Hub) IsUserOnline(userID uuid.UUID) bool {
+	for _, online := range h.GetOnlineUsers() {
+		if online == userID {
+			return true
+		}
 	}
-	mapClients.clients[mapID][client] = true
+	return false
 }
 
-func (h *Hub) RemoveClientFromMap(client *Client, mapID uuid.UUID) {
-	mapClients.mutex.Lock()
-	defer mapClients.mutex.Unlock()
-	
-	if mapClients.clients[mapID] != nil {
-		delete(mapClients.clients[mapID], client)
-		if len(mapClients.clients[mapID]) == 0 {
-			delete(mapClients.clients, mapID)
+// addClient tracks a new connection and reports whether it is the user's
+// first. The caller must hold h.mutex.
+func (h *Hub) addClient(client *Client) bool {
+	h.clients[client] = true
+	connections := h.userClients[client.UserID]
+	if connections == nil {
+		connections = make(map[*Client]bool)
+		h.userClients[client.UserID] = connections
+	}
+	connections[client] = true
+	return len(connections) == 1
+}
+
+// removeClient forgets a client and closes its send channel, reporting
+// whether it was the user's last connection. Its session is detached and
+// kept for the grace window. It is safe to call more than once for the same
+// client. The caller must hold h.mutex.
+func (h *Hub) removeClient(client *Client) bool {
+	if _, ok := h.clients[client]; !ok {
+		return false
+	}
+	delete(h.clients, client)
+	close(client.send)
+
+	if sess := client.session; sess != nil && sess.client == client {
+		sess.client = nil
+		sess.detachedAt = time.Now()
+	}
+
+	connections := h.userClients[client.UserID]
+	delete(connections, client)
+	if len(connections) > 0 {
+		return false
+	}
+	delete(h.userClients, client.UserID)
+	return true
+}
+
+// dropSession closes a session's connection, if any, and forgets the
+// session so it cannot be resumed. It reports whether that closed the
+// user's last connection. The caller must hold h.mutex.
+func (h *Hub) dropSession(sess *session) bool {
+	last := false
+	if sess.client != nil {
+		last = h.removeClient(sess.client)
+	}
+	h.leaveRoom(sess)
+	delete(h.sessions, sess.id)
+	if sessions := h.userSessions[sess.userID]; sessions != nil {
+		delete(sessions, sess)
+		if len(sessions) == 0 {
+			delete(h.userSessions, sess.userID)
 		}
 	}
+	return last
 }
 
-func BroadcastToMap(mapID uuid.UUID, message Message) {
-	mapClients.mutex.RLock()
-	clients := mapClients.clients[mapID]
-	mapClients.mutex.RUnlock()
-	
-	if clients == nil {
-		return
+// syncPresence brings the presence registry in line with whether each
+// user still has connections on this node, and publishes users coming
+// online or going offline. Call it after adding a user's first local
+// connection or removing their last, once h.mutex is released.
+//
+// Connections come and go from several goroutines, so a user's registry
+// calls are made one at a time under their lock and always apply the
+// current state: a leave for a closed connection that runs after a
+// reconnect sees the user connected again and does nothing.
+func (h *Hub) syncPresence(userIDs ...uuid.UUID) {
+	for _, userID := range userIDs {
+		h.syncUserPresence(userID)
 	}
-	
-	data, err := json.Marshal(message)
-	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
-		return
+}
+
+func (h *Hub) syncUserPresence(userID uuid.UUID) {
+	unlock := h.presenceLocks.lock(userID)
+	defer unlock()
+
+	h.mutex.RLock()
+	mapID, connected := h.localMap(userID)
+	joined := h.joined[userID]
+	h.mutex.RUnlock()
+
+	switch {
+	case connected && !joined:
+		first, err := h.backplane.Presence().Join(userID, mapID)
+		if err != nil {
+			log.Printf("Presence registry join of %s failed: %v", userID, err)
+			first = true
+		}
+		h.setJoined(userID, true)
+		if first {
+			h.publishPresence(PresenceEvent{UserID: userID, Online: true})
+		}
+
+	case !connected && joined:
+		last, err := h.backplane.Presence().Leave(userID)
+		if err != nil {
+			log.Printf("Presence registry leave of %s failed: %v", userID, err)
+			last = true
+		}
+		h.setJoined(userID, false)
+		if last {
+			h.publishPresence(PresenceEvent{UserID: userID})
+		}
 	}
-	
-	for client := range clients {
-		select {
-		case client.send <- data:
-		default:
-			// Client channel is full, remove it
-			close(client.send)
-			delete(clients, client)
+}
+
+// localMap reports whether the user has a connection on this node and the
+// map it is on. The caller must hold h.mutex.
+func (h *Hub) localMap(userID uuid.UUID) (uuid.UUID, bool) {
+	for client := range h.userClients[userID] {
+		if client.session != nil && client.session.mapID != uuid.Nil {
+			return client.session.mapID, true
 		}
+		return client.mapID, true
 	}
+	return uuid.Nil, false
 }
 
-func (h *Hub) notifyFriendsOnlineStatus(userID uuid.UUID, isOnline bool) {
-	// This would typically query the database for friends
-	// For now, we'll just broadcast to all users
-	message := Message{
-		Type: "user_status",
-		Data: map[string]interface{}{
-			"user_id":   userID,
-			"is_online": isOnline,
-		},
+func (h *Hub) setJoined(userID uuid.UUID, joined bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if joined {
+		h.joined[userID] = true
+	} else {
+		delete(h.joined, userID)
 	}
-	
-	h.SendToAll(message)
 }
 
-func (h *Hub) HandlePlayerMove(userID uuid.UUID, posX, posY int, direction string) {
-	select {
-	case h.playerMoveChannel <- PlayerMoveEvent{
-		UserID:    userID,
-		PosX:      posX,
-		PosY:      posY,
-		Direction: direction,
-	}:
-	default:
-		log.Println("Player move channel is full")
+// userLocks hands out one mutex per user, kept only while someone holds
+// or waits for it.
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*userLock
+}
+
+type userLock struct {
+	sync.Mutex
+	holders int
+}
+
+func newUserLocks() *userLocks {
+	return &userLocks{locks: make(map[uuid.UUID]*userLock)}
+}
+
+// lock locks the user's mutex and returns the function that unlocks it.
+func (l *userLocks) lock(userID uuid.UUID) func() {
+	l.mu.Lock()
+	lock := l.locks[userID]
+	if lock == nil {
+		lock = &userLock{}
+		l.locks[userID] = lock
+	}
+	lock.holders++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		l.mu.Lock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(l.locks, userID)
+		}
+		l.mu.Unlock()
 	}
 }
 
-func (h *Hub) HandlePlayerInteract(userID uuid.UUID, targetX, targetY int) {
-	select {
-	case h.playerInteractChannel <- PlayerInteractEvent{
-		UserID:  userID,
-		TargetX: targetX,
-		TargetY: targetY,
-	}:
-	default:
-		log.Println("Player interact channel is full")
+// joinRoom moves a session into a map room, leaving its previous one. The
+// caller must hold h.mutex.
+func (h *Hub) joinRoom(sess *session, mapID uuid.UUID) {
+	h.leaveRoom(sess)
+	if h.mapRooms[mapID] == nil {
+		h.mapRooms[mapID] = make(map[*session]bool)
+	}
+	h.mapRooms[mapID][sess] = true
+	sess.mapID = mapID
+}
+
+// leaveRoom removes a session from its map room, if any. The caller must
+// hold h.mutex.
+func (h *Hub) leaveRoom(sess *session) {
+	room := h.mapRooms[sess.mapID]
+	if room == nil {
+		return
+	}
+	delete(room, sess)
+	if len(room) == 0 {
+		delete(h.mapRooms, sess.mapID)
+	}
+}
+
+func (h *Hub) moveUserToMap(userID, mapID uuid.UUID) {
+	h.mutex.Lock()
+	for sess := range h.userSessions[userID] {
+		if sess.mapID != mapID {
+			h.joinRoom(sess, mapID)
+		}
+	}
+	connected := len(h.userClients[userID]) > 0
+	h.mutex.Unlock()
+
+	if connected {
+		if err := h.backplane.Presence().SetMap(userID, mapID); err != nil {
+			log.Printf("Presence registry map update of %s failed: %v", userID, err)
+		}
+	}
+}
+
+func (h *Hub) broadcastToMap(mapID uuid.UUID, message Message) {
+	var offline []uuid.UUID
+	h.mutex.Lock()
+	for sess := range h.mapRooms[mapID] {
+		if h.deliver(sess, message) {
+			offline = append(offline, sess.userID)
+		}
 	}
+	h.mutex.Unlock()
+	h.syncPresence(offline...)
 }
 
-func (h *Hub) GetPlayerMoveChannel() <-chan PlayerMoveEvent {
-	return h.playerMoveChannel
+// publishPresence hands a presence change to the presence service.
+// Presence changes must not be lost, so a full channel falls back
+// to a goroutine instead of dropping the event.
+func (h *Hub) publishPresence(event PresenceEvent) {
+	select {
+	case h.presenceChannel <- event:
+	default:
+		log.Println("Presence channel is full")
+		go func() { h.presenceChannel <- event }()
+	}
 }
 
-func (h *Hub) GetPlayerInteractChannel() <-chan PlayerInteractEvent {
-	return h.playerInteractChannel
+func (h *Hub) GetPresenceChannel() <-chan PresenceEvent {
+	return h.presenceChannel
 }
\ No newline at end of file
@@ -0,0 +1,151 @@
+package websocket
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"code-valley-api/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// slowLeaveBackplane delays presence leaves, like a registry on a slow
+// network, so a reconnect can run while the leave is still in flight.
+type slowLeaveBackplane struct {
+	Backplane
+}
+
+func (b slowLeaveBackplane) Presence() PresenceRegistry {
+	return slowLeavePresence{b.Backplane.Presence()}
+}
+
+type slowLeavePresence struct {
+	PresenceRegistry
+}
+
+func (p slowLeavePresence) Leave(userID uuid.UUID) (bool, error) {
+	time.Sleep(50 * time.Millisecond)
+	return p.PresenceRegistry.Leave(userID)
+}
+
+func newTestHub(t *testing.T, backplane Backplane) *Hub {
+	t.Helper()
+	h := NewHub(config.WebSocketConfig{ReplayBuffer: 4}, backplane)
+	if err := backplane.Subscribe(h.receive); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	go h.Run()
+	return h
+}
+
+func newTestClient(h *Hub, userID uuid.UUID, buffer int) *Client {
+	return &Client{hub: h, send: make(chan []byte, buffer), UserID: userID}
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func nextPresence(t *testing.T, h *Hub) PresenceEvent {
+	t.Helper()
+	select {
+	case event := <-h.GetPresenceChannel():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a presence event")
+		return PresenceEvent{}
+	}
+}
+
+func (h *Hub) hasClient(client *Client) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.clients[client]
+}
+
+func registryHas(h *Hub, userID uuid.UUID) bool {
+	users, _ := h.backplane.Presence().OnlineUsers()
+	for _, online := range users {
+		if online == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHubKeepsUserOnlineWhenReconnectingDuringLeave(t *testing.T) {
+	h := newTestHub(t, slowLeaveBackplane{NewLocalBackplane()})
+	userID := uuid.New()
+
+	// The session frame fills the buffer, so the next send finds the
+	// connection too slow and closes it
+	old := newTestClient(h, userID, 1)
+	h.register <- old
+	waitFor(t, "the first connection", func() bool { return registryHas(h, userID) })
+
+	var closing sync.WaitGroup
+	closing.Add(1)
+	go func() {
+		defer closing.Done()
+		h.sendTo(old, Message{Type: "chat_message"})
+	}()
+	waitFor(t, "the slow connection to close", func() bool { return !h.hasClient(old) })
+
+	// Reconnect while the leave for the closed connection is in flight
+	reconnect := newTestClient(h, userID, 16)
+	h.register <- reconnect
+	closing.Wait()
+
+	waitFor(t, "the reconnected user in the presence registry", func() bool { return registryHas(h, userID) })
+
+	// The user may flicker offline, but the last word must be online
+	var last PresenceEvent
+	waitFor(t, "presence events", func() bool {
+		for {
+			select {
+			case last = <-h.GetPresenceChannel():
+			default:
+				return last.Online
+			}
+		}
+	})
+	if !registryHas(h, userID) {
+		t.Fatal("reconnected user left the presence registry")
+	}
+}
+
+func TestHubPresenceForFirstAndLastConnection(t *testing.T) {
+	h := newTestHub(t, NewLocalBackplane())
+	userID := uuid.New()
+	first, second := newTestClient(h, userID, 16), newTestClient(h, userID, 16)
+
+	h.register <- first
+	h.register <- second
+	waitFor(t, "both connections", func() bool { return h.hasClient(first) && h.hasClient(second) })
+	if event := nextPresence(t, h); event.UserID != userID || !event.Online {
+		t.Fatalf("got %+v, want the user coming online", event)
+	}
+
+	h.unregister <- first
+	h.unregister <- second
+	if event := nextPresence(t, h); event.UserID != userID || event.Online {
+		t.Fatalf("got %+v, want the user going offline", event)
+	}
+	select {
+	case event := <-h.GetPresenceChannel():
+		t.Fatalf("got %+v, want one event each way", event)
+	default:
+	}
+	if registryHas(h, userID) {
+		t.Fatal("user still in the presence registry after their last connection closed")
+	}
+}
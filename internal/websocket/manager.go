@@ -1,7 +1,9 @@
 package websocket
 
 import (
+	"fmt"
 	"log"
+	"strconv"
 
 	"code-valley-api/internal/config"
 	"code-valley-api/internal/utils"
@@ -13,13 +15,51 @@ import (
 
 var GlobalHub *Hub
 
-func InitializeWebSocket() {
-	GlobalHub = NewHub()
+// InitializeWebSocket starts the global hub on the configured backplane.
+func InitializeWebSocket(cfg *config.Config) error {
+	backplane, err := newBackplane(cfg.WebSocket)
+	if err != nil {
+		return err
+	}
+
+	GlobalHub = NewHub(cfg.WebSocket, backplane)
+	if err := backplane.Subscribe(GlobalHub.receive); err != nil {
+		backplane.Close()
+		return err
+	}
 	go GlobalHub.Run()
-	log.Println("WebSocket hub initialized")
+	log.Printf("WebSocket hub initialized with %s backplane", cfg.WebSocket.Backplane)
+	return nil
+}
+
+// ShutdownWebSocket leaves the backplane so other nodes stop counting this
+// node's users as online.
+func ShutdownWebSocket() {
+	if GlobalHub != nil {
+		if err := GlobalHub.backplane.Close(); err != nil {
+			log.Printf("Backplane close failed: %v", err)
+		}
+	}
+}
+
+func newBackplane(cfg config.WebSocketConfig) (Backplane, error) {
+	switch cfg.Backplane {
+	case "", "local":
+		return NewLocalBackplane(), nil
+	case "redis":
+		return NewRedisBackplane(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown websocket backplane %q", cfg.Backplane)
+	}
+}
+
+// MapLocator finds the map a player is currently on, so their connection
+// can join that map's room.
+type MapLocator interface {
+	CurrentMapID(userID uuid.UUID) (uuid.UUID, error)
 }
 
-func WebSocketUpgrade(cfg *config.Config) fiber.Handler {
+func WebSocketUpgrade(cfg *config.Config, sessions utils.SessionValidator, maps MapLocator) fiber.Handler {
 	return websocket.New(func(c *websocket.Conn) {
 		// Get user from query params or headers
 		token := c.Query("token")
@@ -35,7 +75,23 @@ func WebSocketUpgrade(cfg *config.Config) fiber.Handler {
 			return
 		}
 
-		client := NewClient(GlobalHub, c, claims.UserID)
+		if err := sessions.ValidateSession(claims); err != nil {
+			log.Printf("WebSocket session rejected for user %s: %v", claims.UserID, err)
+			c.Close()
+			return
+		}
+
+		client := NewClient(GlobalHub, c, claims.UserID, claims.SessionID)
+		// A reconnecting client names the stream to resume and the last
+		// event it got; anything unparsable starts a fresh stream
+		if resumeID, err := uuid.Parse(c.Query("resume")); err == nil {
+			client.resumeID = resumeID
+			client.lastSeq, _ = strconv.ParseUint(c.Query("last_seq"), 10, 64)
+		}
+		// Players without a position yet join a room once they get one
+		if mapID, err := maps.CurrentMapID(claims.UserID); err == nil {
+			client.mapID = mapID
+		}
 		GlobalHub.register <- client
 
 		go client.WritePump()
@@ -43,6 +99,62 @@ func WebSocketUpgrade(cfg *config.Config) fiber.Handler {
 	})
 }
 
+// DisconnectSession drops any live connection bound to a revoked session.
+func DisconnectSession(sessionID uuid.UUID) {
+	if GlobalHub != nil {
+		GlobalHub.DisconnectSession(sessionID)
+	}
+}
+
+// DisconnectUser kicks every live connection of a user, telling the client
+// why first.
+func DisconnectUser(userID uuid.UUID, msgType string, data interface{}) {
+	if GlobalHub != nil {
+		GlobalHub.DisconnectUser(userID, Message{
+			Type:   msgType,
+			UserID: userID,
+			Data:   data,
+		})
+	}
+}
+
+// SendToUser sends a message to every connection of a user.
+func SendToUser(userID uuid.UUID, message Message) {
+	if GlobalHub != nil {
+		GlobalHub.SendToUser(userID, message)
+	}
+}
+
+// BroadcastToMap sends a message to everyone currently on the map.
+func BroadcastToMap(mapID uuid.UUID, message Message) {
+	if GlobalHub != nil {
+		GlobalHub.BroadcastToMap(mapID, message)
+	}
+}
+
+// MoveUserToMap moves a user's connections to another map's room.
+func MoveUserToMap(userID, mapID uuid.UUID) {
+	if GlobalHub != nil {
+		GlobalHub.MoveUserToMap(userID, mapID)
+	}
+}
+
+// OnlineUsers lists users with at least one live connection.
+func OnlineUsers() []uuid.UUID {
+	if GlobalHub == nil {
+		return nil
+	}
+	return GlobalHub.GetOnlineUsers()
+}
+
+// MapUsers lists users currently connected on the map.
+func MapUsers(mapID uuid.UUID) []uuid.UUID {
+	if GlobalHub == nil {
+		return nil
+	}
+	return GlobalHub.MapUsers(mapID)
+}
+
 // Utility functions for sending real-time updates
 func NotifyQuestUpdate(userID uuid.UUID, questData interface{}) {
 	if GlobalHub != nil {
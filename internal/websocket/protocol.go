@@ -0,0 +1,111 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+
+	"code-valley-api/internal/utils"
+)
+
+// ProtocolVersion is the version of the message envelope. Clients that
+// omit the version are treated as speaking the current one.
+const ProtocolVersion = 1
+
+// maxRequestIDLength bounds the client-chosen ID echoed in replies.
+const maxRequestIDLength = 64
+
+// Envelope is a frame sent by the client. Data is decoded by the handler
+// registered for Type.
+type Envelope struct {
+	V         int             `json:"v"`
+	Type      string          `json:"type" validate:"required"`
+	RequestID string          `json:"request_id,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// Reply types sent in answer to a client frame.
+const (
+	ReplyAck   = "ack"
+	ReplyError = "error"
+)
+
+type ErrorCode string
+
+const (
+	ErrCodeBadRequest         ErrorCode = "bad_request"
+	ErrCodeUnsupportedVersion ErrorCode = "unsupported_version"
+	ErrCodeUnknownType        ErrorCode = "unknown_type"
+	ErrCodeValidation         ErrorCode = "validation_failed"
+	ErrCodeRejected           ErrorCode = "rejected"
+	ErrCodeForbidden          ErrorCode = "forbidden"
+	ErrCodeNotFound           ErrorCode = "not_found"
+	ErrCodeRateLimited        ErrorCode = "rate_limited"
+	ErrCodeInternal           ErrorCode = "internal_error"
+)
+
+// ErrorCodes lists every code a client may receive.
+var ErrorCodes = []ErrorCode{
+	ErrCodeBadRequest,
+	ErrCodeUnsupportedVersion,
+	ErrCodeUnknownType,
+	ErrCodeValidation,
+	ErrCodeRejected,
+	ErrCodeForbidden,
+	ErrCodeNotFound,
+	ErrCodeRateLimited,
+	ErrCodeInternal,
+}
+
+// Error is a structured error sent in an error reply. Fields holds
+// per-field messages for validation failures.
+type Error struct {
+	Code    ErrorCode         `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// toError turns a handler error into the error sent to the client.
+// Validation errors keep their per-field messages; other plain errors are
+// reported as rejected with their message.
+func toError(err error) *Error {
+	var protocolErr *Error
+	if errors.As(err, &protocolErr) {
+		return protocolErr
+	}
+	if fields := utils.FormatValidationErrors(err); len(fields) > 0 {
+		return &Error{Code: ErrCodeValidation, Message: "Validation failed", Fields: fields}
+	}
+	return NewError(ErrCodeRejected, err.Error())
+}
+
+// Empty is the payload of messages that carry no data.
+type Empty struct{}
+
+type Pong struct {
+	Timestamp int64 `json:"timestamp"`
+}
+
+// prepare encodes a message's data once, so fanning it out to many
+// sessions only serialises the small envelope each time.
+func prepare(message Message) (Message, error) {
+	data, err := json.Marshal(message.Data)
+	if err != nil {
+		return message, err
+	}
+	message.Data = json.RawMessage(data)
+	return message, nil
+}
+
+// encode stamps a message with the protocol version and serialises it.
+func encode(message Message) ([]byte, error) {
+	message.V = ProtocolVersion
+	return json.Marshal(message)
+}
@@ -0,0 +1,350 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisChannel = "codevalley:hub"
+	// Presence keys share the {presence} hash tag so they land in one slot
+	// and the scripts below can touch several of them on Redis Cluster
+	redisNodes      = "codevalley:{presence}:nodes" // sorted set of node IDs by last heartbeat
+	redisNodePrefix = "codevalley:{presence}:node:" // hash per node of user ID to map ID
+	redisTimeout    = 5 * time.Second
+
+	// A node that misses heartbeats for nodeTTL is considered gone and its
+	// users no longer count as online
+	heartbeatInterval = 10 * time.Second
+	nodeTTL           = 3 * heartbeatInterval
+
+	// presenceAttempts bounds how often Join and Leave retry when a node
+	// comes up between listing the live nodes and running the script
+	presenceAttempts = 3
+)
+
+// liveOthersScript is shared by the presence scripts. It returns the
+// declared hash keys of the live nodes other than this one, or nil if a live
+// node was not declared in KEYS, which the scripts report as -1 so the
+// caller lists the nodes again and retries.
+//
+// KEYS[1] is the node set, KEYS[2] this node's hash and KEYS[3..] the
+// hashes of the other nodes the caller saw live. ARGV[1] is the oldest live
+// heartbeat, ARGV[2] the node key prefix and ARGV[3] the user ID.
+const liveOthersScript = `
+local function liveOthers()
+	local declared = {}
+	for i = 3, #KEYS do
+		declared[KEYS[i]] = true
+	end
+	local others = {}
+	for _, node in ipairs(redis.call('ZRANGEBYSCORE', KEYS[1], ARGV[1], '+inf')) do
+		local key = ARGV[2] .. node
+		if key ~= KEYS[2] then
+			if not declared[key] then
+				return nil
+			end
+			table.insert(others, key)
+		end
+	end
+	return others
+end
+`
+
+// joinScript records a user on a node with the map in ARGV[4] and returns 1
+// if no other live node has them. Running it as a script keeps two nodes
+// joining the same user at once from both deciding the user was already
+// online.
+var joinScript = redis.NewScript(liveOthersScript + `
+local others = liveOthers()
+if not others then
+	return -1
+end
+local first = 1
+for _, key in ipairs(others) do
+	if redis.call('HEXISTS', key, ARGV[3]) == 1 then
+		first = 0
+	end
+end
+redis.call('HSET', KEYS[2], ARGV[3], ARGV[4])
+return first
+`)
+
+// leaveScript removes a user from a node and returns 1 if no other live
+// node has them.
+var leaveScript = redis.NewScript(liveOthersScript + `
+local others = liveOthers()
+if not others then
+	return -1
+end
+redis.call('HDEL', KEYS[2], ARGV[3])
+for _, key in ipairs(others) do
+	if redis.call('HEXISTS', key, ARGV[3]) == 1 then
+		return 0
+	end
+end
+return 1
+`)
+
+// redisBackplane shares deliveries between nodes over Redis pub/sub and
+// keeps cluster presence in one hash per node, expired when the node stops
+// sending heartbeats.
+type redisBackplane struct {
+	client *redis.Client
+	nodeID string
+	pubsub *redis.PubSub
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewRedisBackplane connects to the Redis server at url, e.g.
+// redis://localhost:6379/0, and registers this process as a node.
+func NewRedisBackplane(url string) (Backplane, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	b := &redisBackplane{
+		client: redis.NewClient(options),
+		nodeID: uuid.New().String(),
+		done:   make(chan struct{}),
+	}
+	if err := b.heartbeat(); err != nil {
+		b.client.Close()
+		return nil, fmt.Errorf("redis backplane: %w", err)
+	}
+	go b.runHeartbeat()
+
+	log.Printf("Redis backplane connected as node %s", b.nodeID)
+	return b, nil
+}
+
+// redisDelivery decodes a delivery keeping the message data encoded, so it
+// is passed on to sessions untouched.
+type redisDelivery struct {
+	Delivery
+	Message struct {
+		Message
+		Data json.RawMessage `json:"data"`
+	} `json:"message"`
+}
+
+func (b *redisBackplane) Publish(delivery Delivery) error {
+	payload, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return b.client.Publish(ctx, redisChannel, payload).Err()
+}
+
+func (b *redisBackplane) Subscribe(receive func(Delivery)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	b.pubsub = b.client.Subscribe(context.Background(), redisChannel)
+	// Wait for the subscription so nothing published after this returns is
+	// missed
+	if _, err := b.pubsub.Receive(ctx); err != nil {
+		b.pubsub.Close()
+		return fmt.Errorf("redis backplane: %w", err)
+	}
+
+	go func() {
+		for msg := range b.pubsub.Channel() {
+			var wire redisDelivery
+			if err := json.Unmarshal([]byte(msg.Payload), &wire); err != nil {
+				log.Printf("Backplane: dropping malformed delivery: %v", err)
+				continue
+			}
+
+			delivery := wire.Delivery
+			delivery.Message = wire.Message.Message
+			delivery.Message.Data = wire.Message.Data
+			receive(delivery)
+		}
+	}()
+	return nil
+}
+
+func (b *redisBackplane) Presence() PresenceRegistry {
+	return b
+}
+
+// Close stops the heartbeat and removes this node, so its users stop
+// counting as online right away.
+func (b *redisBackplane) Close() error {
+	b.once.Do(func() { close(b.done) })
+	if b.pubsub != nil {
+		b.pubsub.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	b.client.ZRem(ctx, redisNodes, b.nodeID)
+	b.client.Del(ctx, nodeKey(b.nodeID))
+	return b.client.Close()
+}
+
+func (b *redisBackplane) runHeartbeat() {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.heartbeat(); err != nil {
+				log.Printf("Backplane heartbeat failed: %v", err)
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// heartbeat marks this node alive, keeps its presence hash from expiring
+// and forgets nodes that stopped beating.
+func (b *redisBackplane) heartbeat() error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	now := time.Now()
+	pipe := b.client.TxPipeline()
+	pipe.ZAdd(ctx, redisNodes, redis.Z{Score: float64(now.Unix()), Member: b.nodeID})
+	pipe.Expire(ctx, nodeKey(b.nodeID), nodeTTL)
+	pipe.ZRemRangeByScore(ctx, redisNodes, "-inf", "("+liveSince(now))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// liveSince is the oldest heartbeat score of a node still considered live.
+func liveSince(now time.Time) string {
+	return strconv.FormatInt(now.Add(-nodeTTL).Unix(), 10)
+}
+
+// nodeKey is the presence hash of a node.
+func nodeKey(nodeID string) string {
+	return redisNodePrefix + nodeID
+}
+
+// liveNodes lists the nodes whose last heartbeat is no older than since.
+func (b *redisBackplane) liveNodes(ctx context.Context, since string) ([]string, error) {
+	return b.client.ZRangeByScore(ctx, redisNodes, &redis.ZRangeBy{Min: since, Max: "+inf"}).Result()
+}
+
+// runPresenceScript runs a presence script for a user with every live
+// node's hash declared in KEYS, and reports whether it returned 1.
+func (b *redisBackplane) runPresenceScript(ctx context.Context, script *redis.Script, userID uuid.UUID, args ...interface{}) (bool, error) {
+	for attempt := 0; attempt < presenceAttempts; attempt++ {
+		since := liveSince(time.Now())
+		nodes, err := b.liveNodes(ctx, since)
+		if err != nil {
+			return false, err
+		}
+
+		keys := []string{redisNodes, nodeKey(b.nodeID)}
+		for _, node := range nodes {
+			if node != b.nodeID {
+				keys = append(keys, nodeKey(node))
+			}
+		}
+		argv := append([]interface{}{since, redisNodePrefix, userID.String()}, args...)
+
+		result, err := script.Run(ctx, b.client, keys, argv...).Int()
+		if err != nil {
+			return false, err
+		}
+		if result >= 0 {
+			return result == 1, nil
+		}
+	}
+	return false, errors.New("redis backplane: live nodes kept changing")
+}
+
+func (b *redisBackplane) Join(userID, mapID uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	first, err := b.runPresenceScript(ctx, joinScript, userID, mapID.String())
+	if err != nil {
+		return false, err
+	}
+	b.client.Expire(ctx, nodeKey(b.nodeID), nodeTTL)
+	return first, nil
+}
+
+func (b *redisBackplane) Leave(userID uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	return b.runPresenceScript(ctx, leaveScript, userID)
+}
+
+func (b *redisBackplane) SetMap(userID, mapID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	key := nodeKey(b.nodeID)
+	exists, err := b.client.HExists(ctx, key, userID.String()).Result()
+	if err != nil || !exists {
+		return err
+	}
+	return b.client.HSet(ctx, key, userID.String(), mapID.String()).Err()
+}
+
+func (b *redisBackplane) OnlineUsers() ([]uuid.UUID, error) {
+	return b.users(func(uuid.UUID) bool { return true })
+}
+
+func (b *redisBackplane) MapUsers(mapID uuid.UUID) ([]uuid.UUID, error) {
+	return b.users(func(userMap uuid.UUID) bool { return userMap == mapID })
+}
+
+// users lists users on any live node whose map matches.
+func (b *redisBackplane) users(match func(mapID uuid.UUID) bool) ([]uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	nodes, err := b.liveNodes(ctx, liveSince(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := b.client.Pipeline()
+	hashes := make([]*redis.MapStringStringCmd, len(nodes))
+	for i, node := range nodes {
+		hashes[i] = pipe.HGetAll(ctx, nodeKey(node))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var users []uuid.UUID
+	for _, hash := range hashes {
+		for rawUser, rawMap := range hash.Val() {
+			userID, err := uuid.Parse(rawUser)
+			if err != nil || seen[userID] {
+				continue
+			}
+			mapID, _ := uuid.Parse(rawMap)
+			if match(mapID) {
+				seen[userID] = true
+				users = append(users, userID)
+			}
+		}
+	}
+	return users, nil
+}
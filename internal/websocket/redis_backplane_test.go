@@ -0,0 +1,174 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+)
+
+// newTestNode registers a backplane node on the miniredis server.
+func newTestNode(t *testing.T, mr *miniredis.Miniredis) *redisBackplane {
+	t.Helper()
+	backplane, err := NewRedisBackplane("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("NewRedisBackplane: %v", err)
+	}
+	t.Cleanup(func() { backplane.Close() })
+	return backplane.(*redisBackplane)
+}
+
+func TestRedisPresenceJoinAndLeave(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, b := newTestNode(t, mr), newTestNode(t, mr)
+	user, mapID := uuid.New(), uuid.New()
+
+	steps := []struct {
+		name string
+		node *redisBackplane
+		join bool
+		want bool
+	}{
+		{"first join", a, true, true},
+		{"join on a second node", b, true, false},
+		{"join again on the same node", a, true, false},
+		{"leave with another node left", a, false, false},
+		{"leave the last node", b, false, true},
+		{"join after leaving everywhere", b, true, true},
+	}
+	for _, step := range steps {
+		var got bool
+		var err error
+		if step.join {
+			got, err = step.node.Join(user, mapID)
+		} else {
+			got, err = step.node.Leave(user)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: got %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestRedisPresenceListsUsersOnLiveNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, b := newTestNode(t, mr), newTestNode(t, mr)
+	town, mine := uuid.New(), uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+
+	if _, err := a.Join(alice, town); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if _, err := b.Join(bob, town); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if err := b.SetMap(bob, mine); err != nil {
+		t.Fatalf("SetMap: %v", err)
+	}
+
+	online, err := a.OnlineUsers()
+	if err != nil || len(online) != 2 {
+		t.Fatalf("OnlineUsers = %v, %v, want both users", online, err)
+	}
+	inMine, err := a.MapUsers(mine)
+	if err != nil || len(inMine) != 1 || inMine[0] != bob {
+		t.Fatalf("MapUsers = %v, %v, want only bob", inMine, err)
+	}
+}
+
+func TestRedisPresenceForgetsNodesThatStopBeating(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, b := newTestNode(t, mr), newTestNode(t, mr)
+	user, mapID := uuid.New(), uuid.New()
+
+	if _, err := b.Join(user, mapID); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+
+	// b's last heartbeat is now older than nodeTTL
+	stale := time.Now().Add(-nodeTTL - time.Second)
+	if _, err := mr.ZAdd(redisNodes, float64(stale.Unix()), b.nodeID); err != nil {
+		t.Fatalf("ZAdd: %v", err)
+	}
+
+	if online, err := a.OnlineUsers(); err != nil || len(online) != 0 {
+		t.Fatalf("OnlineUsers = %v, %v, want nobody on a stale node", online, err)
+	}
+	if first, err := a.Join(user, mapID); err != nil || !first {
+		t.Fatalf("Join = %v, %v, want first while the other node is stale", first, err)
+	}
+	if last, err := a.Leave(user); err != nil || !last {
+		t.Fatalf("Leave = %v, %v, want last while the other node is stale", last, err)
+	}
+
+	// The next heartbeat drops the stale node, and its hash expires
+	if err := a.heartbeat(); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if members, err := mr.ZMembers(redisNodes); err != nil || len(members) != 1 || members[0] != a.nodeID {
+		t.Errorf("live nodes %v, %v, want only %s", members, err, a.nodeID)
+	}
+	mr.FastForward(nodeTTL + time.Second)
+	if mr.Exists(nodeKey(b.nodeID)) {
+		t.Error("stale node's presence hash did not expire")
+	}
+}
+
+func TestRedisPresenceScriptRejectsUndeclaredLiveNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, b := newTestNode(t, mr), newTestNode(t, mr)
+
+	// b is live but left out of KEYS, as if it came up after a listed the
+	// live nodes
+	result, err := joinScript.Run(context.Background(), a.client,
+		[]string{redisNodes, nodeKey(a.nodeID)},
+		liveSince(time.Now()), redisNodePrefix, uuid.New().String(), uuid.New().String()).Int()
+	if err != nil {
+		t.Fatalf("joinScript: %v", err)
+	}
+	if result != -1 {
+		t.Fatalf("joinScript = %d, want -1 with %s undeclared", result, b.nodeID)
+	}
+	if mr.Exists(nodeKey(a.nodeID)) {
+		t.Error("rejected join still recorded the user")
+	}
+}
+
+func TestRedisBackplaneDeliversBetweenNodes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, b := newTestNode(t, mr), newTestNode(t, mr)
+
+	received := make(chan Delivery, 1)
+	if err := a.Subscribe(func(d Delivery) { received <- d }); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	user := uuid.New()
+	sent := Delivery{
+		Kind:    DeliveryUser,
+		Target:  user,
+		Message: Message{V: 1, Type: "chat_message", Data: map[string]string{"text": "hello"}},
+	}
+	if err := b.Publish(sent); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got.Kind != DeliveryUser || got.Target != user || got.Message.Type != "chat_message" {
+			t.Fatalf("received %+v, want the published delivery", got)
+		}
+		data, ok := got.Message.Data.(json.RawMessage)
+		if !ok || string(data) != `{"text":"hello"}` {
+			t.Fatalf("received data %v, want it passed on still encoded", got.Message.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("delivery was not received")
+	}
+}
@@ -0,0 +1,134 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	"code-valley-api/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Context describes the connection and request a message arrived with.
+type Context struct {
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+	RequestID string
+
+	client *Client
+}
+
+// HandlerFunc handles the raw payload of one message type. Its result is
+// sent back as the data of the ack.
+type HandlerFunc func(ctx *Context, data json.RawMessage) (interface{}, error)
+
+type handlerEntry struct {
+	description string
+	request     reflect.Type
+	response    reflect.Type
+	handle      HandlerFunc
+}
+
+type eventEntry struct {
+	description string
+	payload     reflect.Type
+}
+
+// Registry maps client message types to their handlers and documents the
+// events the server pushes, so both can be published as a schema.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]handlerEntry
+	events   map[string]eventEntry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		handlers: make(map[string]handlerEntry),
+		events:   make(map[string]eventEntry),
+	}
+}
+
+// Handle registers fn for msgType. The payload is decoded into Req and
+// validated before fn runs, and the Resp it returns becomes the ack's data.
+// Registering a type again replaces the earlier handler.
+func Handle[Req, Resp any](r *Registry, msgType, description string, fn func(ctx *Context, req Req) (Resp, error)) {
+	requestType := reflect.TypeOf((*Req)(nil)).Elem()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[msgType] = handlerEntry{
+		description: description,
+		request:     requestType,
+		response:    reflect.TypeOf((*Resp)(nil)).Elem(),
+		handle: func(ctx *Context, data json.RawMessage) (interface{}, error) {
+			var req Req
+			if len(data) > 0 && string(data) != "null" {
+				if err := json.Unmarshal(data, &req); err != nil {
+					return nil, NewError(ErrCodeBadRequest, "invalid "+msgType+" payload: "+err.Error())
+				}
+			}
+			if requestType.Kind() == reflect.Struct {
+				if err := utils.ValidateStruct(req); err != nil {
+					return nil, err
+				}
+			}
+			return fn(ctx, req)
+		},
+	}
+}
+
+// Event documents a server-pushed event. payload is a zero value of the
+// event's data type, or nil when the data has no fixed shape.
+func (r *Registry) Event(eventType, description string, payload interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[eventType] = eventEntry{
+		description: description,
+		payload:     reflect.TypeOf(payload),
+	}
+}
+
+// dispatch runs the handler for a client frame and returns the reply to
+// send, if any. Acks are only sent when the client supplied a request ID;
+// errors always are.
+func (r *Registry) dispatch(ctx *Context, env Envelope) *Message {
+	if env.V != 0 && env.V != ProtocolVersion {
+		return errorReply(ctx.RequestID, NewError(ErrCodeUnsupportedVersion, "unsupported protocol version"))
+	}
+
+	r.mu.RLock()
+	entry, ok := r.handlers[env.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return errorReply(ctx.RequestID, NewError(ErrCodeUnknownType, "unknown message type: "+env.Type))
+	}
+
+	result, err := call(entry, env.Type, ctx, env.Data)
+	if err != nil {
+		return errorReply(ctx.RequestID, toError(err))
+	}
+	if ctx.RequestID == "" {
+		return nil
+	}
+	return &Message{Type: ReplyAck, RequestID: ctx.RequestID, Data: result}
+}
+
+// call runs a handler, turning a panic into an internal error so one bad
+// message cannot take down the connection.
+func call(entry handlerEntry, msgType string, ctx *Context, data json.RawMessage) (result interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("WebSocket handler %s panicked for user %s: %v\n%s", msgType, ctx.UserID, p, debug.Stack())
+			result, err = nil, NewError(ErrCodeInternal, "internal error")
+		}
+	}()
+	return entry.handle(ctx, data)
+}
+
+func errorReply(requestID string, err *Error) *Message {
+	return &Message{Type: ReplyError, RequestID: requestID, Error: err}
+}
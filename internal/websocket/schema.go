@@ -0,0 +1,200 @@
+package websocket
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Schema describes the protocol so clients can generate bindings. Types
+// are JSON Schema fragments; named structs are shared through Defs.
+type Schema struct {
+	ProtocolVersion int                      `json:"protocol_version"`
+	Envelope        map[string]interface{}   `json:"envelope"`
+	Reply           map[string]interface{}   `json:"reply"`
+	ErrorCodes      []ErrorCode              `json:"error_codes"`
+	Messages        map[string]MessageSchema `json:"messages"`
+	Events          map[string]EventSchema   `json:"events"`
+	Defs            map[string]interface{}   `json:"$defs"`
+}
+
+// MessageSchema describes a client message and the data of its ack.
+type MessageSchema struct {
+	Description string                 `json:"description"`
+	Request     map[string]interface{} `json:"request"`
+	Response    map[string]interface{} `json:"response"`
+}
+
+// EventSchema describes a server-pushed event's data.
+type EventSchema struct {
+	Description string                 `json:"description"`
+	Payload     map[string]interface{} `json:"payload"`
+}
+
+// Schema builds the schema of every registered message and event.
+func (r *Registry) Schema() *Schema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b := &schemaBuilder{defs: make(map[string]interface{})}
+	schema := &Schema{
+		ProtocolVersion: ProtocolVersion,
+		Envelope:        b.of(reflect.TypeOf(Envelope{})),
+		Reply:           b.of(reflect.TypeOf(Message{})),
+		ErrorCodes:      ErrorCodes,
+		Messages:        make(map[string]MessageSchema, len(r.handlers)),
+		Events:          make(map[string]EventSchema, len(r.events)),
+		Defs:            b.defs,
+	}
+	for msgType, entry := range r.handlers {
+		schema.Messages[msgType] = MessageSchema{
+			Description: entry.description,
+			Request:     b.of(entry.request),
+			Response:    b.of(entry.response),
+		}
+	}
+	for eventType, entry := range r.events {
+		payload := map[string]interface{}{"type": "object"}
+		if entry.payload != nil {
+			payload = b.of(entry.payload)
+		}
+		schema.Events[eventType] = EventSchema{
+			Description: entry.description,
+			Payload:     payload,
+		}
+	}
+	return schema
+}
+
+// SchemaHandler serves the protocol schema of the global hub.
+func SchemaHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(GlobalHub.Handlers().Schema())
+	}
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+func (b *schemaBuilder) of(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	case rawType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.of(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, seen := b.defs[name]; !seen {
+			// Reserve the name first so recursive types terminate
+			b.defs[name] = nil
+			b.defs[name] = b.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+// object describes a struct's JSON fields, flattening embedded structs.
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	b.fields(t, properties, &required)
+	sort.Strings(required)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.fields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := b.of(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch key {
+			case "required":
+				*required = append(*required, name)
+			case "oneof":
+				property = withKeyword(property, "enum", strings.Fields(value))
+			case "min", "max":
+				if n, err := strconv.Atoi(value); err == nil {
+					keyword := map[string]string{"min": "minimum", "max": "maximum"}[key]
+					if property["type"] == "string" {
+						keyword = map[string]string{"min": "minLength", "max": "maxLength"}[key]
+					}
+					property = withKeyword(property, keyword, n)
+				}
+			}
+		}
+		properties[name] = property
+	}
+}
+
+// withKeyword adds a keyword to a property schema. References are wrapped
+// in allOf since JSON Schema ignores siblings of $ref in older drafts.
+func withKeyword(property map[string]interface{}, keyword string, value interface{}) map[string]interface{} {
+	if _, isRef := property["$ref"]; isRef {
+		return map[string]interface{}{"allOf": []interface{}{property}, keyword: value}
+	}
+	property[keyword] = value
+	return property
+}
@@ -0,0 +1,125 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// session is the event stream of one connection. Every event pushed to it
+// gets the next sequence number and is kept in a bounded replay buffer, so
+// a client that drops can reconnect within the grace window and resume
+// from the last sequence it received instead of refetching all state.
+// Sessions are guarded by hub.mutex.
+type session struct {
+	id            uuid.UUID
+	userID        uuid.UUID
+	authSessionID uuid.UUID
+
+	// client is the live connection, nil while detached
+	client     *Client
+	detachedAt time.Time
+
+	// mapID is the map room the session is in
+	mapID uuid.UUID
+
+	// seq is the last sequence number handed out
+	seq uint64
+
+	// frames is a ring of the newest sequenced frames, oldest at start
+	frames []frame
+	start  int
+	count  int
+}
+
+type frame struct {
+	seq  uint64
+	data []byte
+}
+
+// SessionInfo is sent first on every connection. Clients keep SessionID
+// and the seq of the last event they handled to resume after a drop. When
+// Resumed is false, events may have been missed and state should be
+// refetched.
+type SessionInfo struct {
+	SessionID          uuid.UUID `json:"session_id"`
+	Resumed            bool      `json:"resumed"`
+	Seq                uint64    `json:"seq"`
+	ResumeGraceSeconds int       `json:"resume_grace_seconds"`
+}
+
+// SessionAck tells the server events up to Seq were handled and need not
+// be kept for a resume.
+type SessionAck struct {
+	Seq uint64 `json:"seq" validate:"min=1"`
+}
+
+func newSession(userID, authSessionID uuid.UUID, replaySize int) *session {
+	return &session{
+		id:            uuid.New(),
+		userID:        userID,
+		authSessionID: authSessionID,
+		frames:        make([]frame, replaySize),
+	}
+}
+
+// push sequences an event, encodes it and keeps it for replay, evicting
+// the oldest frame when the buffer is full.
+func (s *session) push(message Message) ([]byte, error) {
+	message.Seq = s.seq + 1
+	data, err := encode(message)
+	if err != nil {
+		return nil, err
+	}
+	s.seq = message.Seq
+
+	if len(s.frames) == 0 {
+		return data, nil
+	}
+	if s.count == len(s.frames) {
+		s.start = (s.start + 1) % len(s.frames)
+		s.count--
+	}
+	s.frames[(s.start+s.count)%len(s.frames)] = frame{seq: s.seq, data: data}
+	s.count++
+	return data, nil
+}
+
+// ack drops buffered frames up to and including seq.
+func (s *session) ack(seq uint64) {
+	for s.count > 0 && s.frames[s.start].seq <= seq {
+		s.frames[s.start] = frame{}
+		s.start = (s.start + 1) % len(s.frames)
+		s.count--
+	}
+}
+
+// since returns the frames sequenced after seq, in order. It reports false
+// when some of them were already evicted or seq was never handed out, in
+// which case the stream cannot be resumed.
+func (s *session) since(seq uint64) ([][]byte, bool) {
+	if seq > s.seq {
+		return nil, false
+	}
+
+	var replay [][]byte
+	for i := 0; i < s.count; i++ {
+		f := s.frames[(s.start+i)%len(s.frames)]
+		if f.seq <= seq {
+			continue
+		}
+		if replay == nil && f.seq != seq+1 {
+			return nil, false
+		}
+		replay = append(replay, f.data)
+	}
+	if replay == nil && seq != s.seq {
+		return nil, false
+	}
+	return replay, true
+}
+
+// expired reports whether a detached session is past its grace window.
+func (s *session) expired(now time.Time, grace time.Duration) bool {
+	return s.client == nil && now.Sub(s.detachedAt) > grace
+}
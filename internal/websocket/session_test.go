@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// pushEvents pushes count events to the session.
+func pushEvents(t *testing.T, s *session, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		if _, err := s.push(Message{Type: "test_event"}); err != nil {
+			t.Fatalf("push: %v", err)
+		}
+	}
+}
+
+// frameSeqs decodes the sequence numbers of replayed frames.
+func frameSeqs(t *testing.T, frames [][]byte) []uint64 {
+	t.Helper()
+	var seqs []uint64
+	for _, data := range frames {
+		var message Message
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("decoding frame: %v", err)
+		}
+		seqs = append(seqs, message.Seq)
+	}
+	return seqs
+}
+
+func TestSessionReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		pushed int
+		acked  uint64
+		from   uint64
+		want   []uint64
+		ok     bool
+	}{
+		{"from the start", 4, 3, 0, 0, []uint64{1, 2, 3}, true},
+		{"part-way", 4, 3, 0, 1, []uint64{2, 3}, true},
+		{"up to date", 4, 3, 0, 3, nil, true},
+		{"after wrapping around", 4, 10, 0, 6, []uint64{7, 8, 9, 10}, true},
+		{"from the newest after wrapping around", 4, 10, 0, 8, []uint64{9, 10}, true},
+		{"from an evicted seq", 4, 10, 0, 5, nil, false},
+		{"from the very start after evictions", 4, 10, 0, 0, nil, false},
+		{"from a seq never handed out", 4, 3, 0, 4, nil, false},
+		{"after an ack", 4, 3, 2, 2, []uint64{3}, true},
+		{"from an acked seq", 4, 3, 2, 1, nil, false},
+		{"after an ack past the head", 4, 3, 9, 3, nil, true},
+		{"behind an ack past the head", 4, 3, 9, 2, nil, false},
+		{"without a buffer, up to date", 0, 3, 0, 3, nil, true},
+		{"without a buffer, behind", 0, 3, 0, 2, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSession(uuid.New(), uuid.New(), tt.size)
+			pushEvents(t, s, tt.pushed)
+			s.ack(tt.acked)
+
+			frames, ok := s.since(tt.from)
+			if ok != tt.ok {
+				t.Fatalf("since(%d) resumable %v, want %v", tt.from, ok, tt.ok)
+			}
+			if got := frameSeqs(t, frames); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("since(%d) replayed %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionKeepsSequencingAfterAcks(t *testing.T) {
+	s := newSession(uuid.New(), uuid.New(), 3)
+
+	// Acks past the head empty the buffer without moving the sequence,
+	// and the ring keeps working as it wraps around afterwards
+	pushEvents(t, s, 2)
+	s.ack(50)
+	if s.count != 0 || s.seq != 2 {
+		t.Fatalf("after acking past the head: %d frames, seq %d; want 0 and 2", s.count, s.seq)
+	}
+	pushEvents(t, s, 5)
+	if frames, ok := s.since(4); !ok || !reflect.DeepEqual(frameSeqs(t, frames), []uint64{5, 6, 7}) {
+		t.Fatalf("since(4) = %v, %v, want 5 to 7", frameSeqs(t, frames), ok)
+	}
+
+	s.ack(6)
+	if frames, ok := s.since(6); !ok || !reflect.DeepEqual(frameSeqs(t, frames), []uint64{7}) {
+		t.Fatalf("since(6) = %v, %v, want 7", frameSeqs(t, frames), ok)
+	}
+	if _, ok := s.since(5); ok {
+		t.Error("since(5) after acking 6: resumable, want false")
+	}
+}
+
+func TestSessionWithoutBuffer(t *testing.T) {
+	s := newSession(uuid.New(), uuid.New(), 0)
+
+	data, err := s.push(Message{Type: "test_event"})
+	if err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if seqs := frameSeqs(t, [][]byte{data}); seqs[0] != 1 {
+		t.Fatalf("pushed seq %d, want 1", seqs[0])
+	}
+	s.ack(1)
+	s.ack(7)
+	if frames, ok := s.since(1); !ok || frames != nil {
+		t.Errorf("since(1) = %v, %v, want nothing to replay", frames, ok)
+	}
+}
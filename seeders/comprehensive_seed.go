@@ -6,6 +6,7 @@ import (
 
 	"code-valley-api/internal/config"
 	"code-valley-api/internal/database"
+	"code-valley-api/internal/migrations"
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/utils"
 
@@ -21,6 +22,11 @@ func main() {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
+	// Make sure the schema exists, e.g. on a fresh SQLite file
+	if _, err := migrations.New(database.GetDB()).Up(); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
 	db := database.GetDB()
 
 	log.Println("Starting comprehensive database seeding...")
@@ -90,11 +96,27 @@ func main() {
 			Width:  50,
 			Height: 50,
 			Layout: models.MapLayout{
-				"spawn_point": map[string]int{"x": 25, "y": 25},
-				"buildings":   []map[string]interface{}{
-					{"type": "town_hall", "x": 25, "y": 20},
-					{"type": "shop", "x": 30, "y": 25},
-					{"type": "library", "x": 20, "y": 25},
+				SpawnPoints: []models.SpawnPoint{
+					{Name: models.DefaultSpawn, X: 25, Y: 25},
+					{Name: "from_mine", X: 25, Y: 2},
+					{Name: "from_farm", X: 47, Y: 25},
+				},
+				Zones: []models.MapZone{
+					{Name: "town_hall", Type: models.ZoneBuilding, X: 25, Y: 20, Width: 1, Height: 1},
+					{Name: "shop", Type: models.ZoneBuilding, X: 30, Y: 25, Width: 1, Height: 1},
+					{Name: "library", Type: models.ZoneBuilding, X: 20, Y: 25, Width: 1, Height: 1},
+				},
+				Portals: []models.Portal{
+					{
+						Name: "mine_entrance", X: 24, Y: 0, Width: 3, Height: 1,
+						TargetMap:    "code_mine",
+						Requirements: models.PortalRequirements{MinLevel: 2},
+					},
+					{
+						Name: "farm_gate", X: 49, Y: 24, Width: 1, Height: 3,
+						TargetMap:    "data_farm",
+						Requirements: models.PortalRequirements{OpenFrom: 6, OpenUntil: 22},
+					},
 				},
 			},
 			Description: "The main village where programmers gather",
@@ -107,8 +129,13 @@ func main() {
 			Width:  40,
 			Height: 40,
 			Layout: models.MapLayout{
-				"entrance": map[string]int{"x": 20, "y": 35},
-				"levels":   5,
+				SpawnPoints: []models.SpawnPoint{
+					{Name: models.DefaultSpawn, X: 20, Y: 35},
+				},
+				Portals: []models.Portal{
+					{Name: "mine_exit", X: 19, Y: 39, Width: 3, Height: 1, TargetMap: "village", TargetSpawn: "from_mine"},
+				},
+				Properties: map[string]interface{}{"levels": 5},
 			},
 			Description: "Deep caves where you can mine for algorithms and data structures",
 			IsActive:    true,
@@ -120,8 +147,13 @@ func main() {
 			Width:  60,
 			Height: 40,
 			Layout: models.MapLayout{
-				"plots": 100,
-				"greenhouse": map[string]int{"x": 30, "y": 20},
+				Zones: []models.MapZone{
+					{Name: "plots", Type: models.ZoneFarmland, X: 5, Y: 5, Width: 10, Height: 10},
+					{Name: "greenhouse", Type: models.ZoneBuilding, X: 30, Y: 20, Width: 1, Height: 1},
+				},
+				Portals: []models.Portal{
+					{Name: "village_road", X: 0, Y: 19, Width: 1, Height: 3, TargetMap: "village", TargetSpawn: "from_farm"},
+				},
 			},
 			Description: "Your personal coding farm where you grow and nurture code",
 			IsActive:    true,
@@ -656,6 +688,52 @@ func main() {
 		db.FirstOrCreate(&obj, "map_id = ? AND pos_x = ? AND pos_y = ?", obj.MapID, obj.PosX, obj.PosY)
 	}
 
+	// Loot tables, replacing the plain ones the migration starts with
+	lootTables := []models.LootTable{
+		{
+			Name:        "tree",
+			Description: "Chopped trees",
+			Rolls:       1,
+			Entries: models.LootEntries{
+				{ItemName: "Code Snippet", ItemType: models.ItemTypeResource, Weight: 80, MinQuantity: 1, MaxQuantity: 3, Rarity: models.RarityCommon},
+				{ItemName: "Clean Branch", ItemType: models.ItemTypeResource, Weight: 15, MinQuantity: 1, MaxQuantity: 1, Rarity: models.RarityUncommon,
+					Conditions: models.LootConditions{Tool: "Debug Magnifier"}},
+				{ItemName: "Golden Snippet", ItemType: models.ItemTypeSnippet, Weight: 5, MinQuantity: 1, MaxQuantity: 1, Rarity: models.RarityRare,
+					Conditions: models.LootConditions{Seasons: []string{"fall"}}},
+			},
+		},
+		{
+			Name:        "rock",
+			Description: "Mined rocks",
+			Rolls:       1,
+			Entries: models.LootEntries{
+				{ItemName: "Raw Data", ItemType: models.ItemTypeResource, Weight: 85, MinQuantity: 1, MaxQuantity: 2, Rarity: models.RarityCommon},
+				{ItemName: "Legacy Byte", ItemType: models.ItemTypeResource, Weight: 12, MinQuantity: 1, MaxQuantity: 1, Rarity: models.RarityUncommon},
+				{ItemName: "Crystal Cache", ItemType: models.ItemTypeResource, Weight: 3, MinQuantity: 1, MaxQuantity: 1, Rarity: models.RarityEpic,
+					Conditions: models.LootConditions{MinLevel: 5}},
+			},
+		},
+		{
+			Name:        "chest",
+			Description: "Opened chests",
+			Rolls:       1,
+			Entries: models.LootEntries{
+				{ItemName: "Debug Tool", ItemType: models.ItemTypeTool, Weight: 30, MinQuantity: 1, MaxQuantity: 1, Rarity: models.RarityUncommon},
+				{ItemName: "Refactor Kit", ItemType: models.ItemTypeTool, Weight: 30, MinQuantity: 1, MaxQuantity: 1, Rarity: models.RarityUncommon},
+				{ItemName: "Unit Test Template", ItemType: models.ItemTypeTool, Weight: 30, MinQuantity: 1, MaxQuantity: 1, Rarity: models.RarityUncommon},
+				{ItemName: "Coffee Beans", ItemType: models.ItemTypeResource, Weight: 9, MinQuantity: 2, MaxQuantity: 5, Rarity: models.RarityCommon},
+				{ItemName: "Mechanical Keyboard", ItemType: models.ItemTypeTool, Weight: 1, MinQuantity: 1, MaxQuantity: 1, Rarity: models.RarityLegendary,
+					Conditions: models.LootConditions{MinLevel: 10}},
+			},
+		},
+	}
+
+	for _, table := range lootTables {
+		db.Where("name = ?", table.Name).
+			Assign(models.LootTable{Description: table.Description, Rolls: table.Rolls, Entries: table.Entries}).
+			FirstOrCreate(&table)
+	}
+
 	// Create Player Positions
 	playerPositions := []models.PlayerPosition{
 		{
@@ -706,6 +784,25 @@ func main() {
 		db.FirstOrCreate(&pos, "npc_id = ?", pos.NPCID)
 	}
 
+	// Create NPC Schedules, the same every day of the week
+	mineMap, farmMap := maps[1], maps[2]
+	dailyRoutines := []models.NPCSchedule{
+		{NPCID: npcs[0].ID, TimeOfDay: 700, MapID: villageMap.ID, PosX: 25, PosY: 21, Action: "teach"},
+		{NPCID: npcs[0].ID, TimeOfDay: 1300, MapID: farmMap.ID, PosX: 30, PosY: 21, Action: "inspect"},
+		{NPCID: npcs[0].ID, TimeOfDay: 1800, MapID: villageMap.ID, PosX: 25, PosY: 20, Action: "rest"},
+		{NPCID: npcs[1].ID, TimeOfDay: 900, MapID: villageMap.ID, PosX: 30, PosY: 26, Action: "work"},
+		{NPCID: npcs[1].ID, TimeOfDay: 1600, MapID: mineMap.ID, PosX: 20, PosY: 30, Action: "explore"},
+		{NPCID: npcs[1].ID, TimeOfDay: 2100, MapID: villageMap.ID, PosX: 30, PosY: 25, Action: "rest"},
+	}
+
+	for day := 0; day < 7; day++ {
+		for _, schedule := range dailyRoutines {
+			schedule.DayOfWeek = day
+			db.FirstOrCreate(&schedule, "npc_id = ? AND day_of_week = ? AND time_of_day = ?",
+				schedule.NPCID, schedule.DayOfWeek, schedule.TimeOfDay)
+		}
+	}
+
 	// Create Game Clock
 	gameClock := &models.GameClock{
 		ID:         uuid.New(),
@@ -751,11 +848,17 @@ func main() {
 		db.FirstOrCreate(&reward, "day = ?", reward.Day)
 	}
 
+	// Open coin ledger wallets for the seeded balances
+	if err := migrations.PostOpeningBalances(db); err != nil {
+		log.Fatal("Failed to post opening balances:", err)
+	}
+
 	log.Println("Comprehensive database seeding completed successfully!")
 	log.Println("Created:")
 	log.Println("- 1 Admin user and 3 sample players")
 	log.Println("- 3 Maps (Village, Code Mine, Data Farm)")
 	log.Println("- 4 NPCs with different roles")
+	log.Println("- Daily schedules for 2 NPCs")
 	log.Println("- 4 Quests with various difficulties")
 	log.Println("- 4 Shop items")
 	log.Println("- 3 Achievements")
@@ -6,6 +6,7 @@ import (
 
 	"code-valley-api/internal/config"
 	"code-valley-api/internal/database"
+	"code-valley-api/internal/migrations"
 	"code-valley-api/internal/models"
 	"code-valley-api/internal/utils"
 
@@ -21,6 +22,11 @@ func main() {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
+	// Make sure the schema exists, e.g. on a fresh SQLite file
+	if _, err := migrations.New(database.GetDB()).Up(); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
 	db := database.GetDB()
 
 	log.Println("Starting database seeding...")
@@ -247,5 +253,10 @@ func main() {
 		db.FirstOrCreate(&item, "user_id = ? AND item_name = ?", item.UserID, item.ItemName)
 	}
 
+	// Open coin ledger wallets for the seeded balances
+	if err := migrations.PostOpeningBalances(db); err != nil {
+		log.Fatal("Failed to post opening balances:", err)
+	}
+
 	log.Println("Database seeding completed successfully!")
 }
\ No newline at end of file